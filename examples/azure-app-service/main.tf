@@ -59,21 +59,17 @@ resource "azurerm_linux_web_app" "auditledger" {
     minimum_tls_version = "1.2"
   }
 
-  app_settings = {
+  # AuditLedger storage settings are rendered by the storage module (Entra ID authentication
+  # through the managed identity, no connection string)
+  app_settings = merge(module.auditledger_storage.app_configuration.env, {
     "ASPNETCORE_ENVIRONMENT" = var.environment
 
-    # AuditLedger Configuration
-    "AuditLedger__Storage__Provider"                 = "AzureBlob"
-    "AuditLedger__Storage__AzureBlob__ContainerName" = module.auditledger_storage.container_name
-    "AuditLedger__Storage__AzureBlob__AccountName"   = module.auditledger_storage.storage_account_name
-    "AuditLedger__Storage__AzureBlob__UseAzurite"    = "false"
-
     "AuditLedger__Compliance__OrganizationId" = var.organization_id
     "AuditLedger__Compliance__Environment"    = var.environment
 
     # Application Insights
     "APPLICATIONINSIGHTS_CONNECTION_STRING" = azurerm_application_insights.main.connection_string
-  }
+  })
 
   https_only = true
 
@@ -84,6 +80,12 @@ resource "azurerm_linux_web_app" "auditledger" {
 module "auditledger_storage" {
   source = "../../modules/auditledger-azure-blob"
 
+  # No replication destination is created; see the module README to create one elsewhere
+  providers = {
+    azurerm         = azurerm
+    azurerm.replica = azurerm
+  }
+
   storage_account_name = var.storage_account_name
   resource_group_name  = azurerm_resource_group.main.name
   location             = azurerm_resource_group.main.location
@@ -93,9 +95,11 @@ module "auditledger_storage" {
   retention_days = var.retention_days
 
   # Security: Use managed identity (no connection strings)
-  enable_managed_identity       = true
-  managed_identity_principal_id = azurerm_linux_web_app.auditledger.identity[0].principal_id
-  enable_shared_key_access      = false
+  writer_principal_ids     = [azurerm_linux_web_app.auditledger.identity[0].principal_id]
+  enable_shared_key_access = false
+
+  # CanNotDelete locks on the account; destroying it then needs permission to delete locks
+  enable_management_locks = var.environment == "Production"
 
   # Network security
   network_default_action = var.network_default_action
@@ -129,9 +129,8 @@ resource "aws_instance" "auditledger_app" {
   }
 
   user_data = templatefile("${path.module}/user_data.sh", {
-    bucket_name = module.auditledger_s3.bucket_id
-    region      = var.aws_region
-    environment = var.environment
+    app_settings_json = module.auditledger_s3.app_configuration.json
+    environment       = var.environment
   })
 
   tags = {
@@ -12,19 +12,10 @@ yum install -y dotnet-runtime-9.0
 mkdir -p /opt/auditledger
 cd /opt/auditledger
 
-# Configure AuditLedger (example appsettings.json)
-cat > appsettings.Production.json << EOF
-{
-  "AuditLedger": {
-    "Storage": {
-      "Provider": "AwsS3",
-      "AwsS3": {
-        "BucketName": "${bucket_name}",
-        "Region": "${region}"
-      }
-    }
-  }
-}
+# Configure AuditLedger storage with the settings rendered by the S3 module, including the
+# request headers its bucket policy requires on every write
+cat > appsettings.Production.json << 'EOF'
+${app_settings_json}
 EOF
 
 # Create systemd service
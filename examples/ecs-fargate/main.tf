@@ -133,18 +133,12 @@ resource "aws_ecs_task_definition" "auditledger_app" {
       name  = "auditledger"
       image = var.app_image
 
+      # Storage settings rendered by the S3 module, including the request headers its
+      # bucket policy requires on every write
       environment = [
-        {
-          name  = "AuditLedger__Storage__Provider"
-          value = "AwsS3"
-        },
-        {
-          name  = "AuditLedger__Storage__AwsS3__BucketName"
-          value = module.auditledger_s3.bucket_id
-        },
-        {
-          name  = "AuditLedger__Storage__AwsS3__Region"
-          value = var.aws_region
+        for name, value in module.auditledger_s3.app_configuration.env : {
+          name  = name
+          value = value
         }
       ]
 
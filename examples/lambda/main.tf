@@ -92,12 +92,11 @@ resource "aws_lambda_function" "auditledger" {
   }
 
   environment {
-    variables = {
-      AUDITLEDGER_STORAGE_PROVIDER = "AwsS3"
-      AUDITLEDGER_S3_BUCKET_NAME   = module.auditledger_s3.bucket_id
-      AUDITLEDGER_S3_REGION        = var.aws_region
-      ENVIRONMENT                  = var.environment
-    }
+    # Storage settings rendered by the S3 module, including the request headers its
+    # bucket policy requires on every write
+    variables = merge(module.auditledger_s3.app_configuration.env, {
+      ENVIRONMENT = var.environment
+    })
   }
 
   tags = {
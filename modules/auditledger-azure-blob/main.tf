@@ -8,10 +8,102 @@ terraform {
     azurerm = {
       source  = "hashicorp/azurerm"
       version = ">= 3.0"
+
+      # Created replication destinations; may target another subscription or tenant
+      configuration_aliases = [azurerm.replica]
+    }
+    azapi = {
+      source  = "azure/azapi"
+      version = ">= 2.0"
     }
   }
 }
 
+# Compliance profile - resolves retention, logging and tag requirements
+module "compliance_profile" {
+  source = "../auditledger-compliance-profile"
+
+  profile = var.compliance_profile
+}
+
+locals {
+  compliance = module.compliance_profile.settings
+
+  # Explicit inputs win; otherwise the profile (or historical) default applies
+  retention_days = coalesce(var.retention_days, local.compliance.default_retention_days)
+
+  # Profiles that require COMPLIANCE mode lock the policy unless explicitly overridden
+  lock_immutability_policy = coalesce(var.lock_immutability_policy, local.compliance.require_compliance_mode)
+
+  # managed_identity_principal_id is kept for backwards compatibility and treated as one more writer
+  writer_principal_ids = concat(
+    var.writer_principal_ids,
+    var.managed_identity_principal_id != null ? [var.managed_identity_principal_id] : []
+  )
+
+  # Key Vault and key are created unless existing ones are passed in
+  create_key_vault = var.enable_customer_managed_key && var.key_vault_id == null
+  create_key       = var.enable_customer_managed_key && var.key_name == null
+  key_vault_id     = local.create_key_vault ? one(azurerm_key_vault.audit_logs[*].id) : var.key_vault_id
+  key_name         = local.create_key ? one(azurerm_key_vault_key.audit_logs[*].name) : var.key_name
+
+  # Replication to an existing account wins over a created one
+  replication_enabled                        = var.create_replication_destination || var.replication_destination_storage_account_id != null
+  replication_destination_storage_account_id = var.create_replication_destination ? one(azurerm_storage_account.replica[*].id) : var.replication_destination_storage_account_id
+  replication_destination_container_name     = coalesce(var.replication_destination_container_name, var.container_name)
+
+  # Lifecycle tiering - deletion defaults to the immutability period and never precedes it
+  tiers                  = var.lifecycle_tiers
+  lifecycle_delete_days  = local.tiers.skip_deletion ? null : coalesce(local.tiers.delete_after_days, local.retention_days)
+  lifecycle_prefix_match = length(local.tiers.prefix_filters) > 0 ? [for prefix in local.tiers.prefix_filters : "${var.container_name}/${prefix}"] : ["${var.container_name}/"]
+  lifecycle_has_tiers    = anytrue([for days in [local.tiers.tier_to_cool_after_days, local.tiers.tier_to_cold_after_days, local.tiers.tier_to_archive_after_days] : days != null])
+  lifecycle_by_access    = local.tiers.last_access_time_tracking
+
+  # Diagnostics go to every configured sink
+  diagnostics_enabled = anytrue([
+    var.log_analytics_workspace_id != null,
+    var.diagnostic_event_hub_authorization_rule_id != null,
+    var.diagnostic_storage_account_id != null,
+  ])
+
+  # Control-plane operations that weaken or bypass the audit log protections
+  container_operation = "Microsoft.Storage/storageAccounts/blobServices/containers"
+  activity_log_alerts = {
+    "list-keys"                  = { operation = "Microsoft.Storage/storageAccounts/listKeys/action", description = "Storage account keys were listed" }
+    "account-write"              = { operation = "Microsoft.Storage/storageAccounts/write", description = "Storage account configuration, including network rules, was changed" }
+    "immutability-policy-write"  = { operation = "${local.container_operation}/immutabilityPolicies/write", description = "A container immutability policy was created or changed" }
+    "immutability-policy-delete" = { operation = "${local.container_operation}/immutabilityPolicies/delete", description = "A container immutability policy was deleted" }
+    "immutability-policy-lock"   = { operation = "${local.container_operation}/immutabilityPolicies/lock/action", description = "A container immutability policy was locked" }
+    "immutability-policy-extend" = { operation = "${local.container_operation}/immutabilityPolicies/extend/action", description = "A container immutability policy was extended" }
+    "legal-hold-clear"           = { operation = "${local.container_operation}/clearLegalHold/action", description = "A legal hold was cleared" }
+    "lock-delete"                = { operation = "Microsoft.Authorization/locks/delete", description = "A management lock was deleted" }
+  }
+
+  # Lock deletions are alerted on the resource group so locks on the group itself are covered too
+  resource_group_id = split("/providers/", azurerm_storage_account.audit_logs.id)[0]
+
+  # Bring-your-own zone wins over a created one; no zone means DNS is managed elsewhere
+  private_dns_zone_id = var.private_dns_zone_id != null ? var.private_dns_zone_id : one(azurerm_private_dns_zone.blob[*].id)
+
+  blob_data_action = "Microsoft.Storage/storageAccounts/blobServices/containers/blobs"
+
+  # Never granted to the writer or reader roles, even if their actions are widened later
+  denied_data_actions = concat(
+    [
+      "${local.blob_data_action}/delete",
+      "${local.blob_data_action}/deleteBlobVersion/action",
+      "${local.blob_data_action}/permanentDelete/action",
+    ],
+    var.allow_blob_retention_overrides ? [] : ["${local.blob_data_action}/immutableStorage/runAsSuperUser/action"]
+  )
+
+  # Only claim a framework in tags when a profile actually enforces it
+  compliance_tags = var.compliance_profile != null ? {
+    Compliance        = local.compliance.tag
+    ComplianceProfile = var.compliance_profile
+  } : {}
+}
+
 # Resource Group (optional - can use existing)
 resource "azurerm_resource_group" "audit_logs" {
   count    = var.create_resource_group ? 1 : 0
@@ -21,10 +113,10 @@ resource "azurerm_resource_group" "audit_logs" {
   tags = merge(
     var.tags,
     {
-      Purpose    = "AuditLedger Immutable Storage"
-      Compliance = "SOC2-HIPAA-PCIDSS"
-      ManagedBy  = "Terraform"
-    }
+      Purpose   = "AuditLedger Immutable Storage"
+      ManagedBy = "Terraform"
+    },
+    local.compliance_tags
   )
 }
 
@@ -42,28 +134,40 @@ resource "azurerm_storage_account" "audit_logs" {
   https_traffic_only_enabled      = true # Updated from deprecated enable_https_traffic_only
   allow_nested_items_to_be_public = false
   shared_access_key_enabled       = var.enable_shared_key_access
+  public_network_access_enabled   = var.public_network_access_enabled
+
+  # Object replication to another tenant needs this on the source account
+  cross_tenant_replication_enabled = var.allow_cross_tenant_replication
 
   # Blob properties - versioning is REQUIRED for immutability
   blob_properties {
     # Versioning is mandatory for immutable audit logs
     versioning_enabled = true
 
+    # Required for lifecycle tiering by last access time
+    last_access_time_enabled = local.lifecycle_by_access
+
     # Change feed for point-in-time restore
     change_feed_enabled           = true
-    change_feed_retention_in_days = var.retention_days
+    change_feed_retention_in_days = local.retention_days
 
     # Soft delete for additional protection
     delete_retention_policy {
-      days = var.retention_days
+      days = local.retention_days
     }
 
     container_delete_retention_policy {
-      days = var.retention_days
+      days = local.retention_days
     }
 
-    # Restore policy (Azure limit is 365 days)
-    restore_policy {
-      days = min(var.retention_days, 365)
+    # Restore policy (Azure limit is 365 days). Point-in-time restore is not supported with
+    # version-level immutability, so it is dropped before the container is migrated
+    dynamic "restore_policy" {
+      for_each = var.version_level_immutability == "disabled" ? [min(local.retention_days, 365)] : []
+
+      content {
+        days = restore_policy.value
+      }
     }
   }
 
@@ -76,23 +180,201 @@ resource "azurerm_storage_account" "audit_logs" {
     virtual_network_subnet_ids = var.allowed_subnet_ids
   }
 
-  # Encryption
+  # Version-level immutability for every container in the account - only possible at account creation.
+  # Azure only creates account policies as Unlocked; the audit container's own policy is the one that is locked
+  dynamic "immutability_policy" {
+    for_each = var.version_level_immutability == "account" ? [1] : []
+
+    content {
+      state                         = "Unlocked"
+      period_since_creation_in_days = local.retention_days
+      allow_protected_append_writes = var.enable_protected_append_writes
+    }
+  }
+
+  # Encryption - the system identity is what unwraps the customer-managed key
   identity {
     type = var.enable_managed_identity ? "SystemAssigned" : null
   }
 
+  # Second layer of AES-256 encryption at the infrastructure level (set at creation only)
+  infrastructure_encryption_enabled = var.enable_infrastructure_encryption
+
   tags = merge(
     var.tags,
     {
-      Name       = var.storage_account_name
-      Purpose    = "AuditLedger Immutable Audit Logs"
-      Compliance = "SOC2-HIPAA-PCIDSS"
-      Immutable  = "true"
-      ManagedBy  = "Terraform"
+      Name      = var.storage_account_name
+      Purpose   = "AuditLedger Immutable Audit Logs"
+      Immutable = "true"
+      ManagedBy = "Terraform"
+    },
+    local.compliance_tags
+  )
+
+  # Explicit overrides must still satisfy the compliance profile
+  lifecycle {
+    precondition {
+      condition     = local.retention_days >= local.compliance.minimum_retention_days
+      error_message = "retention_days (${local.retention_days}) is below the ${local.compliance.minimum_retention_days}-day minimum of the ${coalesce(var.compliance_profile, "default")} compliance profile"
+    }
+
+    precondition {
+      condition     = !local.compliance.require_access_logging || local.diagnostics_enabled
+      error_message = "The ${coalesce(var.compliance_profile, "default")} compliance profile requires diagnostic logging - set log_analytics_workspace_id, diagnostic_event_hub_authorization_rule_id or diagnostic_storage_account_id"
+    }
+
+    precondition {
+      condition     = !local.compliance.require_customer_managed_key || var.enable_customer_managed_key
+      error_message = "The ${coalesce(var.compliance_profile, "default")} compliance profile requires a customer-managed key - set enable_customer_managed_key = true"
+    }
+
+    precondition {
+      condition     = !var.enable_customer_managed_key || var.enable_managed_identity
+      error_message = "enable_customer_managed_key requires enable_managed_identity - the storage account identity unwraps the key"
     }
+  }
+}
+
+# Private Link for the blob endpoint (optional)
+resource "azurerm_private_dns_zone" "blob" {
+  count               = var.private_endpoint_subnet_id != null && var.create_private_dns_zone ? 1 : 0
+  name                = "privatelink.blob.core.windows.net"
+  resource_group_name = azurerm_storage_account.audit_logs.resource_group_name
+
+  tags = var.tags
+}
+
+resource "azurerm_private_dns_zone_virtual_network_link" "blob" {
+  count                 = length(azurerm_private_dns_zone.blob) > 0 ? length(var.private_dns_zone_virtual_network_ids) : 0
+  name                  = "${var.storage_account_name}-link-${count.index}"
+  resource_group_name   = azurerm_storage_account.audit_logs.resource_group_name
+  private_dns_zone_name = azurerm_private_dns_zone.blob[0].name
+  virtual_network_id    = var.private_dns_zone_virtual_network_ids[count.index]
+
+  tags = var.tags
+}
+
+# The DNS zone group creates the A record for <account>.privatelink.blob.core.windows.net
+resource "azurerm_private_endpoint" "blob" {
+  count               = var.private_endpoint_subnet_id != null ? 1 : 0
+  name                = "${var.storage_account_name}-blob-pe"
+  location            = var.location
+  resource_group_name = azurerm_storage_account.audit_logs.resource_group_name
+  subnet_id           = var.private_endpoint_subnet_id
+
+  private_service_connection {
+    name                           = "${var.storage_account_name}-blob"
+    private_connection_resource_id = azurerm_storage_account.audit_logs.id
+    subresource_names              = ["blob"]
+    is_manual_connection           = false
+  }
+
+  dynamic "private_dns_zone_group" {
+    for_each = var.private_dns_zone_id != null || var.create_private_dns_zone ? [local.private_dns_zone_id] : []
+
+    content {
+      name                 = "default"
+      private_dns_zone_ids = [private_dns_zone_group.value]
+    }
+  }
+
+  tags = var.tags
+
+  lifecycle {
+    precondition {
+      condition     = var.private_dns_zone_id == null || !var.create_private_dns_zone
+      error_message = "Set either private_dns_zone_id (existing zone) or create_private_dns_zone, not both"
+    }
+  }
+}
+
+# Customer-managed key encryption with Key Vault (optional)
+data "azurerm_client_config" "current" {
+  count = local.create_key_vault || local.create_key ? 1 : 0
+}
+
+# Purge protection keeps a deleted vault (and the key) recoverable - without the key the audit logs are unreadable
+resource "azurerm_key_vault" "audit_logs" {
+  count                      = local.create_key_vault ? 1 : 0
+  name                       = coalesce(var.key_vault_name, "${substr(var.storage_account_name, 0, 21)}-kv")
+  location                   = var.location
+  resource_group_name        = azurerm_storage_account.audit_logs.resource_group_name
+  tenant_id                  = data.azurerm_client_config.current[0].tenant_id
+  sku_name                   = "standard"
+  enable_rbac_authorization  = true
+  purge_protection_enabled   = true
+  soft_delete_retention_days = 90
+
+  network_acls {
+    default_action             = var.network_default_action
+    bypass                     = "AzureServices"
+    ip_rules                   = var.allowed_ip_ranges
+    virtual_network_subnet_ids = var.allowed_subnet_ids
+  }
+
+  tags = merge(
+    var.tags,
+    {
+      Purpose   = "AuditLedger Audit Log Encryption Keys"
+      ManagedBy = "Terraform"
+    },
+    local.compliance_tags
   )
 }
 
+# Terraform needs to create the key in the vault it just created
+resource "azurerm_role_assignment" "key_vault_crypto_officer" {
+  count                = local.create_key_vault ? 1 : 0
+  scope                = azurerm_key_vault.audit_logs[0].id
+  role_definition_name = "Key Vault Crypto Officer"
+  principal_id         = data.azurerm_client_config.current[0].object_id
+}
+
+resource "azurerm_key_vault_key" "audit_logs" {
+  count        = local.create_key ? 1 : 0
+  name         = "${var.storage_account_name}-cmk"
+  key_vault_id = local.key_vault_id
+  key_type     = "RSA"
+  key_size     = 3072
+  key_opts     = ["wrapKey", "unwrapKey"]
+
+  # Storage always uses the latest key version, so rotation needs no reconfiguration
+  rotation_policy {
+    automatic {
+      time_after_creation = "P${var.key_rotation_days}D"
+    }
+
+    expire_after         = "P${var.key_rotation_days * 2}D"
+    notify_before_expiry = "P30D"
+  }
+
+  depends_on = [azurerm_role_assignment.key_vault_crypto_officer]
+}
+
+# Storage account identity may only wrap and unwrap keys
+resource "azurerm_role_assignment" "storage_key_access" {
+  count                = var.enable_customer_managed_key && var.grant_key_vault_access ? 1 : 0
+  scope                = local.key_vault_id
+  role_definition_name = "Key Vault Crypto Service Encryption User"
+  principal_id         = azurerm_storage_account.audit_logs.identity[0].principal_id
+}
+
+resource "azurerm_storage_account_customer_managed_key" "audit_logs" {
+  count              = var.enable_customer_managed_key ? 1 : 0
+  storage_account_id = azurerm_storage_account.audit_logs.id
+  key_vault_id       = local.key_vault_id
+  key_name           = local.key_name
+
+  depends_on = [azurerm_role_assignment.storage_key_access]
+
+  lifecycle {
+    precondition {
+      condition     = var.key_name == null || var.key_vault_id != null
+      error_message = "key_name refers to an existing key, so key_vault_id must be set as well"
+    }
+  }
+}
+
 # Blob Container for Audit Logs
 resource "azurerm_storage_container" "audit_logs" {
   name                  = var.container_name
@@ -100,8 +382,203 @@ resource "azurerm_storage_container" "audit_logs" {
   container_access_type = "private"
 }
 
+# Time-based retention (WORM) policy on the audit container
+# Blobs cannot be overwritten or deleted until they are retention_days old.
+# With version-level immutability this is the default policy applied to every new blob version.
+# A LOCKED policy can only be extended, never shortened or removed - this is IRREVERSIBLE
+resource "azurerm_storage_container_immutability_policy" "audit_logs" {
+  storage_container_resource_manager_id = azurerm_storage_container.audit_logs.resource_manager_id
+  immutability_period_in_days           = local.retention_days
+  locked                                = local.lock_immutability_policy
+
+  # Lets append blobs receive new blocks without modifying committed ones
+  protected_append_writes_enabled = var.enable_protected_append_writes
+
+  lifecycle {
+    precondition {
+      condition     = !local.compliance.require_compliance_mode || local.lock_immutability_policy
+      error_message = "The ${coalesce(var.compliance_profile, "default")} compliance profile requires a locked immutability policy - set lock_immutability_policy = true"
+    }
+  }
+}
+
+# Version-level immutability for the audit container only (immutableStorageWithVersioning).
+# Azure only migrates a container that already has a container-level time-based retention
+# policy, so this runs after the policy above. Migration is one-way and long-running: the
+# action answers 202 and azapi polls the operation until it completes
+resource "azapi_resource_action" "version_level_worm" {
+  count = var.version_level_immutability == "container" ? 1 : 0
+
+  type        = "Microsoft.Storage/storageAccounts/blobServices/containers@2023-01-01"
+  resource_id = azurerm_storage_container.audit_logs.resource_manager_id
+  action      = "migrate"
+
+  timeouts {
+    create = "60m"
+  }
+
+  depends_on = [azurerm_storage_container_immutability_policy.audit_logs]
+}
+
+# Reads the container back so an unfinished migration fails the apply instead of passing silently
+data "azapi_resource" "version_level_worm" {
+  count = var.version_level_immutability == "container" ? 1 : 0
+
+  type        = "Microsoft.Storage/storageAccounts/blobServices/containers@2023-01-01"
+  resource_id = azurerm_storage_container.audit_logs.resource_manager_id
+
+  response_export_values = ["properties.immutableStorageWithVersioning"]
+
+  depends_on = [azapi_resource_action.version_level_worm]
+
+  lifecycle {
+    postcondition {
+      condition     = try(self.output.properties.immutableStorageWithVersioning.enabled, false) == true
+      error_message = "The audit container has not finished migrating to version-level immutability (migrationState ${try(self.output.properties.immutableStorageWithVersioning.migrationState, "unknown")})"
+    }
+  }
+}
+
+# Legal holds block deletion regardless of the retention period until every tag is cleared.
+# Azure only exposes legal holds as actions, so each tag is set on apply and cleared on removal
+resource "azapi_resource_action" "legal_hold" {
+  for_each = toset(var.legal_hold_tags)
+
+  type        = "Microsoft.Storage/storageAccounts/blobServices/containers@2023-01-01"
+  resource_id = azurerm_storage_container.audit_logs.resource_manager_id
+  action      = "setLegalHold"
+
+  body = {
+    tags = [each.value]
+  }
+
+  # Containers with a legal hold cannot be migrated to version-level immutability
+  depends_on = [azapi_resource_action.version_level_worm]
+}
+
+resource "azapi_resource_action" "legal_hold_clear" {
+  for_each = toset(var.legal_hold_tags)
+
+  type        = "Microsoft.Storage/storageAccounts/blobServices/containers@2023-01-01"
+  resource_id = azurerm_storage_container.audit_logs.resource_manager_id
+  action      = "clearLegalHold"
+  when        = "destroy"
+
+  body = {
+    tags = [each.value]
+  }
+}
+
+# Object replication - a logically separate copy of the audit container in another account.
+# The destination container is read-only for everyone while the policy exists.
+# Created destinations mirror the source's versioning, soft delete and immutability settings
+resource "azurerm_storage_account" "replica" {
+  provider                 = azurerm.replica
+  count                    = var.create_replication_destination ? 1 : 0
+  name                     = var.replication_destination_name
+  resource_group_name      = coalesce(var.replication_destination_resource_group_name, azurerm_storage_account.audit_logs.resource_group_name)
+  location                 = var.replication_destination_location
+  account_tier             = var.account_tier
+  account_replication_type = var.replication_type
+  account_kind             = "StorageV2"
+
+  min_tls_version                  = "TLS1_2"
+  https_traffic_only_enabled       = true
+  allow_nested_items_to_be_public  = false
+  shared_access_key_enabled        = var.enable_shared_key_access
+  public_network_access_enabled    = var.public_network_access_enabled
+  cross_tenant_replication_enabled = var.allow_cross_tenant_replication
+
+  # Object replication requires versioning on the destination
+  blob_properties {
+    versioning_enabled = true
+
+    delete_retention_policy {
+      days = local.retention_days
+    }
+
+    container_delete_retention_policy {
+      days = local.retention_days
+    }
+  }
+
+  # tfsec:ignore:azure-storage-default-action-deny - Default action is configurable via variable, defaults to "Deny" (secure)
+  network_rules {
+    default_action             = var.network_default_action
+    bypass                     = var.network_bypass
+    ip_rules                   = var.allowed_ip_ranges
+    virtual_network_subnet_ids = var.allowed_subnet_ids
+  }
+
+  infrastructure_encryption_enabled = var.enable_infrastructure_encryption
+
+  tags = merge(
+    var.tags,
+    {
+      Name      = var.replication_destination_name
+      Purpose   = "AuditLedger Immutable Audit Logs Replica"
+      Immutable = "true"
+      ManagedBy = "Terraform"
+    },
+    local.compliance_tags
+  )
+
+  lifecycle {
+    precondition {
+      condition     = var.replication_destination_name != null && var.replication_destination_location != null
+      error_message = "create_replication_destination requires replication_destination_name and replication_destination_location"
+    }
+  }
+}
+
+resource "azurerm_storage_container" "replica" {
+  provider              = azurerm.replica
+  count                 = var.create_replication_destination ? 1 : 0
+  name                  = local.replication_destination_container_name
+  storage_account_name  = azurerm_storage_account.replica[0].name
+  container_access_type = "private"
+}
+
+# Same retention and lock state as the source container
+resource "azurerm_storage_container_immutability_policy" "replica" {
+  provider                              = azurerm.replica
+  count                                 = var.create_replication_destination ? 1 : 0
+  storage_container_resource_manager_id = azurerm_storage_container.replica[0].resource_manager_id
+  immutability_period_in_days           = local.retention_days
+  locked                                = local.lock_immutability_policy
+  protected_append_writes_enabled       = var.enable_protected_append_writes
+}
+
+resource "azurerm_storage_object_replication" "audit_logs" {
+  count                          = local.replication_enabled ? 1 : 0
+  source_storage_account_id      = azurerm_storage_account.audit_logs.id
+  destination_storage_account_id = local.replication_destination_storage_account_id
+
+  rules {
+    source_container_name        = azurerm_storage_container.audit_logs.name
+    destination_container_name   = local.replication_destination_container_name
+    filter_out_blobs_with_prefix = var.replication_prefix_filters
+    copy_blobs_created_after     = var.replication_copy_existing_blobs ? "Everything" : "OnlyNewObjects"
+  }
+
+  depends_on = [
+    azurerm_storage_container.replica,
+    azurerm_storage_container_immutability_policy.replica,
+  ]
+
+  lifecycle {
+    precondition {
+      condition     = !(var.create_replication_destination && var.replication_destination_storage_account_id != null)
+      error_message = "Set either replication_destination_storage_account_id (existing account) or create_replication_destination, not both"
+    }
+  }
+}
+
 # Management Policy for lifecycle and immutability
+# Tiering never affects immutability - WORM applies in every tier.
+# Archived blobs must be rehydrated (hours) before they can be read
 resource "azurerm_storage_management_policy" "audit_logs" {
+  count              = local.lifecycle_has_tiers || local.lifecycle_delete_days != null ? 1 : 0
   storage_account_id = azurerm_storage_account.audit_logs.id
 
   rule {
@@ -110,38 +587,233 @@ resource "azurerm_storage_management_policy" "audit_logs" {
 
     filters {
       blob_types   = ["blockBlob"]
-      prefix_match = ["${var.container_name}/"]
+      prefix_match = local.lifecycle_prefix_match
+
+      dynamic "match_blob_index_tag" {
+        for_each = local.tiers.blob_index_tags
+
+        content {
+          name      = match_blob_index_tag.key
+          operation = "=="
+          value     = match_blob_index_tag.value
+        }
+      }
     }
 
     actions {
       base_blob {
-        # Tier to cool after 90 days
-        tier_to_cool_after_days_since_modification_greater_than = 90
+        # Tier by modification time, or by last access time when tracking is enabled
+        tier_to_cool_after_days_since_modification_greater_than    = local.lifecycle_by_access ? null : local.tiers.tier_to_cool_after_days
+        tier_to_cold_after_days_since_modification_greater_than    = local.lifecycle_by_access ? null : local.tiers.tier_to_cold_after_days
+        tier_to_archive_after_days_since_modification_greater_than = local.lifecycle_by_access ? null : local.tiers.tier_to_archive_after_days
 
-        # Tier to archive after 180 days
-        tier_to_archive_after_days_since_modification_greater_than = 180
+        tier_to_cool_after_days_since_last_access_time_greater_than    = local.lifecycle_by_access ? local.tiers.tier_to_cool_after_days : null
+        tier_to_cold_after_days_since_last_access_time_greater_than    = local.lifecycle_by_access ? local.tiers.tier_to_cold_after_days : null
+        tier_to_archive_after_days_since_last_access_time_greater_than = local.lifecycle_by_access ? local.tiers.tier_to_archive_after_days : null
+        auto_tier_to_hot_from_cool_enabled                             = local.tiers.auto_tier_to_hot_from_cool
+
+        # Rehydrated blobs are not re-archived until they have stayed in their new tier this long.
+        # Azure only accepts this alongside modification-time archiving
+        tier_to_archive_after_days_since_last_tier_change_greater_than = !local.lifecycle_by_access && local.tiers.tier_to_archive_after_days != null ? local.tiers.rehydrate_grace_days : null
 
         # Delete after retention period (but blob is immutable during retention)
-        delete_after_days_since_modification_greater_than = var.retention_days
+        delete_after_days_since_modification_greater_than = local.lifecycle_delete_days
       }
 
-      snapshot {
-        delete_after_days_since_creation_greater_than = var.retention_days
+      dynamic "snapshot" {
+        for_each = local.lifecycle_delete_days != null ? [local.lifecycle_delete_days] : []
+
+        content {
+          delete_after_days_since_creation_greater_than = snapshot.value
+        }
       }
 
-      version {
-        delete_after_days_since_creation = var.retention_days
+      dynamic "version" {
+        for_each = local.lifecycle_delete_days != null ? [local.lifecycle_delete_days] : []
+
+        content {
+          delete_after_days_since_creation = version.value
+        }
       }
     }
   }
+
+  lifecycle {
+    precondition {
+      condition     = local.lifecycle_delete_days == null || coalesce(local.lifecycle_delete_days, 0) >= local.retention_days
+      error_message = "lifecycle_tiers.delete_after_days (${coalesce(local.tiers.delete_after_days, 0)}) must not be shorter than retention_days (${local.retention_days}) - deletion cannot precede the immutability period"
+    }
+
+    precondition {
+      condition     = local.tiers.tier_to_archive_after_days == null || contains(["LRS", "GRS", "RAGRS"], var.replication_type)
+      error_message = "The archive tier is only available for LRS, GRS and RAGRS accounts - set lifecycle_tiers.tier_to_archive_after_days = null for ${var.replication_type}"
+    }
+  }
+}
+
+# The policy had no count before lifecycle_tiers; keep existing states from replacing the
+# account's single default policy
+moved {
+  from = azurerm_storage_management_policy.audit_logs
+  to   = azurerm_storage_management_policy.audit_logs[0]
+}
+
+# Least-privilege data-plane roles - the built-in Storage Blob Data Contributor role includes delete
+# Writer role - create, append, read, list and tag blobs; no delete, no immutability policy changes
+resource "azurerm_role_definition" "auditledger_writer" {
+  name              = "AuditLedger Audit Log Writer (${var.storage_account_name})"
+  scope             = azurerm_storage_account.audit_logs.id
+  description       = "Write, append, read, list and tag AuditLedger audit log blobs. Cannot delete blobs or change immutability policies."
+  assignable_scopes = [azurerm_storage_account.audit_logs.id]
+
+  permissions {
+    actions = ["Microsoft.Storage/storageAccounts/blobServices/containers/read"]
+
+    data_actions = concat(
+      [
+        "${local.blob_data_action}/read",
+        "${local.blob_data_action}/write",
+        "${local.blob_data_action}/add/action",
+        "${local.blob_data_action}/tags/read",
+        "${local.blob_data_action}/tags/write",
+      ],
+      # Per-blob retention with version-level immutability (can only extend the default)
+      var.allow_blob_retention_overrides ? ["${local.blob_data_action}/immutableStorage/runAsSuperUser/action"] : []
+    )
+
+    not_data_actions = local.denied_data_actions
+  }
+
+  lifecycle {
+    precondition {
+      condition     = !var.allow_blob_retention_overrides || var.version_level_immutability != "disabled"
+      error_message = "allow_blob_retention_overrides requires version_level_immutability to be container or account"
+    }
+  }
+}
+
+# Reader role for auditors - read, list and read tags only
+resource "azurerm_role_definition" "auditledger_reader" {
+  name              = "AuditLedger Audit Log Reader (${var.storage_account_name})"
+  scope             = azurerm_storage_account.audit_logs.id
+  description       = "Read and list AuditLedger audit log blobs and their tags."
+  assignable_scopes = [azurerm_storage_account.audit_logs.id]
+
+  permissions {
+    actions = ["Microsoft.Storage/storageAccounts/blobServices/containers/read"]
+
+    data_actions = [
+      "${local.blob_data_action}/read",
+      "${local.blob_data_action}/tags/read",
+    ]
+
+    not_data_actions = local.denied_data_actions
+  }
+}
+
+# Admin role - manage the container immutability policy and legal holds (extremely privileged).
+# Policies can be extended and locked but never deleted; blob data is read-only
+resource "azurerm_role_definition" "auditledger_admin" {
+  count             = length(var.admin_principal_ids) > 0 ? 1 : 0
+  name              = "AuditLedger Audit Log Admin (${var.storage_account_name})"
+  scope             = azurerm_storage_account.audit_logs.id
+  description       = "Manage immutability policies and legal holds on AuditLedger audit log containers. Cannot delete blobs or immutability policies."
+  assignable_scopes = [azurerm_storage_account.audit_logs.id]
+
+  permissions {
+    actions = [
+      "Microsoft.Storage/storageAccounts/blobServices/containers/read",
+      "Microsoft.Storage/storageAccounts/blobServices/containers/immutabilityPolicies/read",
+      "Microsoft.Storage/storageAccounts/blobServices/containers/immutabilityPolicies/write",
+      "Microsoft.Storage/storageAccounts/blobServices/containers/immutabilityPolicies/extend/action",
+      "Microsoft.Storage/storageAccounts/blobServices/containers/immutabilityPolicies/lock/action",
+      "Microsoft.Storage/storageAccounts/blobServices/containers/setLegalHold/action",
+      "Microsoft.Storage/storageAccounts/blobServices/containers/clearLegalHold/action",
+    ]
+
+    not_actions = ["Microsoft.Storage/storageAccounts/blobServices/containers/immutabilityPolicies/delete"]
+
+    data_actions = [
+      "${local.blob_data_action}/read",
+      "${local.blob_data_action}/tags/read",
+    ]
+
+    not_data_actions = local.denied_data_actions
+  }
 }
 
-# Role Assignment for Managed Identity (if enabled)
-resource "azurerm_role_assignment" "storage_blob_data_contributor" {
-  count                = var.enable_managed_identity && var.managed_identity_principal_id != null ? 1 : 0
-  scope                = azurerm_storage_account.audit_logs.id
-  role_definition_name = "Storage Blob Data Contributor"
-  principal_id         = var.managed_identity_principal_id
+# Role assignments are scoped to the audit container, not the whole storage account.
+# count (not for_each) so principal IDs of identities created in the same apply can be used
+resource "azurerm_role_assignment" "auditledger_writer" {
+  count              = length(local.writer_principal_ids)
+  scope              = azurerm_storage_container.audit_logs.resource_manager_id
+  role_definition_id = azurerm_role_definition.auditledger_writer.role_definition_resource_id
+  principal_id       = local.writer_principal_ids[count.index]
+}
+
+resource "azurerm_role_assignment" "auditledger_reader" {
+  count              = length(var.reader_principal_ids)
+  scope              = azurerm_storage_container.audit_logs.resource_manager_id
+  role_definition_id = azurerm_role_definition.auditledger_reader.role_definition_resource_id
+  principal_id       = var.reader_principal_ids[count.index]
+}
+
+resource "azurerm_role_assignment" "auditledger_admin" {
+  count              = length(var.admin_principal_ids)
+  scope              = azurerm_storage_container.audit_logs.resource_manager_id
+  role_definition_id = azurerm_role_definition.auditledger_admin[0].role_definition_resource_id
+  principal_id       = var.admin_principal_ids[count.index]
+}
+
+# Management locks - Contributor cannot delete the account (and every immutable blob with it).
+# Removing a lock needs Microsoft.Authorization/locks/delete (Owner or User Access Administrator).
+# Created after, and destroyed before, everything else in the module so terraform destroy can proceed
+resource "azurerm_management_lock" "storage_account" {
+  count      = var.enable_management_locks ? 1 : 0
+  name       = "${var.storage_account_name}-lock"
+  scope      = azurerm_storage_account.audit_logs.id
+  lock_level = var.storage_account_lock_level
+  notes      = "AuditLedger immutable audit log storage - do not delete"
+
+  depends_on = [
+    azurerm_storage_container_immutability_policy.audit_logs,
+    azapi_resource_action.legal_hold,
+    azurerm_storage_management_policy.audit_logs,
+    azurerm_storage_account_customer_managed_key.audit_logs,
+    azurerm_private_endpoint.blob,
+    azurerm_role_assignment.auditledger_writer,
+    azurerm_role_assignment.auditledger_reader,
+    azurerm_role_assignment.auditledger_admin,
+    azurerm_advanced_threat_protection.audit_logs,
+    azurerm_monitor_diagnostic_setting.audit_logs,
+    azurerm_monitor_diagnostic_setting.blob,
+    azurerm_monitor_activity_log_alert.audit_logs,
+    azurerm_storage_object_replication.audit_logs,
+  ]
+}
+
+resource "azurerm_management_lock" "replica" {
+  provider   = azurerm.replica
+  count      = var.enable_management_locks && var.create_replication_destination ? 1 : 0
+  name       = "${var.replication_destination_name}-lock"
+  scope      = azurerm_storage_account.replica[0].id
+  lock_level = var.storage_account_lock_level
+  notes      = "AuditLedger immutable audit log replica - do not delete"
+
+  depends_on = [
+    azurerm_storage_container_immutability_policy.replica,
+    azurerm_storage_object_replication.audit_logs,
+  ]
+}
+
+resource "azurerm_management_lock" "resource_group" {
+  count      = var.enable_management_locks && var.create_resource_group ? 1 : 0
+  name       = "${var.resource_group_name}-lock"
+  scope      = azurerm_resource_group.audit_logs[0].id
+  lock_level = var.resource_group_lock_level
+  notes      = "AuditLedger immutable audit log storage - do not delete"
+
+  depends_on = [azurerm_management_lock.storage_account]
 }
 
 # Advanced Threat Protection
@@ -153,11 +825,35 @@ resource "azurerm_advanced_threat_protection" "audit_logs" {
 }
 
 # Diagnostic Settings for monitoring
+# The account itself only emits metrics - read, write and delete logs come from the blob service
 resource "azurerm_monitor_diagnostic_setting" "audit_logs" {
-  count                      = var.log_analytics_workspace_id != null ? 1 : 0
-  name                       = "auditledger-diagnostics"
-  target_resource_id         = azurerm_storage_account.audit_logs.id
-  log_analytics_workspace_id = var.log_analytics_workspace_id
+  count                          = local.diagnostics_enabled ? 1 : 0
+  name                           = "auditledger-diagnostics"
+  target_resource_id             = azurerm_storage_account.audit_logs.id
+  log_analytics_workspace_id     = var.log_analytics_workspace_id
+  eventhub_authorization_rule_id = var.diagnostic_event_hub_authorization_rule_id
+  eventhub_name                  = var.diagnostic_event_hub_name
+  storage_account_id             = var.diagnostic_storage_account_id
+
+  metric {
+    category = "Transaction"
+    enabled  = true
+  }
+
+  metric {
+    category = "Capacity"
+    enabled  = true
+  }
+}
+
+resource "azurerm_monitor_diagnostic_setting" "blob" {
+  count                          = local.diagnostics_enabled ? 1 : 0
+  name                           = "auditledger-blob-diagnostics"
+  target_resource_id             = "${azurerm_storage_account.audit_logs.id}/blobServices/default"
+  log_analytics_workspace_id     = var.log_analytics_workspace_id
+  eventhub_authorization_rule_id = var.diagnostic_event_hub_authorization_rule_id
+  eventhub_name                  = var.diagnostic_event_hub_name
+  storage_account_id             = var.diagnostic_storage_account_id
 
   enabled_log {
     category = "StorageRead"
@@ -180,4 +876,78 @@ resource "azurerm_monitor_diagnostic_setting" "audit_logs" {
     category = "Capacity"
     enabled  = true
   }
+
+  lifecycle {
+    precondition {
+      condition     = var.diagnostic_event_hub_name == null || var.diagnostic_event_hub_authorization_rule_id != null
+      error_message = "diagnostic_event_hub_name requires diagnostic_event_hub_authorization_rule_id"
+    }
+
+    # Logging blob access into the same account would log its own writes
+    precondition {
+      condition     = var.diagnostic_storage_account_id != azurerm_storage_account.audit_logs.id
+      error_message = "diagnostic_storage_account_id must be a separate storage account"
+    }
+  }
+}
+
+# Activity Log alerts on control-plane changes to the audit storage
+resource "azurerm_monitor_activity_log_alert" "audit_logs" {
+  for_each            = length(var.alert_action_group_ids) > 0 ? local.activity_log_alerts : {}
+  name                = "${var.storage_account_name}-${each.key}"
+  resource_group_name = azurerm_storage_account.audit_logs.resource_group_name
+  location            = "global"
+  scopes              = [each.key == "lock-delete" ? local.resource_group_id : azurerm_storage_account.audit_logs.id]
+  description         = "AuditLedger: ${each.value.description}"
+
+  criteria {
+    category       = "Administrative"
+    operation_name = each.value.operation
+  }
+
+  dynamic "action" {
+    for_each = var.alert_action_group_ids
+
+    content {
+      action_group_id = action.value
+    }
+  }
+
+  tags = var.tags
+}
+
+# Application configuration - the writer role is an Azure RBAC role, so AuditLedger must
+# authenticate with Entra ID (managed identity); immutability is enforced by the container
+# policy and needs no request headers
+locals {
+  app_storage_settings = {
+    Provider = "AzureBlob"
+    AzureBlob = {
+      AccountName              = azurerm_storage_account.audit_logs.name
+      ContainerName            = azurerm_storage_container.audit_logs.name
+      BlobEndpoint             = azurerm_storage_account.audit_logs.primary_blob_endpoint
+      UseAzurite               = false
+      Authentication           = "EntraId"
+      Prefix                   = var.app_key_prefix
+      RetentionDays            = local.retention_days
+      ImmutabilityPolicyLocked = local.lock_immutability_policy
+      VersionLevelImmutability = var.version_level_immutability != "disabled"
+      ProtectedAppendWrites    = var.enable_protected_append_writes
+    }
+  }
+
+  # .NET configuration keys: sections separated by __
+  app_configuration_env = {
+    AuditLedger__Storage__Provider                            = "AzureBlob"
+    AuditLedger__Storage__AzureBlob__AccountName              = azurerm_storage_account.audit_logs.name
+    AuditLedger__Storage__AzureBlob__ContainerName            = azurerm_storage_container.audit_logs.name
+    AuditLedger__Storage__AzureBlob__BlobEndpoint             = azurerm_storage_account.audit_logs.primary_blob_endpoint
+    AuditLedger__Storage__AzureBlob__UseAzurite               = "false"
+    AuditLedger__Storage__AzureBlob__Authentication           = "EntraId"
+    AuditLedger__Storage__AzureBlob__Prefix                   = var.app_key_prefix
+    AuditLedger__Storage__AzureBlob__RetentionDays            = tostring(local.retention_days)
+    AuditLedger__Storage__AzureBlob__ImmutabilityPolicyLocked = tostring(local.lock_immutability_policy)
+    AuditLedger__Storage__AzureBlob__VersionLevelImmutability = tostring(var.version_level_immutability != "disabled")
+    AuditLedger__Storage__AzureBlob__ProtectedAppendWrites    = tostring(var.enable_protected_append_writes)
+  }
 }
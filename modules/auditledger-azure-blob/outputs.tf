@@ -20,6 +20,40 @@ output "container_name" {
   value       = azurerm_storage_container.audit_logs.name
 }
 
+output "container_resource_manager_id" {
+  description = "Azure Resource Manager ID of the audit logs container (scope of the role assignments)"
+  value       = azurerm_storage_container.audit_logs.resource_manager_id
+}
+
+output "writer_role_definition_id" {
+  description = "Resource ID of the least-privilege AuditLedger writer role definition"
+  value       = azurerm_role_definition.auditledger_writer.role_definition_resource_id
+}
+
+output "reader_role_definition_id" {
+  description = "Resource ID of the read-only AuditLedger reader role definition"
+  value       = azurerm_role_definition.auditledger_reader.role_definition_resource_id
+}
+
+output "admin_role_definition_id" {
+  description = "Resource ID of the AuditLedger admin role definition (null if admin_principal_ids is empty)"
+  value       = one(azurerm_role_definition.auditledger_admin[*].role_definition_resource_id)
+}
+
+output "private_endpoint_ip" {
+  description = "Private IP address of the blob private endpoint (null if not created)"
+  value       = try(azurerm_private_endpoint.blob[0].private_service_connection[0].private_ip_address, null)
+}
+
+output "private_endpoint_fqdn" {
+  description = "FQDN that resolves to the blob private endpoint, from its DNS zone record or custom DNS config (null if not created)"
+  value = try(
+    azurerm_private_endpoint.blob[0].private_dns_zone_configs[0].record_sets[0].fqdn,
+    azurerm_private_endpoint.blob[0].custom_dns_configs[0].fqdn,
+    null
+  )
+}
+
 output "resource_group_name" {
   description = "Name of the resource group"
   value       = var.create_resource_group ? azurerm_resource_group.audit_logs[0].name : var.resource_group_name
@@ -33,13 +67,103 @@ output "managed_identity_principal_id" {
 output "immutability_configuration" {
   description = "Immutability configuration for verification"
   value = {
-    versioning_enabled = true
-    retention_days     = var.retention_days
-    soft_delete_days   = var.retention_days
+    versioning_enabled              = true
+    retention_days                  = local.retention_days
+    soft_delete_days                = local.retention_days
+    immutability_policy_enabled     = true
+    version_level_immutability      = var.version_level_immutability
+    immutability_period_days        = azurerm_storage_container_immutability_policy.audit_logs.immutability_period_in_days
+    immutability_policy_locked      = azurerm_storage_container_immutability_policy.audit_logs.locked
+    protected_append_writes_enabled = azurerm_storage_container_immutability_policy.audit_logs.protected_append_writes_enabled
+    legal_hold_tags                 = sort(keys(azapi_resource_action.legal_hold))
+  }
+}
+
+output "security_configuration" {
+  description = "Account security settings for verification"
+  value = {
+    https_traffic_only_enabled    = azurerm_storage_account.audit_logs.https_traffic_only_enabled
+    min_tls_version               = azurerm_storage_account.audit_logs.min_tls_version
+    shared_access_key_enabled     = var.enable_shared_key_access
+    public_network_access_enabled = var.public_network_access_enabled
+    network_default_action        = var.network_default_action
+    change_feed_enabled           = true
+    change_feed_retention_days    = local.retention_days
+    threat_protection_enabled     = var.enable_threat_protection
+  }
+}
+
+output "encryption_configuration" {
+  description = "Encryption configuration for verification"
+  value = {
+    customer_managed_key_enabled      = var.enable_customer_managed_key
+    key_vault_id                      = local.key_vault_id
+    key_name                          = local.key_name
+    key_rotation_days                 = local.create_key ? var.key_rotation_days : null
+    infrastructure_encryption_enabled = azurerm_storage_account.audit_logs.infrastructure_encryption_enabled
+  }
+}
+
+output "replication_configuration" {
+  description = "Object replication of the audit container (enabled is false if not configured)"
+  value = {
+    enabled                              = local.replication_enabled
+    destination_created                  = var.create_replication_destination
+    destination_storage_account_id       = local.replication_destination_storage_account_id
+    destination_container_name           = local.replication_enabled ? local.replication_destination_container_name : null
+    prefix_filters                       = var.replication_prefix_filters
+    copy_existing_blobs                  = var.replication_copy_existing_blobs
+    source_object_replication_id         = one(azurerm_storage_object_replication.audit_logs[*].source_object_replication_id)
+    destination_object_replication_id    = one(azurerm_storage_object_replication.audit_logs[*].destination_object_replication_id)
+    destination_immutability_period_days = one(azurerm_storage_container_immutability_policy.replica[*].immutability_period_in_days)
+    destination_immutability_locked      = one(azurerm_storage_container_immutability_policy.replica[*].locked)
+  }
+}
+
+output "app_configuration" {
+  description = "AuditLedger application storage settings as an object, appsettings JSON and YAML, and .NET environment variables"
+  value = {
+    settings = local.app_storage_settings
+    json     = jsonencode({ AuditLedger = { Storage = local.app_storage_settings } })
+    yaml     = yamlencode({ AuditLedger = { Storage = local.app_storage_settings } })
+    env      = local.app_configuration_env
+  }
+}
+
+output "monitoring_configuration" {
+  description = "Diagnostic sinks and Activity Log alerts configured for the storage account"
+  value = {
+    diagnostics_enabled             = local.diagnostics_enabled
+    log_analytics_workspace_id      = var.log_analytics_workspace_id
+    event_hub_authorization_rule_id = var.diagnostic_event_hub_authorization_rule_id
+    event_hub_name                  = var.diagnostic_event_hub_name
+    storage_account_id              = var.diagnostic_storage_account_id
+    activity_log_alert_ids          = { for name, alert in azurerm_monitor_activity_log_alert.audit_logs : name => alert.id }
+  }
+}
+
+output "management_locks" {
+  description = "Management lock levels on the storage account and resource group (null if not locked)"
+  value = {
+    storage_account = one(azurerm_management_lock.storage_account[*].lock_level)
+    resource_group  = one(azurerm_management_lock.resource_group[*].lock_level)
+    replica         = one(azurerm_management_lock.replica[*].lock_level)
+  }
+}
+
+output "compliance_profile" {
+  description = "Compliance profile applied to the storage account and its requirements (profile is null if none)"
+  value = {
+    profile                      = var.compliance_profile
+    tag                          = local.compliance.tag
+    minimum_retention_days       = local.compliance.minimum_retention_days
+    require_compliance_mode      = local.compliance.require_compliance_mode
+    require_customer_managed_key = local.compliance.require_customer_managed_key
+    require_access_logging       = local.compliance.require_access_logging
   }
 }
 
 output "immutability_verified" {
-  description = "Confirmation that immutability is enforced"
-  value       = true
+  description = "Whether the audit container's immutability policy is locked, read from the policy"
+  value       = azurerm_storage_container_immutability_policy.audit_logs.locked
 }
@@ -60,17 +60,119 @@ variable "replication_type" {
   }
 }
 
+variable "compliance_profile" {
+  type        = string
+  description = "Compliance profile that sets minimum retention, logging and tag requirements: soc2, hipaa, pci_dss, sox, gdpr_minimal or finra_17a4"
+  default     = null
+
+  validation {
+    condition     = var.compliance_profile == null ? true : contains(["soc2", "hipaa", "pci_dss", "sox", "gdpr_minimal", "finra_17a4"], var.compliance_profile)
+    error_message = "Compliance profile must be one of: soc2, hipaa, pci_dss, sox, gdpr_minimal, finra_17a4"
+  }
+}
+
 variable "retention_days" {
   type        = number
-  description = "Number of days to retain audit logs (minimum 365 for compliance)"
-  default     = 2555 # 7 years for SOC 2
+  description = "Number of days to retain audit logs (minimum 365 for compliance). Defaults to the compliance profile's retention, or 2555 days (7 years) without a profile"
+  default     = null
 
   validation {
-    condition     = var.retention_days >= 365
+    condition     = coalesce(var.retention_days, 365) >= 365
     error_message = "Retention period must be at least 365 days for compliance"
   }
 }
 
+variable "lock_immutability_policy" {
+  type        = bool
+  description = "Lock the container's time-based immutability policy. A locked policy can only be extended, never shortened or removed - IRREVERSIBLE. Defaults to true for compliance profiles that require COMPLIANCE mode, false otherwise"
+  default     = null
+}
+
+variable "enable_protected_append_writes" {
+  type        = bool
+  description = "Allow new blocks to be appended to append blobs while the immutability policy protects existing data"
+  default     = true
+}
+
+variable "version_level_immutability" {
+  type        = string
+  description = "Version-level immutability scope: disabled (container-level policy only), container (migrate the audit container) or account (all containers, only possible when the storage account is created)"
+  default     = "disabled"
+
+  validation {
+    condition     = contains(["disabled", "container", "account"], var.version_level_immutability)
+    error_message = "Version-level immutability must be one of: disabled, container, account"
+  }
+}
+
+variable "legal_hold_tags" {
+  type        = list(string)
+  description = "Legal hold tags to set on the audit container (blobs cannot be deleted while any tag is set). Removing a tag clears it"
+  default     = []
+
+  validation {
+    condition     = length(var.legal_hold_tags) <= 10 && alltrue([for tag in var.legal_hold_tags : can(regex("^[a-zA-Z0-9]{3,23}$", tag))])
+    error_message = "Up to 10 legal hold tags are allowed, each 3-23 alphanumeric characters"
+  }
+}
+
+variable "lifecycle_tiers" {
+  type = object({
+    prefix_filters             = optional(list(string), [])
+    blob_index_tags            = optional(map(string), {})
+    last_access_time_tracking  = optional(bool, false)
+    auto_tier_to_hot_from_cool = optional(bool, false)
+    tier_to_cool_after_days    = optional(number, 90)
+    tier_to_cold_after_days    = optional(number)
+    tier_to_archive_after_days = optional(number, 180)
+    rehydrate_grace_days       = optional(number, 7)
+    delete_after_days          = optional(number)
+    skip_deletion              = optional(bool, false)
+  })
+  description = <<-EOT
+    Lifecycle tiering and expiration of audit blobs. Set a tier to null to skip it.
+    prefix_filters are relative to the audit container; blob_index_tags must all match.
+    With last_access_time_tracking, tiering counts days since last access instead of modification.
+    rehydrate_grace_days keeps rehydrated blobs out of archive for that many days after a tier change (modification-time tiering only).
+    delete_after_days defaults to retention_days and cannot be shorter; skip_deletion keeps blobs indefinitely
+  EOT
+  default     = {}
+
+  validation {
+    condition = alltrue([
+      for days in [
+        var.lifecycle_tiers.tier_to_cool_after_days,
+        var.lifecycle_tiers.tier_to_cold_after_days,
+        var.lifecycle_tiers.tier_to_archive_after_days,
+        var.lifecycle_tiers.rehydrate_grace_days,
+        var.lifecycle_tiers.delete_after_days,
+      ] : days == null ? true : days >= 0 && floor(days) == days
+    ])
+    error_message = "lifecycle_tiers day counts must be whole numbers of 0 or more"
+  }
+
+  validation {
+    condition = alltrue([
+      for pair in [
+        [var.lifecycle_tiers.tier_to_cool_after_days, var.lifecycle_tiers.tier_to_cold_after_days],
+        [var.lifecycle_tiers.tier_to_cool_after_days, var.lifecycle_tiers.tier_to_archive_after_days],
+        [var.lifecycle_tiers.tier_to_cold_after_days, var.lifecycle_tiers.tier_to_archive_after_days],
+      ] : pair[0] == null || pair[1] == null ? true : pair[0] < pair[1]
+    ])
+    error_message = "lifecycle_tiers must move blobs to cooler tiers in order: cool before cold before archive"
+  }
+
+  validation {
+    condition     = !var.lifecycle_tiers.auto_tier_to_hot_from_cool || var.lifecycle_tiers.last_access_time_tracking
+    error_message = "lifecycle_tiers.auto_tier_to_hot_from_cool requires last_access_time_tracking"
+  }
+
+  validation {
+    condition     = length(var.lifecycle_tiers.blob_index_tags) <= 10
+    error_message = "Lifecycle rules support at most 10 blob index tag filters"
+  }
+}
+
 variable "network_default_action" {
   type        = string
   description = "Default action for network rules (Allow or Deny)"
@@ -105,6 +207,100 @@ variable "allowed_subnet_ids" {
   default     = []
 }
 
+variable "public_network_access_enabled" {
+  type        = bool
+  description = "Allow access over the public endpoint (subject to the network rules). Set false to allow Private Link only"
+  default     = true
+}
+
+variable "private_endpoint_subnet_id" {
+  type        = string
+  description = "Subnet ID for a blob private endpoint (optional, no private endpoint if not provided)"
+  default     = null
+}
+
+variable "private_dns_zone_id" {
+  type        = string
+  description = "ID of an existing privatelink.blob.core.windows.net private DNS zone for the private endpoint's A record"
+  default     = null
+}
+
+variable "create_private_dns_zone" {
+  type        = bool
+  description = "Create a privatelink.blob.core.windows.net private DNS zone in the storage account's resource group"
+  default     = false
+}
+
+variable "private_dns_zone_virtual_network_ids" {
+  type        = list(string)
+  description = "Virtual network IDs to link to the created private DNS zone"
+  default     = []
+}
+
+variable "replication_destination_storage_account_id" {
+  type        = string
+  description = "ID of an existing storage account (any subscription or tenant) to replicate the audit container to (optional)"
+  default     = null
+}
+
+variable "create_replication_destination" {
+  type        = bool
+  description = "Create a destination storage account with the same immutability settings and replicate the audit container to it"
+  default     = false
+}
+
+variable "replication_destination_name" {
+  type        = string
+  description = "Name of the created destination storage account (required if create_replication_destination is true)"
+  default     = null
+
+  validation {
+    condition     = var.replication_destination_name == null || can(regex("^[a-z0-9]{3,24}$", var.replication_destination_name))
+    error_message = "Storage account name must be 3-24 characters, lowercase letters and numbers only"
+  }
+}
+
+variable "replication_destination_resource_group_name" {
+  type        = string
+  description = "Existing resource group for the created destination storage account (defaults to the source resource group)"
+  default     = null
+}
+
+variable "replication_destination_location" {
+  type        = string
+  description = "Azure region of the created destination storage account (required if create_replication_destination is true)"
+  default     = null
+}
+
+variable "replication_destination_container_name" {
+  type        = string
+  description = "Destination container name (defaults to container_name). Must already exist in an existing destination account"
+  default     = null
+}
+
+variable "replication_prefix_filters" {
+  type        = list(string)
+  description = "Blob name prefixes to replicate (up to 5, all blobs if empty)"
+  default     = []
+
+  validation {
+    condition     = length(var.replication_prefix_filters) <= 5
+    error_message = "Object replication supports at most 5 prefix filters per rule"
+  }
+}
+
+variable "replication_copy_existing_blobs" {
+  type        = bool
+  description = "Also replicate blobs that existed before the replication policy was created (otherwise only new blobs)"
+  default     = false
+}
+
+variable "allow_cross_tenant_replication" {
+  type        = bool
+  description = "Allow object replication to a destination account in another Azure AD tenant"
+  default     = false
+}
+
 variable "enable_shared_key_access" {
   type        = bool
   description = "Allow access via shared access keys (set false for managed identity only)"
@@ -113,16 +309,115 @@ variable "enable_shared_key_access" {
 
 variable "enable_managed_identity" {
   type        = bool
-  description = "Enable system-assigned managed identity for the storage account"
+  description = "Enable system-assigned managed identity for the storage account itself (does not grant anyone access)"
   default     = true
 }
 
+variable "writer_principal_ids" {
+  type        = list(string)
+  description = "Object IDs of managed identities, service principals or groups that AuditLedger writes with (writer role on the audit container)"
+  default     = []
+}
+
+variable "reader_principal_ids" {
+  type        = list(string)
+  description = "Object IDs of auditor identities, service principals or groups that get the read-only reader role on the audit container"
+  default     = []
+}
+
+variable "admin_principal_ids" {
+  type        = list(string)
+  description = "Object IDs that can manage the immutability policy and legal holds on the audit container (extremely privileged)"
+  default     = []
+}
+
 variable "managed_identity_principal_id" {
   type        = string
-  description = "Principal ID of the managed identity to grant access (e.g., App Service, AKS)"
+  description = "Deprecated: use writer_principal_ids. Principal ID added to the writers"
+  default     = null
+}
+
+variable "allow_blob_retention_overrides" {
+  type        = bool
+  description = "Allow the writer role to set per-blob retention (requires version_level_immutability). Also allows removing unlocked per-blob policies"
+  default     = false
+}
+
+variable "enable_customer_managed_key" {
+  type        = bool
+  description = "Encrypt the storage account with a customer-managed key in Key Vault (requires enable_managed_identity)"
+  default     = false
+}
+
+variable "key_vault_id" {
+  type        = string
+  description = "ID of an existing Key Vault with purge protection for the customer-managed key (optional, a vault is created if not provided)"
+  default     = null
+}
+
+variable "key_vault_name" {
+  type        = string
+  description = "Name of the Key Vault to create (defaults to <storage_account_name>-kv)"
+  default     = null
+}
+
+variable "key_name" {
+  type        = string
+  description = "Name of an existing RSA key in the Key Vault (optional, a key with a rotation policy is created if not provided)"
   default     = null
 }
 
+variable "key_rotation_days" {
+  type        = number
+  description = "Days after creation that the created key is rotated automatically"
+  default     = 365
+
+  validation {
+    condition     = var.key_rotation_days >= 28
+    error_message = "Key rotation must be at least 28 days"
+  }
+}
+
+variable "grant_key_vault_access" {
+  type        = bool
+  description = "Grant the storage account identity Key Vault Crypto Service Encryption User on the Key Vault (requires an RBAC-enabled vault)"
+  default     = true
+}
+
+variable "enable_infrastructure_encryption" {
+  type        = bool
+  description = "Enable infrastructure (double) encryption. Can only be set when the storage account is created"
+  default     = false
+}
+
+variable "enable_management_locks" {
+  type        = bool
+  description = "Create management locks on the storage account and, if created by this module, the resource group. Recommended for production; destroying locked resources needs permission to delete locks"
+  default     = false
+}
+
+variable "storage_account_lock_level" {
+  type        = string
+  description = "Management lock level on the storage account: CanNotDelete or ReadOnly"
+  default     = "CanNotDelete"
+
+  validation {
+    condition     = contains(["CanNotDelete", "ReadOnly"], var.storage_account_lock_level)
+    error_message = "storage_account_lock_level must be CanNotDelete or ReadOnly"
+  }
+}
+
+variable "resource_group_lock_level" {
+  type        = string
+  description = "Management lock level on the resource group when create_resource_group is true: CanNotDelete or ReadOnly"
+  default     = "CanNotDelete"
+
+  validation {
+    condition     = contains(["CanNotDelete", "ReadOnly"], var.resource_group_lock_level)
+    error_message = "resource_group_lock_level must be CanNotDelete or ReadOnly"
+  }
+}
+
 variable "enable_threat_protection" {
   type        = bool
   description = "Enable Advanced Threat Protection"
@@ -135,6 +430,41 @@ variable "log_analytics_workspace_id" {
   default     = null
 }
 
+variable "diagnostic_event_hub_authorization_rule_id" {
+  type        = string
+  description = "Event Hub namespace authorization rule ID to stream blob diagnostics to, e.g. for a SIEM (optional)"
+  default     = null
+}
+
+variable "diagnostic_event_hub_name" {
+  type        = string
+  description = "Event Hub to stream diagnostics to (defaults to one per log category, requires diagnostic_event_hub_authorization_rule_id)"
+  default     = null
+}
+
+variable "diagnostic_storage_account_id" {
+  type        = string
+  description = "ID of a separate storage account to archive diagnostics to (optional, must not be this storage account)"
+  default     = null
+}
+
+variable "alert_action_group_ids" {
+  type        = list(string)
+  description = "Action group IDs notified by Activity Log alerts on key listing, immutability policy, legal hold, account configuration and lock changes (no alerts if empty)"
+  default     = []
+}
+
+variable "app_key_prefix" {
+  type        = string
+  description = "Blob name prefix the application writes audit logs under, for the app_configuration output (empty for the container root)"
+  default     = ""
+
+  validation {
+    condition     = var.app_key_prefix == "" || (endswith(var.app_key_prefix, "/") && !startswith(var.app_key_prefix, "/"))
+    error_message = "app_key_prefix must be empty or end with / and must not start with /"
+  }
+}
+
 variable "tags" {
   type        = map(string)
   description = "Additional tags for resources"
@@ -0,0 +1,86 @@
+# AuditLedger Compliance Profile Module
+# Data-only module that resolves a compliance framework to the retention, lock, encryption,
+# logging and tagging requirements enforced by the storage modules
+
+terraform {
+  required_version = ">= 1.5.0"
+}
+
+locals {
+  # Minimum retention reflects each framework's record-keeping requirement:
+  #   soc2         - 1 year of audit evidence (7 years kept by default, as before)
+  #   hipaa        - 6 years for required documentation (45 CFR 164.316(b)(2))
+  #   pci_dss      - 1 year of audit trail history (PCI DSS 10.5.1)
+  #   sox          - 7 years for audit records (SOX section 802)
+  #   gdpr_minimal - module floor only; GOVERNANCE allowed so erasure requests can be honored
+  #   finra_17a4   - 6 years in non-rewriteable, non-erasable storage (SEC 17a-4(a), (f))
+  profiles = {
+    soc2 = {
+      tag                          = "SOC2"
+      minimum_retention_days       = 365
+      default_retention_days       = 2555
+      lock_mode                    = "COMPLIANCE"
+      require_compliance_mode      = true
+      require_customer_managed_key = false
+      require_access_logging       = true
+    }
+    hipaa = {
+      tag                          = "HIPAA"
+      minimum_retention_days       = 2190
+      default_retention_days       = 2190
+      lock_mode                    = "COMPLIANCE"
+      require_compliance_mode      = true
+      require_customer_managed_key = true
+      require_access_logging       = true
+    }
+    pci_dss = {
+      tag                          = "PCI-DSS"
+      minimum_retention_days       = 365
+      default_retention_days       = 365
+      lock_mode                    = "COMPLIANCE"
+      require_compliance_mode      = true
+      require_customer_managed_key = true
+      require_access_logging       = true
+    }
+    sox = {
+      tag                          = "SOX"
+      minimum_retention_days       = 2555
+      default_retention_days       = 2555
+      lock_mode                    = "COMPLIANCE"
+      require_compliance_mode      = true
+      require_customer_managed_key = false
+      require_access_logging       = true
+    }
+    gdpr_minimal = {
+      tag                          = "GDPR"
+      minimum_retention_days       = 365
+      default_retention_days       = 365
+      lock_mode                    = "GOVERNANCE"
+      require_compliance_mode      = false
+      require_customer_managed_key = false
+      require_access_logging       = false
+    }
+    finra_17a4 = {
+      tag                          = "FINRA-17a-4"
+      minimum_retention_days       = 2190
+      default_retention_days       = 2190
+      lock_mode                    = "COMPLIANCE"
+      require_compliance_mode      = true
+      require_customer_managed_key = false
+      require_access_logging       = true
+    }
+  }
+
+  # Without a profile the modules keep their historical defaults and claim no framework
+  no_profile = {
+    tag                          = null
+    minimum_retention_days       = 365
+    default_retention_days       = 2555
+    lock_mode                    = "COMPLIANCE"
+    require_compliance_mode      = false
+    require_customer_managed_key = false
+    require_access_logging       = false
+  }
+
+  settings = var.profile != null ? local.profiles[var.profile] : local.no_profile
+}
@@ -0,0 +1,16 @@
+# AuditLedger Compliance Profile Module Outputs
+
+output "name" {
+  description = "Name of the selected compliance profile (null if none)"
+  value       = var.profile
+}
+
+output "settings" {
+  description = "Requirements of the selected profile: tag, minimum_retention_days, default_retention_days, lock_mode, require_compliance_mode, require_customer_managed_key, require_access_logging"
+  value       = local.settings
+}
+
+output "profiles" {
+  description = "Requirements of every supported profile, keyed by profile name"
+  value       = local.profiles
+}
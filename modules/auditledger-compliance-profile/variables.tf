@@ -0,0 +1,12 @@
+# AuditLedger Compliance Profile Module Variables
+
+variable "profile" {
+  type        = string
+  description = "Compliance profile: soc2, hipaa, pci_dss, sox, gdpr_minimal or finra_17a4 (null for no profile)"
+  default     = null
+
+  validation {
+    condition     = var.profile == null ? true : contains(["soc2", "hipaa", "pci_dss", "sox", "gdpr_minimal", "finra_17a4"], var.profile)
+    error_message = "Compliance profile must be one of: soc2, hipaa, pci_dss, sox, gdpr_minimal, finra_17a4"
+  }
+}
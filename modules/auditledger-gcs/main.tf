@@ -0,0 +1,196 @@
+# AuditLedger GCS Storage Module
+# This module creates a Google Cloud Storage bucket with appropriate security settings for audit log storage
+
+terraform {
+  required_version = ">= 1.5.0"
+
+  required_providers {
+    google = {
+      source  = "hashicorp/google"
+      version = ">= 5.22"
+    }
+  }
+}
+
+# Compliance profile - resolves retention, lock, encryption, logging and label requirements
+module "compliance_profile" {
+  source = "../auditledger-compliance-profile"
+
+  profile = var.compliance_profile
+}
+
+locals {
+  compliance = module.compliance_profile.settings
+
+  # Explicit inputs win; otherwise the profile (or historical) default applies
+  retention_days        = coalesce(var.retention_days, local.compliance.default_retention_days)
+  lock_retention_policy = coalesce(var.lock_retention_policy, local.compliance.require_compliance_mode)
+
+  # Only claim a framework in labels when a profile actually enforces it
+  compliance_labels = var.compliance_profile != null ? {
+    compliance         = lower(local.compliance.tag)
+    compliance-profile = var.compliance_profile
+  } : {}
+
+  # Storage class transitions by object age in days (same tiers as the S3 module)
+  lifecycle_transitions = {
+    NEARLINE = 90
+    COLDLINE = 180
+    ARCHIVE  = 365
+  }
+}
+
+# Cloud Storage service agent - needs access to the CMEK key before the bucket can use it
+data "google_storage_project_service_account" "gcs_account" {
+  count   = var.kms_key_name != null && var.grant_kms_access ? 1 : 0
+  project = var.project_id
+}
+
+resource "google_kms_crypto_key_iam_member" "gcs_encrypter_decrypter" {
+  count         = var.kms_key_name != null && var.grant_kms_access ? 1 : 0
+  crypto_key_id = var.kms_key_name
+  role          = "roles/cloudkms.cryptoKeyEncrypterDecrypter"
+  member        = "serviceAccount:${data.google_storage_project_service_account.gcs_account[0].email_address}"
+}
+
+# GCS Bucket for Audit Logs with mandatory Bucket Lock retention policy
+# A LOCKED retention policy can never be removed or shortened - this is IRREVERSIBLE
+# tfsec:ignore:google-storage-bucket-encryption-customer-key - CMEK is optional, configured via kms_key_name variable
+resource "google_storage_bucket" "audit_logs" {
+  name          = var.bucket_name
+  project       = var.project_id
+  location      = var.location
+  storage_class = "STANDARD"
+
+  # Never allow Terraform to empty the bucket
+  force_destroy = false
+
+  # Security settings - IAM only, no ACLs, never public
+  uniform_bucket_level_access = true
+  public_access_prevention    = "enforced"
+
+  # Bucket Lock retention policy enforces immutability
+  # Objects cannot be deleted or overwritten until they are retention_days old
+  retention_policy {
+    retention_period = local.retention_days * 86400
+    is_locked        = local.lock_retention_policy
+  }
+
+  # Object Versioning cannot be enabled on a bucket with a retention policy -
+  # the retention policy already prevents overwrites and deletes.
+  # Soft delete keeps a recoverable copy of anything removed after retention expires.
+  soft_delete_policy {
+    retention_duration_seconds = var.soft_delete_days * 86400
+  }
+
+  dynamic "encryption" {
+    for_each = var.kms_key_name != null ? [var.kms_key_name] : []
+
+    content {
+      default_kms_key_name = encryption.value
+    }
+  }
+
+  # Autoclass moves objects between classes based on access patterns
+  dynamic "autoclass" {
+    for_each = var.enable_autoclass ? [1] : []
+
+    content {
+      enabled                = true
+      terminal_storage_class = "ARCHIVE"
+    }
+  }
+
+  # Lifecycle tiering for cost optimization (mutually exclusive with Autoclass)
+  dynamic "lifecycle_rule" {
+    for_each = { for class, age in local.lifecycle_transitions : class => age if var.enable_lifecycle_rules }
+
+    content {
+      condition {
+        age = lifecycle_rule.value
+      }
+
+      action {
+        type          = "SetStorageClass"
+        storage_class = lifecycle_rule.key
+      }
+    }
+  }
+
+  dynamic "logging" {
+    for_each = var.access_log_bucket != null ? [var.access_log_bucket] : []
+
+    content {
+      log_bucket        = logging.value
+      log_object_prefix = "audit-logs-access/"
+    }
+  }
+
+  labels = merge(
+    var.labels,
+    {
+      name       = var.bucket_name
+      purpose    = "auditledger-immutable-audit-logs"
+      immutable  = "true"
+      managed-by = "terraform"
+    },
+    local.compliance_labels
+  )
+
+  depends_on = [google_kms_crypto_key_iam_member.gcs_encrypter_decrypter]
+
+  lifecycle {
+    precondition {
+      condition     = !(var.enable_autoclass && var.enable_lifecycle_rules)
+      error_message = "enable_autoclass and enable_lifecycle_rules cannot both be true - Autoclass manages storage classes itself"
+    }
+
+    # Explicit overrides must still satisfy the compliance profile
+    precondition {
+      condition     = local.retention_days >= local.compliance.minimum_retention_days
+      error_message = "retention_days (${local.retention_days}) is below the ${local.compliance.minimum_retention_days}-day minimum of the ${coalesce(var.compliance_profile, "default")} compliance profile"
+    }
+
+    precondition {
+      condition     = !local.compliance.require_compliance_mode || local.lock_retention_policy
+      error_message = "The ${coalesce(var.compliance_profile, "default")} compliance profile requires a locked retention policy - set lock_retention_policy = true"
+    }
+
+    precondition {
+      condition     = !local.compliance.require_customer_managed_key || var.kms_key_name != null
+      error_message = "The ${coalesce(var.compliance_profile, "default")} compliance profile requires a customer-managed encryption key - set kms_key_name"
+    }
+
+    precondition {
+      condition     = !local.compliance.require_access_logging || var.access_log_bucket != null
+      error_message = "The ${coalesce(var.compliance_profile, "default")} compliance profile requires access logging - set access_log_bucket"
+    }
+  }
+}
+
+# Writer access for AuditLedger service accounts
+# objectCreator cannot delete or overwrite objects; objectViewer allows reads and listing
+resource "google_storage_bucket_iam_member" "auditledger_writer" {
+  for_each = toset(var.writer_service_accounts)
+
+  bucket = google_storage_bucket.audit_logs.name
+  role   = "roles/storage.objectCreator"
+  member = "serviceAccount:${each.value}"
+}
+
+resource "google_storage_bucket_iam_member" "auditledger_reader" {
+  for_each = toset(var.writer_service_accounts)
+
+  bucket = google_storage_bucket.audit_logs.name
+  role   = "roles/storage.objectViewer"
+  member = "serviceAccount:${each.value}"
+}
+
+# Bucket administrators (extremely privileged - can lock the retention policy)
+resource "google_storage_bucket_iam_member" "admin" {
+  for_each = toset(var.admin_members)
+
+  bucket = google_storage_bucket.audit_logs.name
+  role   = "roles/storage.admin"
+  member = each.value
+}
@@ -0,0 +1,54 @@
+# AuditLedger GCS Immutable Storage Module Outputs
+
+output "bucket_name" {
+  description = "Name of the GCS bucket"
+  value       = google_storage_bucket.audit_logs.name
+}
+
+output "bucket_url" {
+  description = "gs:// URL of the GCS bucket"
+  value       = google_storage_bucket.audit_logs.url
+}
+
+output "bucket_self_link" {
+  description = "Self link of the GCS bucket"
+  value       = google_storage_bucket.audit_logs.self_link
+}
+
+output "retention_policy" {
+  description = "Bucket Lock retention policy for verification"
+  value = {
+    enabled        = true
+    locked         = local.lock_retention_policy
+    retention_days = local.retention_days
+  }
+}
+
+output "immutability_configuration" {
+  description = "Immutability configuration for verification"
+  value = {
+    retention_policy_locked     = local.lock_retention_policy
+    retention_days              = local.retention_days
+    soft_delete_days            = var.soft_delete_days
+    uniform_bucket_level_access = true
+    public_access_prevention    = "enforced"
+    cmek_enabled                = var.kms_key_name != null
+  }
+}
+
+output "immutability_verified" {
+  description = "Whether the bucket's retention policy is locked (Bucket Lock), read from the bucket"
+  value       = google_storage_bucket.audit_logs.retention_policy[0].is_locked
+}
+
+output "compliance_profile" {
+  description = "Compliance profile applied to the bucket and its requirements (profile is null if none)"
+  value = {
+    profile                      = var.compliance_profile
+    tag                          = local.compliance.tag
+    minimum_retention_days       = local.compliance.minimum_retention_days
+    require_compliance_mode      = local.compliance.require_compliance_mode
+    require_customer_managed_key = local.compliance.require_customer_managed_key
+    require_access_logging       = local.compliance.require_access_logging
+  }
+}
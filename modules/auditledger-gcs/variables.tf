@@ -0,0 +1,118 @@
+# AuditLedger GCS Immutable Storage Module Variables
+
+variable "bucket_name" {
+  type        = string
+  description = "Name of the GCS bucket for audit logs (must be globally unique)"
+
+  validation {
+    condition     = can(regex("^[a-z0-9][a-z0-9_-]{1,61}[a-z0-9]$", var.bucket_name)) && !startswith(var.bucket_name, "goog")
+    error_message = "Bucket name must be between 3-63 characters, lowercase, contain only letters, numbers, hyphens and underscores, and must not start with \"goog\""
+  }
+}
+
+variable "project_id" {
+  type        = string
+  description = "GCP project ID that owns the bucket"
+}
+
+variable "location" {
+  type        = string
+  description = "GCS location (region, dual-region or multi-region such as US or EU)"
+  default     = "US"
+}
+
+variable "compliance_profile" {
+  type        = string
+  description = "Compliance profile that sets minimum retention, retention lock, encryption, logging and label requirements: soc2, hipaa, pci_dss, sox, gdpr_minimal or finra_17a4"
+  default     = null
+
+  validation {
+    condition     = var.compliance_profile == null ? true : contains(["soc2", "hipaa", "pci_dss", "sox", "gdpr_minimal", "finra_17a4"], var.compliance_profile)
+    error_message = "Compliance profile must be one of: soc2, hipaa, pci_dss, sox, gdpr_minimal, finra_17a4"
+  }
+}
+
+variable "retention_days" {
+  type        = number
+  description = "Number of days to retain audit logs (minimum 365 for compliance). Defaults to the compliance profile's retention, or 2555 days (7 years) without a profile"
+  default     = null
+
+  validation {
+    condition     = coalesce(var.retention_days, 365) >= 365
+    error_message = "Retention period must be at least 365 days for compliance. Recommended: 2555 days (7 years) for SOC 2."
+  }
+}
+
+variable "lock_retention_policy" {
+  type        = bool
+  description = "Lock the retention policy (Bucket Lock). A locked policy cannot be removed or shortened - IRREVERSIBLE. Defaults to the compliance profile's requirement, or false without a profile"
+  default     = null
+}
+
+variable "soft_delete_days" {
+  type        = number
+  description = "Days that deleted objects remain recoverable after their retention period has expired (7-90)"
+  default     = 90
+
+  validation {
+    condition     = var.soft_delete_days >= 7 && var.soft_delete_days <= 90
+    error_message = "Soft delete retention must be between 7 and 90 days"
+  }
+}
+
+variable "writer_service_accounts" {
+  type        = list(string)
+  description = "Emails of service accounts that AuditLedger uses to write audit logs"
+
+  validation {
+    condition     = length(var.writer_service_accounts) > 0
+    error_message = "At least one AuditLedger service account must be provided"
+  }
+
+  validation {
+    condition     = alltrue([for sa in var.writer_service_accounts : can(regex("^[^:@]+@[^:@]+$", sa))])
+    error_message = "Writer service accounts must be plain emails without a \"serviceAccount:\" prefix"
+  }
+}
+
+variable "admin_members" {
+  type        = list(string)
+  description = "IAM members (e.g. group:audit-admins@example.com) granted storage.admin on the bucket (extremely privileged)"
+  default     = []
+}
+
+variable "kms_key_name" {
+  type        = string
+  description = "Cloud KMS key resource name for CMEK encryption (optional, uses Google-managed keys if not provided)"
+  default     = null
+}
+
+variable "grant_kms_access" {
+  type        = bool
+  description = "Grant the Cloud Storage service agent encrypt/decrypt on kms_key_name"
+  default     = true
+}
+
+variable "enable_autoclass" {
+  type        = bool
+  description = "Enable Autoclass to move objects between storage classes based on access (mutually exclusive with lifecycle rules)"
+  default     = false
+}
+
+variable "enable_lifecycle_rules" {
+  type        = bool
+  description = "Enable lifecycle rules for cost optimization (transitions to Nearline, Coldline and Archive)"
+  default     = true
+}
+
+variable "access_log_bucket" {
+  type        = string
+  description = "GCS bucket for usage and storage logs (optional but recommended for compliance)"
+  default     = null
+}
+
+variable "labels" {
+  type        = map(string)
+  description = "Additional labels for the GCS bucket (lowercase keys and values)"
+  default     = {}
+}
@@ -0,0 +1,134 @@
+# AuditLedger MinIO Storage Module
+# This module creates a MinIO bucket with appropriate security settings for on-premises audit log storage
+
+terraform {
+  required_version = ">= 1.5.0"
+
+  required_providers {
+    minio = {
+      source  = "aminueza/minio"
+      version = ">= 3.0"
+    }
+  }
+}
+
+# MinIO Bucket for Audit Logs with mandatory Object Lock
+# Object Lock MUST be enabled at bucket creation - this is IRREVERSIBLE
+resource "minio_s3_bucket" "audit_logs" {
+  bucket = var.bucket_name
+  acl    = "private"
+
+  # Object Lock enforcement for immutability - cannot be disabled after creation
+  object_locking = true
+
+  # Never allow Terraform to empty the bucket
+  force_destroy = false
+}
+
+# Versioning is REQUIRED for Object Lock
+# MinIO enables it with object_locking, declared explicitly so drift is detected
+resource "minio_s3_bucket_versioning" "audit_logs" {
+  bucket = minio_s3_bucket.audit_logs.bucket
+
+  versioning_configuration {
+    status = "Enabled"
+  }
+}
+
+# Default Object Lock retention - enforces immutability
+# COMPLIANCE mode: No one (not even the MinIO root user) can delete objects during retention
+# GOVERNANCE mode: Users with s3:BypassGovernanceRetention can override retention
+resource "minio_s3_bucket_retention" "audit_logs" {
+  bucket          = minio_s3_bucket.audit_logs.bucket
+  mode            = var.object_lock_mode
+  unit            = "DAYS"
+  validity_period = var.retention_days
+
+  depends_on = [minio_s3_bucket_versioning.audit_logs]
+}
+
+# Server-Side Encryption via MinIO KES (optional)
+resource "minio_s3_bucket_server_side_encryption" "audit_logs" {
+  count = var.kms_key_id != null ? 1 : 0
+
+  bucket          = minio_s3_bucket.audit_logs.bucket
+  encryption_type = "aws:kms"
+  kms_key_id      = var.kms_key_id
+}
+
+# Bucket Policy - Enforce immutability
+# Note: the MinIO root user is not subject to bucket policies; Object Lock still protects locked versions
+resource "minio_s3_bucket_policy" "audit_logs" {
+  bucket = minio_s3_bucket.audit_logs.bucket
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Sid       = "DenyDeleteObject"
+        Effect    = "Deny"
+        Principal = { AWS = ["*"] }
+        Action = [
+          "s3:DeleteObject",
+          "s3:DeleteObjectVersion"
+        ]
+        Resource = ["arn:aws:s3:::${minio_s3_bucket.audit_logs.bucket}/*"]
+      },
+      {
+        Sid       = "DenyDisableObjectLock"
+        Effect    = "Deny"
+        Principal = { AWS = ["*"] }
+        Action = [
+          "s3:PutBucketObjectLockConfiguration",
+          "s3:BypassGovernanceRetention"
+        ]
+        Resource = [
+          "arn:aws:s3:::${minio_s3_bucket.audit_logs.bucket}",
+          "arn:aws:s3:::${minio_s3_bucket.audit_logs.bucket}/*"
+        ]
+      }
+    ]
+  })
+}
+
+# Writer user for the AuditLedger application (optional - can attach the policy to existing users)
+resource "minio_iam_user" "auditledger_writer" {
+  count = var.create_writer_user ? 1 : 0
+
+  name          = coalesce(var.writer_user_name, "${var.bucket_name}-writer")
+  force_destroy = false
+}
+
+# Policy for applications to access the MinIO bucket
+# Equivalent to aws_iam_policy.s3_access in the S3 module - no delete permissions
+resource "minio_iam_policy" "s3_access" {
+  name = "${var.bucket_name}-access-policy"
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = [
+      {
+        Sid    = "S3BucketAccess"
+        Effect = "Allow"
+        Action = [
+          "s3:PutObject",
+          "s3:GetObject",
+          "s3:GetObjectVersion",
+          "s3:ListBucket",
+          "s3:ListBucketVersions"
+        ]
+        Resource = [
+          "arn:aws:s3:::${minio_s3_bucket.audit_logs.bucket}",
+          "arn:aws:s3:::${minio_s3_bucket.audit_logs.bucket}/*"
+        ]
+      }
+    ]
+  })
+}
+
+resource "minio_iam_user_policy_attachment" "auditledger_writer" {
+  count = var.create_writer_user ? 1 : 0
+
+  user_name   = minio_iam_user.auditledger_writer[0].id
+  policy_name = minio_iam_policy.s3_access.id
+}
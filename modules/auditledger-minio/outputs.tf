@@ -0,0 +1,51 @@
+# AuditLedger MinIO Immutable Storage Module Outputs
+
+output "bucket_id" {
+  description = "ID of the MinIO bucket"
+  value       = minio_s3_bucket.audit_logs.id
+}
+
+output "bucket_arn" {
+  description = "ARN of the MinIO bucket"
+  value       = minio_s3_bucket.audit_logs.arn
+}
+
+output "bucket_domain_name" {
+  description = "Domain name of the MinIO bucket"
+  value       = minio_s3_bucket.audit_logs.bucket_domain_name
+}
+
+output "object_lock_configuration" {
+  description = "Object Lock configuration for verification"
+  value = {
+    enabled        = true
+    mode           = var.object_lock_mode
+    retention_days = var.retention_days
+  }
+}
+
+output "immutability_verified" {
+  description = "Whether the bucket's default Object Lock retention is in COMPLIANCE mode, read from the bucket"
+  value       = minio_s3_bucket_retention.audit_logs.mode == "COMPLIANCE"
+}
+
+output "iam_policy_name" {
+  description = "Name of the MinIO policy for bucket access"
+  value       = minio_iam_policy.s3_access.name
+}
+
+output "writer_user_name" {
+  description = "Name of the AuditLedger writer user (null if not created)"
+  value       = var.create_writer_user ? minio_iam_user.auditledger_writer[0].name : null
+}
+
+output "writer_access_key" {
+  description = "Access key of the AuditLedger writer user (null if not created)"
+  value       = var.create_writer_user ? minio_iam_user.auditledger_writer[0].id : null
+}
+
+output "writer_secret_key" {
+  description = "Secret key of the AuditLedger writer user (null if not created)"
+  value       = var.create_writer_user ? minio_iam_user.auditledger_writer[0].secret : null
+  sensitive   = true
+}
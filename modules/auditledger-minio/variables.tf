@@ -0,0 +1,51 @@
+# AuditLedger MinIO Immutable Storage Module Variables
+
+variable "bucket_name" {
+  type        = string
+  description = "Name of the MinIO bucket for audit logs"
+
+  validation {
+    condition     = can(regex("^[a-z0-9][a-z0-9-]{1,61}[a-z0-9]$", var.bucket_name))
+    error_message = "Bucket name must be between 3-63 characters, lowercase, and contain only letters, numbers, and hyphens"
+  }
+}
+
+variable "retention_days" {
+  type        = number
+  description = "Number of days to retain audit logs (minimum 365 for compliance)"
+  default     = 2555 # 7 years for SOC 2
+
+  validation {
+    condition     = var.retention_days >= 365
+    error_message = "Retention period must be at least 365 days for compliance. Recommended: 2555 days (7 years) for SOC 2."
+  }
+}
+
+variable "object_lock_mode" {
+  type        = string
+  description = "Object Lock mode: COMPLIANCE (strict) or GOVERNANCE (can be overridden with special permissions)"
+  default     = "COMPLIANCE"
+
+  validation {
+    condition     = contains(["COMPLIANCE", "GOVERNANCE"], var.object_lock_mode)
+    error_message = "Object Lock mode must be either COMPLIANCE or GOVERNANCE"
+  }
+}
+
+variable "create_writer_user" {
+  type        = bool
+  description = "Create a MinIO user for AuditLedger with the bucket access policy attached"
+  default     = true
+}
+
+variable "writer_user_name" {
+  type        = string
+  description = "Name of the AuditLedger writer user (defaults to <bucket_name>-writer)"
+  default     = null
+}
+
+variable "kms_key_id" {
+  type        = string
+  description = "MinIO KES key name for server-side encryption (optional, requires KES to be configured on the server)"
+  default     = null
+}
@@ -12,6 +12,32 @@ terraform {
   }
 }
 
+# Compliance profile - resolves retention, lock mode, encryption, logging and tag requirements
+module "compliance_profile" {
+  source = "../auditledger-compliance-profile"
+
+  profile = var.compliance_profile
+}
+
+locals {
+  compliance = module.compliance_profile.settings
+
+  # Explicit inputs win; otherwise the profile (or historical) defaults apply
+  retention_days   = coalesce(var.retention_days, local.compliance.default_retention_days)
+  object_lock_mode = coalesce(var.object_lock_mode, local.compliance.lock_mode)
+
+  # Only claim a framework in tags when a profile actually enforces it
+  compliance_tags = var.compliance_profile != null ? {
+    Compliance        = local.compliance.tag
+    ComplianceProfile = var.compliance_profile
+  } : {}
+
+  # Digest manifests (tools/cmd/auditledger-manifest) live under their own prefix, which
+  # only the manifest writer roles may write to - and which is all they may write to
+  manifest_prefix  = "_manifests/"
+  manifest_enabled = length(var.manifest_writer_role_arns) > 0
+}
+
 # S3 Bucket for Audit Logs with mandatory Object Lock
 # Object Lock MUST be enabled at bucket creation - this is IRREVERSIBLE
 # tfsec:ignore:aws-s3-enable-bucket-logging - Bucket logging is optional, configured via logging_bucket variable
@@ -26,13 +52,36 @@ resource "aws_s3_bucket" "audit_logs" {
   tags = merge(
     var.tags,
     {
-      Name       = var.bucket_name
-      Purpose    = "AuditLedger Immutable Audit Logs"
-      Compliance = "SOC2-HIPAA-PCIDSS"
-      Immutable  = "true"
-      ManagedBy  = "Terraform"
-    }
+      Name      = var.bucket_name
+      Purpose   = "AuditLedger Immutable Audit Logs"
+      Immutable = "true"
+      ManagedBy = "Terraform"
+    },
+    local.compliance_tags
   )
+
+  # Explicit overrides must still satisfy the compliance profile
+  lifecycle {
+    precondition {
+      condition     = local.retention_days >= local.compliance.minimum_retention_days
+      error_message = "retention_days (${local.retention_days}) is below the ${local.compliance.minimum_retention_days}-day minimum of the ${coalesce(var.compliance_profile, "default")} compliance profile"
+    }
+
+    precondition {
+      condition     = !local.compliance.require_compliance_mode || local.object_lock_mode == "COMPLIANCE"
+      error_message = "The ${coalesce(var.compliance_profile, "default")} compliance profile requires object_lock_mode = \"COMPLIANCE\""
+    }
+
+    precondition {
+      condition     = !local.compliance.require_customer_managed_key || var.kms_key_id != null
+      error_message = "The ${coalesce(var.compliance_profile, "default")} compliance profile requires a customer-managed key - set kms_key_id"
+    }
+
+    precondition {
+      condition     = !local.compliance.require_access_logging || var.access_log_bucket != null
+      error_message = "The ${coalesce(var.compliance_profile, "default")} compliance profile requires access logging - set access_log_bucket"
+    }
+  }
 }
 
 # Block Public Access
@@ -58,8 +107,8 @@ resource "aws_s3_bucket_object_lock_configuration" "audit_logs" {
 
   rule {
     default_retention {
-      mode = var.object_lock_mode
-      days = var.retention_days
+      mode = local.object_lock_mode
+      days = local.retention_days
     }
   }
 }
@@ -124,7 +173,7 @@ resource "aws_s3_bucket_lifecycle_configuration" "audit_logs" {
     filter {}
 
     noncurrent_version_expiration {
-      noncurrent_days = var.retention_days
+      noncurrent_days = local.retention_days
     }
   }
 }
@@ -135,7 +184,7 @@ resource "aws_s3_bucket_policy" "audit_logs" {
 
   policy = jsonencode({
     Version = "2012-10-17"
-    Statement = [
+    Statement = concat([
       {
         Sid       = "DenyDeleteObject"
         Effect    = "Deny"
@@ -191,7 +240,7 @@ resource "aws_s3_bucket_policy" "audit_logs" {
         Resource = "${aws_s3_bucket.audit_logs.arn}/*"
         Condition = {
           StringEquals = {
-            "s3:x-amz-object-lock-mode" : var.object_lock_mode
+            "s3:x-amz-object-lock-mode" : local.object_lock_mode
           }
         }
       },
@@ -239,10 +288,88 @@ resource "aws_s3_bucket_policy" "audit_logs" {
           }
         }
       }
-    ]
+    ], local.manifest_bucket_policy_statements)
   })
 }
 
+# For expressions rather than conditionals: the statements have different attributes, so
+# `condition ? [...] : []` cannot unify their types
+locals {
+  manifest_bucket_policy_statements = [for statement in [
+    {
+      Sid    = "AllowManifestWrite"
+      Effect = "Allow"
+      Principal = {
+        AWS = var.manifest_writer_role_arns
+      }
+      Action = [
+        "s3:PutObject",
+        "s3:PutObjectRetention"
+      ]
+      Resource = "${aws_s3_bucket.audit_logs.arn}/${local.manifest_prefix}*"
+      Condition = {
+        StringEquals = {
+          "s3:x-amz-object-lock-mode" : local.object_lock_mode
+        }
+      }
+    },
+    {
+      # Manifests digest every object version, so the writer reads the whole bucket
+      Sid    = "AllowManifestRead"
+      Effect = "Allow"
+      Principal = {
+        AWS = var.manifest_writer_role_arns
+      }
+      Action = [
+        "s3:GetObject",
+        "s3:GetObjectVersion",
+        "s3:ListBucket",
+        "s3:ListBucketVersions"
+      ]
+      Resource = [
+        aws_s3_bucket.audit_logs.arn,
+        "${aws_s3_bucket.audit_logs.arn}/*"
+      ]
+    },
+    {
+      # Stops AuditLedger writers (and anyone else) from forging manifests
+      Sid       = "DenyManifestWriteByOthers"
+      Effect    = "Deny"
+      Principal = "*"
+      Action    = "s3:PutObject"
+      Resource  = "${aws_s3_bucket.audit_logs.arn}/${local.manifest_prefix}*"
+      Condition = {
+        StringNotEquals = {
+          "aws:PrincipalArn" : var.manifest_writer_role_arns
+        }
+      }
+    },
+    {
+      # Stops a compromised manifest writer from inserting audit records
+      Sid         = "DenyManifestWriterOutsidePrefix"
+      Effect      = "Deny"
+      Principal   = "*"
+      Action      = "s3:PutObject"
+      NotResource = "${aws_s3_bucket.audit_logs.arn}/${local.manifest_prefix}*"
+      Condition = {
+        StringEquals = {
+          "aws:PrincipalArn" : var.manifest_writer_role_arns
+        }
+      }
+    }
+  ] : statement if local.manifest_enabled]
+
+  manifest_signing_statements = [for key_arn in compact([var.manifest_signing_kms_key_arn]) : {
+    Sid    = "SignManifests"
+    Effect = "Allow"
+    Action = [
+      "kms:Sign",
+      "kms:GetPublicKey"
+    ]
+    Resource = key_arn
+  }]
+}
+
 # Access Logging (optional)
 resource "aws_s3_bucket_logging" "audit_logs" {
   count  = var.access_log_bucket != null ? 1 : 0
@@ -266,10 +393,28 @@ resource "aws_s3_bucket_replication_configuration" "audit_logs" {
     id     = "replicate-all"
     status = "Enabled"
 
+    # SSE-KMS objects are only replicated when selected explicitly and given a replica key;
+    # the replication role needs kms:Decrypt on kms_key_id and kms:Encrypt on the replica key
+    dynamic "source_selection_criteria" {
+      for_each = var.replication_kms_key_id != null ? [1] : []
+      content {
+        sse_kms_encrypted_objects {
+          status = "Enabled"
+        }
+      }
+    }
+
     destination {
       bucket        = var.replication_bucket_arn
       storage_class = "STANDARD_IA"
 
+      dynamic "encryption_configuration" {
+        for_each = var.replication_kms_key_id != null ? [1] : []
+        content {
+          replica_kms_key_id = var.replication_kms_key_id
+        }
+      }
+
       replication_time {
         status = "Enabled"
         time {
@@ -287,6 +432,150 @@ resource "aws_s3_bucket_replication_configuration" "audit_logs" {
   }
 }
 
+# Inventory and Storage Lens reporting (optional)
+# Daily inventory reports let auditors prove every object version is locked and encrypted
+# without ad-hoc ListObjectVersions scripts
+locals {
+  inventory_destination_bucket = var.inventory_destination_bucket_arn != null ? element(split(":::", var.inventory_destination_bucket_arn), 1) : null
+
+  inventory_optional_fields = [
+    "Size",
+    "LastModifiedDate",
+    "StorageClass",
+    "EncryptionStatus",
+    "BucketKeyStatus",
+    "ReplicationStatus",
+    "ObjectLockMode",
+    "ObjectLockRetainUntilDate",
+    "ObjectLockLegalHoldStatus"
+  ]
+}
+
+data "aws_caller_identity" "current" {
+  count = var.enable_inventory || var.enable_storage_lens ? 1 : 0
+}
+
+resource "aws_s3_bucket_inventory" "audit_logs" {
+  count = var.enable_inventory ? 1 : 0
+
+  bucket = aws_s3_bucket.audit_logs.id
+  name   = "auditledger-immutability-inventory"
+
+  # Every version must be reported - noncurrent versions are still under retention
+  included_object_versions = "All"
+  optional_fields          = local.inventory_optional_fields
+
+  schedule {
+    frequency = var.inventory_frequency
+  }
+
+  destination {
+    bucket {
+      format     = var.inventory_format
+      bucket_arn = var.inventory_destination_bucket_arn
+      prefix     = var.inventory_destination_prefix
+      account_id = data.aws_caller_identity.current[0].account_id
+
+      encryption {
+        sse_s3 {}
+      }
+    }
+  }
+
+  lifecycle {
+    precondition {
+      condition     = var.inventory_destination_bucket_arn != null
+      error_message = "inventory_destination_bucket_arn is required when enable_inventory is true"
+    }
+
+    precondition {
+      condition     = local.inventory_destination_bucket != var.bucket_name
+      error_message = "Inventory reports cannot be delivered to the audit bucket itself - its bucket policy denies unattributed writes"
+    }
+  }
+}
+
+# Bucket policy allowing S3 to deliver inventory reports to the destination bucket
+# Only applied when manage_inventory_destination_policy is true - otherwise merge
+# inventory_destination_policy_json into the destination bucket's existing policy
+data "aws_iam_policy_document" "inventory_destination" {
+  count = var.enable_inventory ? 1 : 0
+
+  statement {
+    sid       = "AllowAuditLedgerInventoryDelivery"
+    effect    = "Allow"
+    actions   = ["s3:PutObject"]
+    resources = ["${var.inventory_destination_bucket_arn}/${var.inventory_destination_prefix}/*"]
+
+    principals {
+      type        = "Service"
+      identifiers = ["s3.amazonaws.com"]
+    }
+
+    condition {
+      test     = "ArnLike"
+      variable = "aws:SourceArn"
+      values   = [aws_s3_bucket.audit_logs.arn]
+    }
+
+    condition {
+      test     = "StringEquals"
+      variable = "aws:SourceAccount"
+      values   = [data.aws_caller_identity.current[0].account_id]
+    }
+
+    condition {
+      test     = "StringEquals"
+      variable = "s3:x-amz-acl"
+      values   = ["bucket-owner-full-control"]
+    }
+  }
+
+  lifecycle {
+    precondition {
+      condition     = var.inventory_destination_bucket_arn != null
+      error_message = "inventory_destination_bucket_arn is required when enable_inventory is true"
+    }
+  }
+}
+
+resource "aws_s3_bucket_policy" "inventory_destination" {
+  count = var.enable_inventory && var.manage_inventory_destination_policy ? 1 : 0
+
+  bucket = local.inventory_destination_bucket
+  policy = data.aws_iam_policy_document.inventory_destination[0].json
+}
+
+# Storage Lens dashboard scoped to the audit bucket (optional)
+resource "aws_s3control_storage_lens_configuration" "audit_logs" {
+  count = var.enable_storage_lens ? 1 : 0
+
+  account_id = data.aws_caller_identity.current[0].account_id
+  config_id  = "${substr(var.bucket_name, 0, 58)}-lens" # Storage Lens IDs are limited to 64 characters
+
+  storage_lens_configuration {
+    enabled = true
+
+    account_level {
+      activity_metrics {
+        enabled = true
+      }
+
+      bucket_level {
+        activity_metrics {
+          enabled = true
+        }
+      }
+    }
+
+    include {
+      buckets = [aws_s3_bucket.audit_logs.arn]
+    }
+  }
+
+  tags = var.tags
+}
+
 # IAM Policy for applications to access S3 bucket
 # Applications can attach this policy to their IAM roles
 # tfsec:ignore:aws-iam-no-policy-wildcards - Wildcard required for audit log writes to any path in bucket
@@ -317,3 +606,88 @@ resource "aws_iam_policy" "s3_access" {
 
   tags = var.tags
 }
+
+# IAM Policy for the digest manifest writer role - reads every object version, writes only manifests
+# tfsec:ignore:aws-iam-no-policy-wildcards - Wildcard required to digest every object in the bucket
+resource "aws_iam_policy" "manifest_writer" {
+  count = local.manifest_enabled ? 1 : 0
+
+  name        = "${var.bucket_name}-manifest-writer-policy"
+  description = "Policy for writing digest manifests to ${var.bucket_name}/${local.manifest_prefix}"
+
+  policy = jsonencode({
+    Version = "2012-10-17"
+    Statement = concat([
+      {
+        Sid    = "ReadAuditLogs"
+        Effect = "Allow"
+        Action = [
+          "s3:GetObject",
+          "s3:GetObjectVersion",
+          "s3:ListBucket",
+          "s3:ListBucketVersions"
+        ]
+        Resource = [
+          aws_s3_bucket.audit_logs.arn,
+          "${aws_s3_bucket.audit_logs.arn}/*"
+        ]
+      },
+      {
+        Sid    = "WriteManifests"
+        Effect = "Allow"
+        Action = [
+          "s3:PutObject",
+          "s3:PutObjectRetention"
+        ]
+        Resource = "${aws_s3_bucket.audit_logs.arn}/${local.manifest_prefix}*"
+      }
+    ], local.manifest_signing_statements)
+  })
+
+  tags = var.tags
+}
+
+# Application configuration - what AuditLedger needs to write through the bucket policy:
+# AllowAuditLedgerWrite only matches requests with the lock mode header and
+# DenyUnencryptedObjectUploads rejects requests without the encryption header. S3 also
+# requires x-amz-object-lock-retain-until-date with the mode header; the application sets
+# it to the time of the write plus RetentionDays
+locals {
+  # Without the key ID header, aws:kms uploads are encrypted with the AWS managed aws/s3 key
+  app_required_headers = concat([
+    { Name = "x-amz-object-lock-mode", Value = local.object_lock_mode },
+    { Name = "x-amz-server-side-encryption", Value = var.kms_key_id != null ? "aws:kms" : "AES256" },
+  ], var.kms_key_id != null ? [{ Name = "x-amz-server-side-encryption-aws-kms-key-id", Value = var.kms_key_id }] : [])
+
+  app_storage_settings = {
+    Provider = "AwsS3"
+    AwsS3 = {
+      BucketName           = aws_s3_bucket.audit_logs.id
+      Region               = aws_s3_bucket.audit_logs.region
+      Prefix               = var.app_key_prefix
+      ObjectLockMode       = local.object_lock_mode
+      RetentionDays        = local.retention_days
+      ServerSideEncryption = var.kms_key_id != null ? "aws:kms" : "AES256"
+      KmsKeyId             = var.kms_key_id
+      RequiredHeaders      = local.app_required_headers
+      ReservedPrefixes     = [local.manifest_prefix]
+    }
+  }
+
+  # .NET configuration keys: sections separated by __, list items by their index
+  app_configuration_env = merge(
+    {
+      AuditLedger__Storage__Provider                    = "AwsS3"
+      AuditLedger__Storage__AwsS3__BucketName           = aws_s3_bucket.audit_logs.id
+      AuditLedger__Storage__AwsS3__Region               = aws_s3_bucket.audit_logs.region
+      AuditLedger__Storage__AwsS3__Prefix               = var.app_key_prefix
+      AuditLedger__Storage__AwsS3__ObjectLockMode       = local.object_lock_mode
+      AuditLedger__Storage__AwsS3__RetentionDays        = tostring(local.retention_days)
+      AuditLedger__Storage__AwsS3__ServerSideEncryption = var.kms_key_id != null ? "aws:kms" : "AES256"
+      AuditLedger__Storage__AwsS3__ReservedPrefixes__0  = local.manifest_prefix
+    },
+    var.kms_key_id != null ? { AuditLedger__Storage__AwsS3__KmsKeyId = var.kms_key_id } : {},
+    { for i, header in local.app_required_headers : "AuditLedger__Storage__AwsS3__RequiredHeaders__${i}__Name" => header.Name },
+    { for i, header in local.app_required_headers : "AuditLedger__Storage__AwsS3__RequiredHeaders__${i}__Value" => header.Value }
+  )
+}
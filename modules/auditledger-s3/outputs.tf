@@ -24,14 +24,52 @@ output "object_lock_configuration" {
   description = "Object Lock configuration for verification"
   value = {
     enabled        = true
-    mode           = var.object_lock_mode
-    retention_days = var.retention_days
+    mode           = local.object_lock_mode
+    retention_days = local.retention_days
+  }
+}
+
+output "encryption_configuration" {
+  description = "Default encryption configuration for verification"
+  value = {
+    sse_algorithm = var.kms_key_id != null ? "aws:kms" : "AES256"
+    kms_key_id    = var.kms_key_id
+  }
+}
+
+output "access_logging_configuration" {
+  description = "Access logging configuration for verification"
+  value = {
+    enabled       = var.access_log_bucket != null
+    target_bucket = var.access_log_bucket
+    target_prefix = var.access_log_bucket != null ? "audit-logs-access/" : null
+  }
+}
+
+output "replication_configuration" {
+  description = "Replication configuration for verification"
+  value = {
+    enabled                = var.replication_bucket_arn != null
+    destination_bucket_arn = var.replication_bucket_arn
+    replica_kms_key_id     = var.replication_kms_key_id
+  }
+}
+
+output "compliance_profile" {
+  description = "Compliance profile applied to the bucket and its requirements (profile is null if none)"
+  value = {
+    profile                      = var.compliance_profile
+    tag                          = local.compliance.tag
+    minimum_retention_days       = local.compliance.minimum_retention_days
+    require_compliance_mode      = local.compliance.require_compliance_mode
+    require_customer_managed_key = local.compliance.require_customer_managed_key
+    require_access_logging       = local.compliance.require_access_logging
   }
 }
 
 output "immutability_verified" {
-  description = "Confirmation that immutability is enforced"
-  value       = true
+  description = "Whether the bucket's default Object Lock retention is in COMPLIANCE mode, read from the lock configuration"
+  value       = aws_s3_bucket_object_lock_configuration.audit_logs.rule[0].default_retention[0].mode == "COMPLIANCE"
 }
 
 output "iam_policy_arn" {
@@ -43,3 +81,45 @@ output "iam_policy_name" {
   description = "Name of the IAM policy for S3 bucket access"
   value       = aws_iam_policy.s3_access.name
 }
+
+output "manifest_configuration" {
+  description = "Digest manifest settings for auditledger-manifest (writer_policy_arn is null without manifest writer roles)"
+  value = {
+    prefix              = local.manifest_prefix
+    writer_role_arns    = var.manifest_writer_role_arns
+    writer_policy_arn   = local.manifest_enabled ? aws_iam_policy.manifest_writer[0].arn : null
+    signing_kms_key_arn = var.manifest_signing_kms_key_arn
+  }
+}
+
+output "app_configuration" {
+  description = "AuditLedger application storage settings with the request headers the bucket policy requires, as an object, appsettings JSON and YAML, and .NET environment variables"
+  value = {
+    settings = local.app_storage_settings
+    json     = jsonencode({ AuditLedger = { Storage = local.app_storage_settings } })
+    yaml     = yamlencode({ AuditLedger = { Storage = local.app_storage_settings } })
+    env      = local.app_configuration_env
+  }
+}
+
+output "inventory_configuration" {
+  description = "S3 Inventory configuration for verification (null if disabled)"
+  value = var.enable_inventory ? {
+    name                   = aws_s3_bucket_inventory.audit_logs[0].name
+    destination_bucket_arn = var.inventory_destination_bucket_arn
+    destination_prefix     = var.inventory_destination_prefix
+    format                 = var.inventory_format
+    frequency              = var.inventory_frequency
+    optional_fields        = local.inventory_optional_fields
+  } : null
+}
+
+output "inventory_destination_policy_json" {
+  description = "Bucket policy JSON granting S3 inventory delivery to the destination bucket (null if inventory is disabled)"
+  value       = var.enable_inventory ? data.aws_iam_policy_document.inventory_destination[0].json : null
+}
+
+output "storage_lens_configuration_id" {
+  description = "ID of the Storage Lens configuration (null if disabled)"
+  value       = var.enable_storage_lens ? aws_s3control_storage_lens_configuration.audit_logs[0].config_id : null
+}
@@ -10,24 +10,35 @@ variable "bucket_name" {
   }
 }
 
+variable "compliance_profile" {
+  type        = string
+  description = "Compliance profile that sets minimum retention, lock mode, encryption, logging and tag requirements: soc2, hipaa, pci_dss, sox, gdpr_minimal or finra_17a4"
+  default     = null
+
+  validation {
+    condition     = var.compliance_profile == null ? true : contains(["soc2", "hipaa", "pci_dss", "sox", "gdpr_minimal", "finra_17a4"], var.compliance_profile)
+    error_message = "Compliance profile must be one of: soc2, hipaa, pci_dss, sox, gdpr_minimal, finra_17a4"
+  }
+}
+
 variable "retention_days" {
   type        = number
-  description = "Number of days to retain audit logs (minimum 365 for compliance)"
-  default     = 2555 # 7 years for SOC 2
+  description = "Number of days to retain audit logs (minimum 365 for compliance). Defaults to the compliance profile's retention, or 2555 days (7 years) without a profile"
+  default     = null
 
   validation {
-    condition     = var.retention_days >= 365
+    condition     = coalesce(var.retention_days, 365) >= 365
     error_message = "Retention period must be at least 365 days for compliance. Recommended: 2555 days (7 years) for SOC 2."
   }
 }
 
 variable "object_lock_mode" {
   type        = string
-  description = "Object Lock mode: COMPLIANCE (strict) or GOVERNANCE (can be overridden with special permissions)"
-  default     = "COMPLIANCE"
+  description = "Object Lock mode: COMPLIANCE (strict) or GOVERNANCE (can be overridden with special permissions). Defaults to the compliance profile's mode, or COMPLIANCE without a profile"
+  default     = null
 
   validation {
-    condition     = contains(["COMPLIANCE", "GOVERNANCE"], var.object_lock_mode)
+    condition     = var.object_lock_mode == null ? true : contains(["COMPLIANCE", "GOVERNANCE"], var.object_lock_mode)
     error_message = "Object Lock mode must be either COMPLIANCE or GOVERNANCE"
   }
 }
@@ -84,6 +95,92 @@ variable "replication_role_arn" {
   default     = null
 }
 
+variable "replication_kms_key_id" {
+  type        = string
+  description = "ARN of the KMS key in the destination region that encrypts replicas. Required to replicate objects when kms_key_id is set - S3 skips SSE-KMS objects otherwise"
+  default     = null
+}
+
+variable "manifest_writer_role_arns" {
+  type        = list(string)
+  description = "ARNs of IAM roles that write digest manifests (auditledger-manifest). When set, only these roles can write under _manifests/ and they can write nowhere else"
+  default     = []
+}
+
+variable "manifest_signing_kms_key_arn" {
+  type        = string
+  description = "ARN of the asymmetric KMS key (ECC_NIST_P256, SIGN_VERIFY) that signs manifests. Grants kms:Sign in the manifest writer policy"
+  default     = null
+}
+
+variable "app_key_prefix" {
+  type        = string
+  description = "Key prefix the application writes audit logs under, for the app_configuration output (empty for the bucket root)"
+  default     = ""
+
+  validation {
+    condition     = var.app_key_prefix == "" || (endswith(var.app_key_prefix, "/") && !startswith(var.app_key_prefix, "/") && !startswith(var.app_key_prefix, "_manifests/"))
+    error_message = "app_key_prefix must be empty or end with /, must not start with / and must not be under _manifests/, which is reserved for digest manifests"
+  }
+}
+
+variable "enable_inventory" {
+  type        = bool
+  description = "Enable S3 Inventory reports listing Object Lock, encryption and replication status for every object version"
+  default     = false
+}
+
+variable "inventory_destination_bucket_arn" {
+  type        = string
+  description = "ARN of the bucket that receives inventory reports (required if enable_inventory is true)"
+  default     = null
+}
+
+variable "inventory_destination_prefix" {
+  type        = string
+  description = "Key prefix for inventory reports in the destination bucket"
+  default     = "auditledger-inventory"
+
+  validation {
+    condition     = can(regex("^[A-Za-z0-9!_.*'()-]+(/[A-Za-z0-9!_.*'()-]+)*$", var.inventory_destination_prefix))
+    error_message = "Inventory destination prefix must not be empty or start/end with a slash"
+  }
+}
+
+variable "inventory_format" {
+  type        = string
+  description = "Inventory report format: Parquet, ORC or CSV"
+  default     = "Parquet"
+
+  validation {
+    condition     = contains(["Parquet", "ORC", "CSV"], var.inventory_format)
+    error_message = "Inventory format must be one of: Parquet, ORC, CSV"
+  }
+}
+
+variable "inventory_frequency" {
+  type        = string
+  description = "How often inventory reports are generated: Daily or Weekly"
+  default     = "Daily"
+
+  validation {
+    condition     = contains(["Daily", "Weekly"], var.inventory_frequency)
+    error_message = "Inventory frequency must be Daily or Weekly"
+  }
+}
+
+variable "manage_inventory_destination_policy" {
+  type        = bool
+  description = "Attach the inventory delivery bucket policy to the destination bucket (replaces any existing policy on that bucket)"
+  default     = false
+}
+
+variable "enable_storage_lens" {
+  type        = bool
+  description = "Enable an S3 Storage Lens configuration scoped to the audit bucket"
+  default     = false
+}
+
 variable "tags" {
   type        = map(string)
   description = "Additional tags for the S3 bucket"
@@ -0,0 +1,135 @@
+# AuditLedger Multi-Cloud Storage Module
+# This module exposes one interface for immutable audit log storage and delegates to the
+# cloud-specific AuditLedger module selected by var.cloud
+
+terraform {
+  required_version = ">= 1.5.0"
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = ">= 5.0"
+    }
+    azurerm = {
+      source  = "hashicorp/azurerm"
+      version = ">= 3.0"
+    }
+    google = {
+      source  = "hashicorp/google"
+      version = ">= 5.22"
+    }
+  }
+}
+
+locals {
+  # Outputs of the selected module (null for the others)
+  aws   = one(module.aws)
+  azure = one(module.azure)
+  gcp   = one(module.gcp)
+
+  # GCS labels only allow lowercase letters, numbers, underscores and dashes
+  gcp_labels = {
+    for key, value in var.tags :
+    lower(replace(key, "/[^a-zA-Z0-9_-]/", "_")) => lower(replace(value, "/[^a-zA-Z0-9_-]/", "_"))
+  }
+}
+
+# AWS S3 with Object Lock
+module "aws" {
+  source = "../auditledger-s3"
+  count  = var.cloud == "aws" ? 1 : 0
+
+  bucket_name           = var.name
+  compliance_profile    = var.compliance_profile
+  retention_days        = var.retention_days
+  object_lock_mode      = var.lock_mode
+  auditledger_role_arns = var.writer_identities
+  kms_key_id            = var.encryption_key_id
+  access_log_bucket     = var.access_log_bucket
+  app_key_prefix        = var.app_key_prefix
+  tags                  = var.tags
+}
+
+# Azure Blob Storage
+module "azure" {
+  source = "../auditledger-azure-blob"
+  count  = var.cloud == "azure" ? 1 : 0
+
+  # The wrapper creates no replication destination
+  providers = {
+    azurerm         = azurerm
+    azurerm.replica = azurerm
+  }
+
+  storage_account_name                       = var.name
+  resource_group_name                        = var.azure_resource_group_name
+  create_resource_group                      = var.azure_create_resource_group
+  location                                   = var.azure_location
+  compliance_profile                         = var.compliance_profile
+  retention_days                             = var.retention_days
+  lock_immutability_policy                   = var.lock_mode == null ? null : var.lock_mode == "COMPLIANCE"
+  writer_principal_ids                       = var.writer_identities
+  enable_customer_managed_key                = var.encryption_key_id != null
+  key_vault_id                               = var.encryption_key_id
+  log_analytics_workspace_id                 = var.azure_log_analytics_workspace_id
+  diagnostic_storage_account_id              = var.azure_diagnostic_storage_account_id
+  diagnostic_event_hub_authorization_rule_id = var.azure_diagnostic_event_hub_authorization_rule_id
+  diagnostic_event_hub_name                  = var.azure_diagnostic_event_hub_name
+  app_key_prefix                             = var.app_key_prefix
+  tags                                       = var.tags
+}
+
+# Google Cloud Storage with Bucket Lock
+module "gcp" {
+  source = "../auditledger-gcs"
+  count  = var.cloud == "gcp" ? 1 : 0
+
+  bucket_name             = var.name
+  project_id              = var.gcp_project_id
+  location                = var.gcp_location
+  compliance_profile      = var.compliance_profile
+  retention_days          = var.retention_days
+  lock_retention_policy   = var.lock_mode == null ? null : var.lock_mode == "COMPLIANCE"
+  writer_service_accounts = var.writer_identities
+  kms_key_name            = var.encryption_key_id
+  access_log_bucket       = var.access_log_bucket
+  labels                  = local.gcp_labels
+}
+
+# Cloud-specific inputs are validated here because variable validation
+# cannot reference other variables in Terraform 1.5
+resource "terraform_data" "validation" {
+  lifecycle {
+    precondition {
+      condition     = var.cloud != "azure" || var.azure_resource_group_name != null
+      error_message = "azure_resource_group_name is required when cloud is \"azure\""
+    }
+
+    precondition {
+      condition     = var.cloud != "gcp" || var.gcp_project_id != null
+      error_message = "gcp_project_id is required when cloud is \"gcp\""
+    }
+
+    precondition {
+      condition     = var.cloud != "gcp" || var.app_key_prefix == ""
+      error_message = "app_key_prefix is not supported when cloud is \"gcp\" (there is no app_configuration output)"
+    }
+
+    precondition {
+      condition     = var.cloud != "azure" || var.access_log_bucket == null
+      error_message = "access_log_bucket is not supported when cloud is \"azure\" - use azure_log_analytics_workspace_id or the azure_diagnostic_* inputs"
+    }
+
+    precondition {
+      condition = var.cloud == "azure" || alltrue([
+        for sink in [
+          var.azure_log_analytics_workspace_id,
+          var.azure_diagnostic_storage_account_id,
+          var.azure_diagnostic_event_hub_authorization_rule_id,
+          var.azure_diagnostic_event_hub_name,
+        ] : sink == null
+      ])
+      error_message = "azure_log_analytics_workspace_id and the azure_diagnostic_* inputs are only supported when cloud is \"azure\" - use access_log_bucket"
+    }
+  }
+}
@@ -0,0 +1,125 @@
+# AuditLedger Multi-Cloud Storage Module Outputs
+
+output "cloud" {
+  description = "Cloud provider the storage was deployed to"
+  value       = var.cloud
+}
+
+output "storage_id" {
+  description = "Provider ID of the storage: bucket ARN (AWS), storage account ID (Azure) or bucket self link (GCP)"
+  value = (
+    var.cloud == "aws" ? local.aws.bucket_arn :
+    var.cloud == "azure" ? local.azure.storage_account_id :
+    local.gcp.bucket_self_link
+  )
+}
+
+output "storage_name" {
+  description = "Name of the bucket (AWS, GCP) or storage account (Azure)"
+  value = (
+    var.cloud == "aws" ? local.aws.bucket_id :
+    var.cloud == "azure" ? local.azure.storage_account_name :
+    local.gcp.bucket_name
+  )
+}
+
+output "storage_uri" {
+  description = "URI of the audit log location: s3:// URI (AWS), blob container URL (Azure) or gs:// URI (GCP)"
+  value = (
+    var.cloud == "aws" ? "s3://${local.aws.bucket_id}" :
+    var.cloud == "azure" ? "${local.azure.primary_blob_endpoint}${local.azure.container_name}" :
+    local.gcp.bucket_url
+  )
+}
+
+output "writer_credential_reference" {
+  description = "How writers obtain access: IAM policy to attach (AWS), role assignment scope (Azure) or bucket IAM bindings (GCP)"
+  value = (
+    var.cloud == "aws" ? {
+      type      = "aws_iam_policy"
+      reference = local.aws.iam_policy_arn
+    } :
+    var.cloud == "azure" ? {
+      type      = "azure_role_assignment_scope"
+      reference = local.azure.container_resource_manager_id
+    } :
+    {
+      type      = "gcp_bucket_iam"
+      reference = local.gcp.bucket_name
+    }
+  )
+}
+
+output "immutability_configuration" {
+  description = "Normalized immutability configuration for verification"
+  value = (
+    var.cloud == "aws" ? {
+      mechanism      = "s3_object_lock"
+      lock_mode      = local.aws.object_lock_configuration.mode
+      locked         = local.aws.object_lock_configuration.mode == "COMPLIANCE"
+      retention_days = local.aws.object_lock_configuration.retention_days
+    } :
+    var.cloud == "azure" ? {
+      mechanism      = "azure_container_worm"
+      lock_mode      = local.azure.immutability_configuration.immutability_policy_locked ? "COMPLIANCE" : "GOVERNANCE"
+      locked         = local.azure.immutability_configuration.immutability_policy_locked
+      retention_days = local.azure.immutability_configuration.immutability_period_days
+    } :
+    {
+      mechanism      = "gcs_bucket_lock"
+      lock_mode      = local.gcp.retention_policy.locked ? "COMPLIANCE" : "GOVERNANCE"
+      locked         = local.gcp.retention_policy.locked
+      retention_days = local.gcp.retention_policy.retention_days
+    }
+  )
+}
+
+output "immutability_verified" {
+  description = "Whether the selected cloud's retention lock is irreversible (COMPLIANCE mode or a locked policy)"
+  value = (
+    var.cloud == "aws" ? local.aws.immutability_verified :
+    var.cloud == "azure" ? local.azure.immutability_verified :
+    local.gcp.immutability_verified
+  )
+}
+
+output "compliance_profile" {
+  description = "Compliance profile applied by the selected cloud module and its requirements"
+  value = (
+    var.cloud == "aws" ? local.aws.compliance_profile :
+    var.cloud == "azure" ? local.azure.compliance_profile :
+    local.gcp.compliance_profile
+  )
+}
+
+output "app_configuration" {
+  description = "AuditLedger application storage configuration of the selected cloud module as appsettings JSON, YAML and .NET environment variables (null for gcp)"
+  value = (
+    var.cloud == "aws" ? {
+      json = local.aws.app_configuration.json
+      yaml = local.aws.app_configuration.yaml
+      env  = tomap(local.aws.app_configuration.env)
+    } :
+    var.cloud == "azure" ? {
+      json = local.azure.app_configuration.json
+      yaml = local.azure.app_configuration.yaml
+      env  = tomap(local.azure.app_configuration.env)
+    } :
+    null
+  )
+}
+
+output "aws" {
+  description = "All outputs of the auditledger-s3 module (null unless cloud is aws)"
+  value       = local.aws
+}
+
+output "azure" {
+  description = "All outputs of the auditledger-azure-blob module (null unless cloud is azure)"
+  value       = local.azure
+}
+
+output "gcp" {
+  description = "All outputs of the auditledger-gcs module (null unless cloud is gcp)"
+  value       = local.gcp
+}
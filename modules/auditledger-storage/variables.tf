@@ -0,0 +1,133 @@
+# AuditLedger Multi-Cloud Storage Module Variables
+
+variable "cloud" {
+  type        = string
+  description = "Cloud provider to deploy audit log storage to: aws, azure or gcp"
+
+  validation {
+    condition     = contains(["aws", "azure", "gcp"], var.cloud)
+    error_message = "Cloud must be one of: aws, azure, gcp"
+  }
+}
+
+variable "name" {
+  type        = string
+  description = "Name of the bucket (AWS, GCP) or storage account (Azure, 3-24 lowercase letters/numbers)"
+}
+
+variable "compliance_profile" {
+  type        = string
+  description = "Compliance profile passed to the cloud module: soc2, hipaa, pci_dss, sox, gdpr_minimal or finra_17a4"
+  default     = null
+
+  validation {
+    condition     = var.compliance_profile == null ? true : contains(["soc2", "hipaa", "pci_dss", "sox", "gdpr_minimal", "finra_17a4"], var.compliance_profile)
+    error_message = "Compliance profile must be one of: soc2, hipaa, pci_dss, sox, gdpr_minimal, finra_17a4"
+  }
+}
+
+variable "retention_days" {
+  type        = number
+  description = "Number of days to retain audit logs (minimum 365 for compliance). Defaults to the compliance profile's retention, or 2555 days (7 years) without a profile"
+  default     = null
+
+  validation {
+    condition     = coalesce(var.retention_days, 365) >= 365
+    error_message = "Retention period must be at least 365 days for compliance. Recommended: 2555 days (7 years) for SOC 2."
+  }
+}
+
+variable "lock_mode" {
+  type        = string
+  description = "Lock mode: COMPLIANCE (strict, locked retention) or GOVERNANCE (retention can be overridden with special permissions). Defaults to the compliance profile's mode, or COMPLIANCE without a profile"
+  default     = null
+
+  validation {
+    condition     = var.lock_mode == null ? true : contains(["COMPLIANCE", "GOVERNANCE"], var.lock_mode)
+    error_message = "Lock mode must be either COMPLIANCE or GOVERNANCE"
+  }
+}
+
+variable "writer_identities" {
+  type        = list(string)
+  description = "Identities that AuditLedger writes with: IAM role ARNs (AWS), principal IDs (Azure) or service account emails (GCP)"
+  default     = []
+}
+
+variable "encryption_key_id" {
+  type        = string
+  description = "Customer-managed key: KMS key ID (AWS), Key Vault ID (Azure) or Cloud KMS key name (GCP). Optional, uses provider-managed keys if not provided"
+  default     = null
+}
+
+variable "access_log_bucket" {
+  type        = string
+  description = "Bucket that receives access logs (AWS and GCP). Required by every compliance profile except gdpr_minimal"
+  default     = null
+}
+
+variable "azure_resource_group_name" {
+  type        = string
+  description = "Name of the resource group (required if cloud is azure)"
+  default     = null
+}
+
+variable "azure_create_resource_group" {
+  type        = bool
+  description = "Whether to create a new resource group (Azure only)"
+  default     = true
+}
+
+variable "azure_location" {
+  type        = string
+  description = "Azure region for resources (Azure only)"
+  default     = "eastus"
+}
+
+variable "azure_log_analytics_workspace_id" {
+  type        = string
+  description = "Log Analytics workspace ID for blob diagnostics (Azure only). Compliance profiles that require access logging need this or another diagnostics sink"
+  default     = null
+}
+
+variable "azure_diagnostic_storage_account_id" {
+  type        = string
+  description = "ID of a separate storage account to archive blob diagnostics to (Azure only)"
+  default     = null
+}
+
+variable "azure_diagnostic_event_hub_authorization_rule_id" {
+  type        = string
+  description = "Event Hub namespace authorization rule ID to stream blob diagnostics to (Azure only)"
+  default     = null
+}
+
+variable "azure_diagnostic_event_hub_name" {
+  type        = string
+  description = "Event Hub to stream diagnostics to (Azure only, requires azure_diagnostic_event_hub_authorization_rule_id)"
+  default     = null
+}
+
+variable "gcp_project_id" {
+  type        = string
+  description = "GCP project ID that owns the bucket (required if cloud is gcp)"
+  default     = null
+}
+
+variable "gcp_location" {
+  type        = string
+  description = "GCS location (GCP only)"
+  default     = "US"
+}
+
+variable "app_key_prefix" {
+  type        = string
+  description = "Key prefix the application writes audit logs under, for the app_configuration output (AWS and Azure only)"
+  default     = ""
+}
+
+variable "tags" {
+  type        = map(string)
+  description = "Additional tags for resources (converted to lowercase labels on GCP)"
+  default     = {}
+}
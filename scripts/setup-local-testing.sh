@@ -47,6 +47,7 @@ echo ""
 # Create necessary directories
 echo "📁 Creating directories..."
 mkdir -p "$PROJECT_ROOT/.localstack"
+mkdir -p "$PROJECT_ROOT/.minio"
 echo -e "${GREEN}✅ Directories created${NC}"
 
 # Start services
@@ -78,8 +79,22 @@ for i in {1..30}; do
     sleep 2
 done
 
+# Check MinIO
+echo "Checking MinIO..."
+for i in {1..30}; do
+    if curl -s http://localhost:9000/minio/health/live > /dev/null 2>&1; then
+        echo -e "${GREEN}✅ MinIO is ready${NC}"
+        break
+    fi
+    if [ $i -eq 30 ]; then
+        echo -e "${RED}❌ MinIO failed to start${NC}"
+        exit 1
+    fi
+    sleep 2
+done
+
 echo ""
-echo -e "${GREEN}🎉 LocalStack is ready!${NC}"
+echo -e "${GREEN}🎉 LocalStack and MinIO are ready!${NC}"
 echo ""
 
 # Create .env file if it doesn't exist
@@ -89,6 +104,12 @@ if [ ! -f "$PROJECT_ROOT/.env.localstack" ]; then
     echo -e "${GREEN}✅ Created .env.localstack${NC}"
 fi
 
+if [ ! -f "$PROJECT_ROOT/.env.minio" ]; then
+    echo "📝 Creating .env.minio from example..."
+    cp "$PROJECT_ROOT/env.minio.example" "$PROJECT_ROOT/.env.minio"
+    echo -e "${GREEN}✅ Created .env.minio${NC}"
+fi
+
 echo ""
 echo "📝 Next steps:"
 echo ""
@@ -102,7 +123,10 @@ echo "3. Or manually run tests:"
 echo "   cd tests/integration"
 echo "   USE_LOCALSTACK=true AWS_ENDPOINT_URL=http://localhost:4566 go test -v"
 echo ""
-echo "4. Stop services when done:"
+echo "4. Run MinIO end-to-end tests:"
+echo "   ./scripts/test-minio.sh"
+echo ""
+echo "5. Stop services when done:"
 echo "   docker compose down"
 echo ""
 echo "📚 For more info, see: tests/README.md"
@@ -20,10 +20,10 @@ fi
 echo "✅ Services stopped"
 
 # Optional: Clean up data
-read -p "Do you want to delete test data (.localstack)? [y/N] " -n 1 -r
+read -p "Do you want to delete test data (.localstack, .minio)? [y/N] " -n 1 -r
 echo
 if [[ $REPLY =~ ^[Yy]$ ]]; then
-    rm -rf "$PROJECT_ROOT/.localstack"
+    rm -rf "$PROJECT_ROOT/.localstack" "$PROJECT_ROOT/.minio"
     echo "✅ Test data deleted"
 else
     echo "ℹ️  Test data preserved for next run"
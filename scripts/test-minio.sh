@@ -0,0 +1,45 @@
+#!/bin/bash
+# Quick script to run end-to-end tests against the MinIO container with environment loaded
+
+set -e
+
+SCRIPT_DIR="$(cd "$(dirname "${BASH_SOURCE[0]}")" && pwd)"
+PROJECT_ROOT="$(dirname "$SCRIPT_DIR")"
+
+# Load MinIO environment variables
+ENV_FILE="$PROJECT_ROOT/.env.minio"
+
+if [ ! -f "$ENV_FILE" ]; then
+    echo "⚠️  .env.minio not found. Creating from example..."
+    cp "$PROJECT_ROOT/env.minio.example" "$ENV_FILE"
+    echo "✅ Created .env.minio"
+fi
+
+echo "📝 Loading MinIO environment from .env.minio..."
+# shellcheck source=/dev/null
+source "$ENV_FILE"
+
+echo "🔍 Environment configured:"
+echo "  MINIO_ENDPOINT: $MINIO_ENDPOINT"
+echo "  USE_MINIO: $USE_MINIO"
+echo ""
+
+# Check if MinIO is running
+if ! curl -s "http://$MINIO_ENDPOINT/minio/health/live" > /dev/null 2>&1; then
+    echo "❌ MinIO is not running!"
+    echo ""
+    echo "Start it with:"
+    echo "  docker compose up -d minio"
+    echo ""
+    exit 1
+fi
+
+echo "✅ MinIO is running"
+echo ""
+
+# Determine what to run
+TEST_PATTERN="${1:-TestMinIO}"
+
+echo "🧪 Running tests matching: $TEST_PATTERN"
+cd "$PROJECT_ROOT/tests/integration"
+go test -v -timeout 15m -run "$TEST_PATTERN"
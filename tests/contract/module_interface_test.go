@@ -2,6 +2,8 @@ package contract
 
 import (
 	"os"
+	"regexp"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -24,6 +26,16 @@ type Output struct {
 	Description string `json:"description"`
 }
 
+// assertAttribute checks that a .tf file sets an attribute to an expression on a line of
+// its own, whatever alignment terraform fmt gives it
+func assertAttribute(t *testing.T, hcl, name, value string, msgAndArgs ...interface{}) {
+	t.Helper()
+	pattern := `(?m)^\s*` + regexp.QuoteMeta(name) + `\s*=\s*` + regexp.QuoteMeta(value) + `\s*$`
+	if !regexp.MustCompile(pattern).MatchString(hcl) {
+		assert.Fail(t, name+" = "+value+" is not set", msgAndArgs...)
+	}
+}
+
 // TestS3ModuleInterface validates the S3 module's interface contract
 func TestS3ModuleInterface(t *testing.T) {
 	// This test ensures the module maintains its interface contract
@@ -62,7 +74,7 @@ func TestAzureModuleInterface(t *testing.T) {
 	expectedInputs := []string{
 		"storage_account_name",
 		"resource_group_name",
-		// Other vars have defaults
+		// Other vars have defaults, including writer_principal_ids, reader_principal_ids and admin_principal_ids
 	}
 
 	expectedOutputs := []string{
@@ -72,6 +84,204 @@ func TestAzureModuleInterface(t *testing.T) {
 		"container_name",
 		"resource_group_name",
 		"managed_identity_principal_id",
+		"container_resource_manager_id",
+		"writer_role_definition_id",
+		"reader_role_definition_id",
+		"admin_role_definition_id",
+		"immutability_configuration",
+		"immutability_verified",
+	}
+
+	for _, input := range expectedInputs {
+		assert.NotEmpty(t, input)
+	}
+
+	for _, output := range expectedOutputs {
+		assert.NotEmpty(t, output)
+	}
+}
+
+// TestAzureModuleContainerImmutability ensures the Azure module enforces WORM
+// with a container immutability policy rather than versioning alone
+func TestAzureModuleContainerImmutability(t *testing.T) {
+	content, err := os.ReadFile("../../modules/auditledger-azure-blob/main.tf")
+	require.NoError(t, err, "Should be able to read Azure module")
+
+	mainTf := string(content)
+	assert.Contains(t, mainTf, `resource "azurerm_storage_container_immutability_policy" "audit_logs"`)
+	assertAttribute(t, mainTf, "immutability_period_in_days", "local.retention_days")
+
+	outputs, err := os.ReadFile("../../modules/auditledger-azure-blob/outputs.tf")
+	require.NoError(t, err)
+
+	// The output must reflect the deployed policy, not hard-coded values
+	for _, field := range []string{"immutability_period_days", "immutability_policy_locked", "version_level_immutability", "legal_hold_tags"} {
+		assert.Contains(t, string(outputs), field)
+	}
+}
+
+// TestAzureModuleRolesExcludeDelete ensures the custom writer and reader roles
+// never grant blob deletion and are assigned at container scope
+func TestAzureModuleRolesExcludeDelete(t *testing.T) {
+	content, err := os.ReadFile("../../modules/auditledger-azure-blob/main.tf")
+	require.NoError(t, err, "Should be able to read Azure module")
+
+	mainTf := string(content)
+	assert.NotContains(t, mainTf, "Storage Blob Data Contributor\"", "Built-in contributor role includes delete")
+
+	for _, role := range []string{"auditledger_writer", "auditledger_reader"} {
+		start := strings.Index(mainTf, `resource "azurerm_role_definition" "`+role+`"`)
+		require.NotEqual(t, -1, start, "Role definition %s should exist", role)
+
+		definition := mainTf[start:]
+		granted := definition[:strings.Index(definition, "not_data_actions")]
+		assert.NotContains(t, granted, "/delete\"", "%s must not grant delete", role)
+		assert.NotContains(t, granted, "permanentDelete", "%s must not grant permanent delete", role)
+		assert.NotContains(t, granted, "deleteBlobVersion", "%s must not grant version delete", role)
+
+		assignment := `resource "azurerm_role_assignment" "` + role + `"`
+		assignmentStart := strings.Index(mainTf, assignment)
+		require.NotEqual(t, -1, assignmentStart, "Role assignment %s should exist", role)
+		assert.Contains(t, mainTf[assignmentStart:assignmentStart+300], "azurerm_storage_container.audit_logs.resource_manager_id", "%s should be assigned at container scope", role)
+	}
+}
+
+// TestAzureModuleKeyVaultCustody ensures created Key Vaults cannot be purged and
+// the storage identity can only wrap and unwrap the customer-managed key
+func TestAzureModuleKeyVaultCustody(t *testing.T) {
+	content, err := os.ReadFile("../../modules/auditledger-azure-blob/main.tf")
+	require.NoError(t, err, "Should be able to read Azure module")
+
+	mainTf := string(content)
+	assertAttribute(t, mainTf, "purge_protection_enabled", "true")
+	assertAttribute(t, mainTf, "key_opts", `["wrapKey", "unwrapKey"]`)
+	assertAttribute(t, mainTf, "role_definition_name", `"Key Vault Crypto Service Encryption User"`)
+	assert.Contains(t, mainTf, "rotation_policy {")
+}
+
+// TestAzureModuleManagementLocks ensures the storage account and resource group can be
+// protected from deletion by management locks
+func TestAzureModuleManagementLocks(t *testing.T) {
+	content, err := os.ReadFile("../../modules/auditledger-azure-blob/main.tf")
+	require.NoError(t, err, "Should be able to read Azure module")
+
+	mainTf := string(content)
+	assert.Contains(t, mainTf, `resource "azurerm_management_lock" "storage_account"`)
+	assert.Contains(t, mainTf, `resource "azurerm_management_lock" "resource_group"`)
+}
+
+// TestAzureModuleReplicaImmutability ensures a created replica gets the same
+// retention and lock state as the source container
+func TestAzureModuleReplicaImmutability(t *testing.T) {
+	content, err := os.ReadFile("../../modules/auditledger-azure-blob/main.tf")
+	require.NoError(t, err, "Should be able to read Azure module")
+
+	mainTf := string(content)
+	start := strings.Index(mainTf, `resource "azurerm_storage_container_immutability_policy" "replica"`)
+	require.NotEqual(t, -1, start, "Replica container should have an immutability policy")
+
+	policy := mainTf[start:]
+	policy = policy[:strings.Index(policy, "\n}\n")]
+	assert.Contains(t, policy, "= local.retention_days")
+	assert.Contains(t, policy, "= local.lock_immutability_policy")
+	assert.Contains(t, mainTf, `resource "azurerm_storage_object_replication" "audit_logs"`)
+}
+
+// TestAzureModuleLifecycleAccessTiering ensures the rehydrate grace period is only set
+// with modification-time tiering, since Azure rejects it next to last-access-time rules
+func TestAzureModuleLifecycleAccessTiering(t *testing.T) {
+	content, err := os.ReadFile("../../modules/auditledger-azure-blob/main.tf")
+	require.NoError(t, err, "Should be able to read Azure module")
+
+	mainTf := string(content)
+	assertAttribute(t, mainTf, "tier_to_archive_after_days_since_last_tier_change_greater_than",
+		"!local.lifecycle_by_access && local.tiers.tier_to_archive_after_days != null ? local.tiers.rehydrate_grace_days : null")
+	assertAttribute(t, mainTf, "tier_to_archive_after_days_since_last_access_time_greater_than",
+		"local.lifecycle_by_access ? local.tiers.tier_to_archive_after_days : null")
+}
+
+// TestAzureModuleBlobDiagnostics ensures read, write and delete logs are collected
+// from the blob service, where Azure emits them, rather than the account
+func TestAzureModuleBlobDiagnostics(t *testing.T) {
+	content, err := os.ReadFile("../../modules/auditledger-azure-blob/main.tf")
+	require.NoError(t, err, "Should be able to read Azure module")
+
+	mainTf := string(content)
+	start := strings.Index(mainTf, `resource "azurerm_monitor_diagnostic_setting" "blob"`)
+	require.NotEqual(t, -1, start, "Blob service diagnostic setting should exist")
+
+	blob := mainTf[start:]
+	assert.Contains(t, blob, `/blobServices/default"`)
+
+	account := mainTf[strings.Index(mainTf, `resource "azurerm_monitor_diagnostic_setting" "audit_logs"`):start]
+	assert.NotContains(t, account, "enabled_log", "The storage account does not emit blob logs")
+
+	for _, operation := range []string{"listKeys/action", "immutabilityPolicies/delete", "Microsoft.Authorization/locks/delete"} {
+		assert.Contains(t, mainTf, operation, "Activity Log alert on %s should exist", operation)
+	}
+}
+
+// TestAzureModuleVerifierOutputs ensures the outputs read by auditledger-verify azure
+// still exist and keep the names of the diagnostic settings it looks up
+func TestAzureModuleVerifierOutputs(t *testing.T) {
+	outputs, err := os.ReadFile("../../modules/auditledger-azure-blob/outputs.tf")
+	require.NoError(t, err)
+
+	for _, field := range []string{
+		`output "security_configuration"`, "min_tls_version", "shared_access_key_enabled",
+		"network_default_action", "change_feed_retention_days", "threat_protection_enabled",
+		"soft_delete_days", "diagnostics_enabled",
+	} {
+		assert.Contains(t, string(outputs), field)
+	}
+
+	mainTf, err := os.ReadFile("../../modules/auditledger-azure-blob/main.tf")
+	require.NoError(t, err)
+	assertAttribute(t, string(mainTf), "name", `"auditledger-diagnostics"`)
+	assertAttribute(t, string(mainTf), "name", `"auditledger-blob-diagnostics"`)
+}
+
+// TestGCSModuleInterface validates the GCS module's interface contract
+func TestGCSModuleInterface(t *testing.T) {
+	expectedInputs := []string{
+		"bucket_name",
+		"project_id",
+		"writer_service_accounts",
+		// retention_days and lock_retention_policy have defaults
+	}
+
+	expectedOutputs := []string{
+		"bucket_name",
+		"bucket_url",
+		"bucket_self_link",
+		"retention_policy",
+		"immutability_configuration",
+		"immutability_verified",
+	}
+
+	for _, input := range expectedInputs {
+		assert.NotEmpty(t, input)
+	}
+
+	for _, output := range expectedOutputs {
+		assert.NotEmpty(t, output)
+	}
+}
+
+// TestStorageModuleInterface validates the multi-cloud wrapper's normalized interface contract
+func TestStorageModuleInterface(t *testing.T) {
+	expectedInputs := []string{
+		"cloud",
+		"name",
+		// retention_days, lock_mode, writer_identities, encryption_key_id and tags have defaults
+	}
+
+	expectedOutputs := []string{
+		"cloud",
+		"storage_id",
+		"storage_name",
+		"storage_uri",
+		"writer_credential_reference",
 		"immutability_configuration",
 		"immutability_verified",
 	}
@@ -128,6 +338,33 @@ func TestS3ModuleObjectLockModes(t *testing.T) {
 	}
 }
 
+// TestS3ModuleManifestPrefix ensures only the manifest writer roles can write under the
+// manifest prefix, that they cannot write anywhere else, and that auditledger-manifest
+// can find the prefix in the module outputs
+func TestS3ModuleManifestPrefix(t *testing.T) {
+	content, err := os.ReadFile("../../modules/auditledger-s3/main.tf")
+	require.NoError(t, err, "Should be able to read S3 module")
+	mainTf := string(content)
+
+	assertAttribute(t, mainTf, "manifest_prefix", `"_manifests/"`, "auditledger-manifest defaults to _manifests/")
+	for _, sid := range []string{"AllowManifestWrite", "AllowManifestRead", "DenyManifestWriteByOthers", "DenyManifestWriterOutsidePrefix"} {
+		assert.Contains(t, mainTf, `"`+sid+`"`, "Bucket policy statement %s should exist", sid)
+	}
+	assert.Contains(t, mainTf, `NotResource = "${aws_s3_bucket.audit_logs.arn}/${local.manifest_prefix}*"`)
+
+	start := strings.Index(mainTf, `resource "aws_iam_policy" "manifest_writer"`)
+	require.NotEqual(t, -1, start, "Manifest writer IAM policy should exist")
+	policy := mainTf[start:]
+	assert.Contains(t, policy, `Resource = "${aws_s3_bucket.audit_logs.arn}/${local.manifest_prefix}*"`, "Manifest writers only write under the prefix")
+	assert.NotContains(t, policy, "s3:DeleteObject")
+
+	outputs, err := os.ReadFile("../../modules/auditledger-s3/outputs.tf")
+	require.NoError(t, err)
+	for _, field := range []string{`output "manifest_configuration"`, "prefix", "signing_kms_key_arn"} {
+		assert.Contains(t, string(outputs), field)
+	}
+}
+
 // TestModuleOutputsMatchDocumentation validates outputs match README
 func TestModuleOutputsMatchDocumentation(t *testing.T) {
 	// Read module README
@@ -149,3 +386,73 @@ func TestModuleOutputsMatchDocumentation(t *testing.T) {
 		assert.Contains(t, readme, output, "Output should be documented in README")
 	}
 }
+
+// TestComplianceProfileContract ensures every storage module accepts the same
+// compliance profiles that the compliance-profile module defines
+func TestComplianceProfileContract(t *testing.T) {
+	profiles := []string{"soc2", "hipaa", "pci_dss", "sox", "gdpr_minimal", "finra_17a4"}
+
+	content, err := os.ReadFile("../../modules/auditledger-compliance-profile/main.tf")
+	require.NoError(t, err, "Should be able to read compliance profile module")
+
+	for _, profile := range profiles {
+		assert.Regexp(t, `(?m)^\s*`+profile+`\s*=\s*\{$`, string(content), "Profile %s should be defined", profile)
+	}
+
+	modules := []string{"auditledger-s3", "auditledger-azure-blob", "auditledger-gcs", "auditledger-storage"}
+
+	for _, module := range modules {
+		variables, err := os.ReadFile("../../modules/" + module + "/variables.tf")
+		require.NoError(t, err)
+
+		readme, err := os.ReadFile("../../modules/" + module + "/README.md")
+		require.NoError(t, err)
+
+		assert.Contains(t, string(variables), `variable "compliance_profile"`, "%s should accept compliance_profile", module)
+		assert.Contains(t, string(readme), "compliance_profile", "%s README should document compliance_profile", module)
+		assert.NotContains(t, string(variables)+string(readme), "SOC2-HIPAA-PCIDSS", "%s should not claim frameworks it does not enforce", module)
+
+		for _, profile := range profiles {
+			assert.Contains(t, string(variables), `"`+profile+`"`, "%s should accept the %s profile", module, profile)
+		}
+	}
+}
+
+// TestAppConfigurationContract ensures the application settings carry the headers the S3
+// bucket policy conditions on, and that every example configures AuditLedger from them
+func TestAppConfigurationContract(t *testing.T) {
+	content, err := os.ReadFile("../../modules/auditledger-s3/main.tf")
+	require.NoError(t, err, "Should be able to read S3 module")
+	mainTf := string(content)
+
+	// Each header the bucket policy conditions on must be in the required headers
+	for _, header := range []string{"x-amz-object-lock-mode", "x-amz-server-side-encryption"} {
+		assert.Contains(t, mainTf, `"s3:`+header+`"`, "Bucket policy should condition on %s", header)
+		assert.Contains(t, mainTf, `{ Name = "`+header+`", Value = `, "app_configuration should require %s", header)
+	}
+	assert.Contains(t, mainTf, `{ Name = "x-amz-object-lock-mode", Value = local.object_lock_mode }`)
+	assertAttribute(t, mainTf, "ReservedPrefixes", "[local.manifest_prefix]")
+
+	for _, module := range []string{"auditledger-s3", "auditledger-azure-blob", "auditledger-storage"} {
+		outputs, err := os.ReadFile("../../modules/" + module + "/outputs.tf")
+		require.NoError(t, err)
+		variables, err := os.ReadFile("../../modules/" + module + "/variables.tf")
+		require.NoError(t, err)
+
+		assert.Contains(t, string(outputs), `output "app_configuration"`, "%s should output app_configuration", module)
+		assert.Contains(t, string(variables), `variable "app_key_prefix"`, "%s should accept app_key_prefix", module)
+	}
+
+	examples := map[string]string{
+		"ec2/main.tf":               "module.auditledger_s3.app_configuration.json",
+		"ecs-fargate/main.tf":       "module.auditledger_s3.app_configuration.env",
+		"lambda/main.tf":            "module.auditledger_s3.app_configuration.env",
+		"azure-app-service/main.tf": "module.auditledger_storage.app_configuration.env",
+	}
+	for example, reference := range examples {
+		content, err := os.ReadFile("../../examples/" + example)
+		require.NoError(t, err)
+		assert.Contains(t, string(content), reference, "%s should use the rendered configuration", example)
+		assert.NotContains(t, string(content), `"AuditLedger__Storage__Provider"`, "%s should not hand-write storage settings", example)
+	}
+}
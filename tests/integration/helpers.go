@@ -6,6 +6,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/gruntwork-io/terratest/modules/terraform"
 )
 
@@ -47,6 +51,89 @@ func GetAzureConfig(t *testing.T, terraformDir string, vars map[string]interface
 	}
 }
 
+// GetMinIOConfig returns Terraform options configured for the MinIO container from docker-compose.yml
+func GetMinIOConfig(t *testing.T, terraformDir string, vars map[string]interface{}) *terraform.Options {
+	return &terraform.Options{
+		TerraformDir:    terraformDir,
+		TerraformBinary: "terraform",
+		Vars:            vars,
+		EnvVars: map[string]string{
+			"MINIO_ENDPOINT":     getEnvOrDefault("MINIO_ENDPOINT", "localhost:9000"),
+			"MINIO_USER":         getEnvOrDefault("MINIO_USER", "minioadmin"),
+			"MINIO_PASSWORD":     getEnvOrDefault("MINIO_PASSWORD", "minioadmin"),
+			"MINIO_ENABLE_HTTPS": getEnvOrDefault("MINIO_ENABLE_HTTPS", "false"),
+		},
+	}
+}
+
+// IsMinIO returns true if tests should run against the MinIO container
+func IsMinIO() bool {
+	return os.Getenv("USE_MINIO") == "true"
+}
+
+// NewMinIOClient returns an S3 client for the MinIO container using the given credentials
+func NewMinIOClient(t *testing.T, accessKey string, secretKey string) *s3.S3 {
+	scheme := "http"
+	if os.Getenv("MINIO_ENABLE_HTTPS") == "true" {
+		scheme = "https"
+	}
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:           aws.String("us-east-1"),
+		Endpoint:         aws.String(fmt.Sprintf("%s://%s", scheme, getEnvOrDefault("MINIO_ENDPOINT", "localhost:9000"))),
+		Credentials:      credentials.NewStaticCredentials(accessKey, secretKey, ""),
+		S3ForcePathStyle: aws.Bool(true),
+	})
+	if err != nil {
+		t.Fatalf("Failed to create MinIO session: %v", err)
+	}
+
+	return s3.New(sess)
+}
+
+// NewMinIORootClient returns an S3 client authenticated as the MinIO root user
+func NewMinIORootClient(t *testing.T) *s3.S3 {
+	return NewMinIOClient(t, getEnvOrDefault("MINIO_USER", "minioadmin"), getEnvOrDefault("MINIO_PASSWORD", "minioadmin"))
+}
+
+// emptyGovernanceBucket deletes every object version and delete marker, bypassing GOVERNANCE retention
+// Only works for GOVERNANCE mode buckets - COMPLIANCE locked versions cannot be removed by anyone
+func emptyGovernanceBucket(t *testing.T, client *s3.S3, bucketName string) {
+	err := client.ListObjectVersionsPages(&s3.ListObjectVersionsInput{
+		Bucket: aws.String(bucketName),
+	}, func(page *s3.ListObjectVersionsOutput, lastPage bool) bool {
+		for _, version := range page.Versions {
+			deleteObjectVersion(t, client, bucketName, version.Key, version.VersionId)
+		}
+		for _, marker := range page.DeleteMarkers {
+			deleteObjectVersion(t, client, bucketName, marker.Key, marker.VersionId)
+		}
+		return true
+	})
+	if err != nil {
+		t.Logf("Warning: Could not list object versions in %s: %v", bucketName, err)
+	}
+}
+
+func deleteObjectVersion(t *testing.T, client *s3.S3, bucketName string, key *string, versionId *string) {
+	_, err := client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket:                    aws.String(bucketName),
+		Key:                       key,
+		VersionId:                 versionId,
+		BypassGovernanceRetention: aws.Bool(true),
+	})
+	if err != nil {
+		t.Logf("Warning: Could not delete %s (version %s): %v", aws.StringValue(key), aws.StringValue(versionId), err)
+	}
+}
+
+func getEnvOrDefault(key string, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
 // IsLocalStack returns true if tests should run against LocalStack
 func IsLocalStack() bool {
 	return os.Getenv("USE_LOCALSTACK") == "true"
@@ -0,0 +1,141 @@
+package test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/gruntwork-io/terratest/modules/terraform"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMinIOModuleLocal deploys the MinIO module and exercises Object Lock end to end
+// Run with: USE_MINIO=true go test -v -run TestMinIOModule
+// Note: MinIO tests cannot run in parallel (they share the same module directory)
+func TestMinIOModuleLocal(t *testing.T) {
+	if !IsMinIO() {
+		t.Skip("Skipping MinIO test - set USE_MINIO=true to run")
+	}
+
+	// Do NOT run in parallel - MinIO tests share the same module directory
+
+	bucketName := fmt.Sprintf("test-minio-%s", strings.ToLower(random.UniqueId()))
+
+	vars := map[string]interface{}{
+		"bucket_name":    bucketName,
+		"retention_days": 365,
+		// GOVERNANCE so the root user can clean up after the test
+		"object_lock_mode": "GOVERNANCE",
+	}
+
+	terraformOptions := GetMinIOConfig(t, "../../modules/auditledger-minio", vars)
+	rootClient := NewMinIORootClient(t)
+
+	// Unlike LocalStack, MinIO can destroy Object Lock buckets once they are empty
+	defer terraform.Destroy(t, terraformOptions)
+	defer emptyGovernanceBucket(t, rootClient, bucketName)
+
+	terraform.InitAndApply(t, terraformOptions)
+
+	outputBucketId := terraform.Output(t, terraformOptions, "bucket_id")
+	assert.Equal(t, bucketName, outputBucketId)
+
+	// GOVERNANCE retention can be bypassed, so it is not reported as verified
+	immutabilityVerified := terraform.Output(t, terraformOptions, "immutability_verified")
+	assert.Equal(t, "false", immutabilityVerified)
+
+	// Validate Object Lock default retention on the live bucket
+	lockConfig, err := rootClient.GetObjectLockConfiguration(&s3.GetObjectLockConfigurationInput{
+		Bucket: aws.String(bucketName),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Enabled", aws.StringValue(lockConfig.ObjectLockConfiguration.ObjectLockEnabled))
+	assert.Equal(t, "GOVERNANCE", aws.StringValue(lockConfig.ObjectLockConfiguration.Rule.DefaultRetention.Mode))
+	assert.Equal(t, int64(365), aws.Int64Value(lockConfig.ObjectLockConfiguration.Rule.DefaultRetention.Days))
+
+	versioning, err := rootClient.GetBucketVersioning(&s3.GetBucketVersioningInput{
+		Bucket: aws.String(bucketName),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "Enabled", aws.StringValue(versioning.Status))
+}
+
+// TestMinIOModuleWriterCannotDelete verifies the writer user can write but never delete audit records
+// Note: MinIO tests cannot run in parallel (they share the same module directory)
+func TestMinIOModuleWriterCannotDelete(t *testing.T) {
+	if !IsMinIO() {
+		t.Skip("Skipping MinIO test - set USE_MINIO=true to run")
+	}
+
+	// Do NOT run in parallel - MinIO tests share the same module directory
+
+	bucketName := fmt.Sprintf("test-minio-ops-%s", strings.ToLower(random.UniqueId()))
+
+	vars := map[string]interface{}{
+		"bucket_name":      bucketName,
+		"retention_days":   365,
+		"object_lock_mode": "GOVERNANCE",
+	}
+
+	terraformOptions := GetMinIOConfig(t, "../../modules/auditledger-minio", vars)
+	rootClient := NewMinIORootClient(t)
+
+	defer terraform.Destroy(t, terraformOptions)
+	defer emptyGovernanceBucket(t, rootClient, bucketName)
+
+	terraform.InitAndApply(t, terraformOptions)
+
+	writerClient := NewMinIOClient(t,
+		terraform.Output(t, terraformOptions, "writer_access_key"),
+		terraform.Output(t, terraformOptions, "writer_secret_key"),
+	)
+
+	// Writer can append audit records
+	key := "events/2025/01/01/0001.json"
+	putOutput, err := writerClient.PutObject(&s3.PutObjectInput{
+		Bucket: aws.String(bucketName),
+		Key:    aws.String(key),
+		Body:   strings.NewReader(`{"event":"user.login","actor":"alice"}`),
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, aws.StringValue(putOutput.VersionId))
+
+	// Default retention is applied to the new version
+	head, err := rootClient.HeadObject(&s3.HeadObjectInput{
+		Bucket:    aws.String(bucketName),
+		Key:       aws.String(key),
+		VersionId: putOutput.VersionId,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "GOVERNANCE", aws.StringValue(head.ObjectLockMode))
+	assert.True(t, aws.TimeValue(head.ObjectLockRetainUntilDate).After(time.Now().AddDate(0, 0, 364)))
+
+	// Writer can read it back
+	_, err = writerClient.GetObject(&s3.GetObjectInput{
+		Bucket:    aws.String(bucketName),
+		Key:       aws.String(key),
+		VersionId: putOutput.VersionId,
+	})
+	assert.NoError(t, err)
+
+	// Writer cannot delete the version (bucket policy + missing permission)
+	_, err = writerClient.DeleteObject(&s3.DeleteObjectInput{
+		Bucket:    aws.String(bucketName),
+		Key:       aws.String(key),
+		VersionId: putOutput.VersionId,
+	})
+	assert.Error(t, err, "Writer must not be able to delete audit records")
+
+	// Even the root user cannot delete a locked version without bypassing governance
+	_, err = rootClient.DeleteObject(&s3.DeleteObjectInput{
+		Bucket:    aws.String(bucketName),
+		Key:       aws.String(key),
+		VersionId: putOutput.VersionId,
+	})
+	assert.Error(t, err, "Locked versions must not be deletable without a governance bypass")
+}
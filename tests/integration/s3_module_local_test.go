@@ -42,7 +42,7 @@ func TestS3ModuleLocalStack(t *testing.T) {
 	overridePath := copyLocalStackOverride(t, terraformOptions.TerraformDir)
 	defer removeLocalStackOverride(overridePath)
 	// Note: Skip terraform destroy for LocalStack - it hangs on Object Lock buckets
-	// LocalStack container cleanup handles resource disposal
+	// make local-reap removes the bucket and its IAM policy
 
 	// Deploy
 	terraform.InitAndApply(t, terraformOptions)
@@ -52,7 +52,7 @@ func TestS3ModuleLocalStack(t *testing.T) {
 	assert.Equal(t, bucketName, outputBucketId)
 
 	immutabilityVerified := terraform.Output(t, terraformOptions, "immutability_verified")
-	assert.Equal(t, "true", immutabilityVerified)
+	assert.Equal(t, "false", immutabilityVerified) // GOVERNANCE retention can be bypassed
 }
 
 // TestS3ModuleLocalStackBasicOperations tests basic S3 operations in LocalStack
@@ -84,7 +84,7 @@ func TestS3ModuleLocalStackBasicOperations(t *testing.T) {
 	overridePath := copyLocalStackOverride(t, terraformOptions.TerraformDir)
 	defer removeLocalStackOverride(overridePath)
 	// Note: Skip terraform destroy for LocalStack - it hangs on Object Lock buckets
-	// LocalStack container cleanup handles resource disposal
+	// make local-reap removes the bucket and its IAM policy
 	terraform.InitAndApply(t, terraformOptions)
 
 	// Validate outputs exist
@@ -51,7 +51,7 @@ func TestS3ModuleImmutability(t *testing.T) {
 
 	// Validate immutability_verified output
 	immutabilityVerified := terraform.Output(t, terraformOptions, "immutability_verified")
-	assert.Equal(t, "true", immutabilityVerified)
+	assert.Equal(t, "false", immutabilityVerified) // GOVERNANCE retention can be bypassed
 
 	// Validate S3 bucket exists
 	aws.AssertS3BucketExists(t, awsRegion, bucketName)
@@ -224,11 +224,8 @@ func TestS3ModuleWithReplication(t *testing.T) {
 		},
 	}
 
-	// Note: This test will fail unless the replica bucket and role actually exist
-	// It's included to validate the module accepts replication parameters
-	defer terraform.Destroy(t, terraformOptions)
-
-	// Just validate the plan - don't apply since we'd need to create replica bucket first
+	// Only plan: applying would need the replica bucket and role to exist first. Nothing is
+	// created, so there is nothing to destroy
 	terraform.Init(t, terraformOptions)
 	planOutput := terraform.Plan(t, terraformOptions)
 	assert.Contains(t, planOutput, "aws_s3_bucket_replication_configuration.audit_logs")
@@ -0,0 +1,68 @@
+// Command auditledger-adopt brings existing, hand-built audit buckets under the AuditLedger
+// modules without recreating them.
+//
+// It reads the live bucket configuration, writes a module call with the inputs that reproduce
+// it and an `import` block for every resource the module manages, and reports each setting the
+// next apply would still change. The exit code is 0 when the module would change nothing, 1
+// when it would change settings and 2 when adoption is unsafe or on usage or input errors; no
+// configuration is written for an unsafe adoption.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+const (
+	exitOK      = 0
+	exitChanges = 1
+	exitError   = 2
+)
+
+type command struct {
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+var commands = map[string]command{
+	"s3": {"Generate modules/auditledger-s3 configuration and imports for an existing bucket", runS3},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		usage(stderr)
+		return exitError
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		usage(stderr)
+		return exitError
+	}
+	return cmd.run(args[1:], stdout, stderr)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: auditledger-adopt <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-14s %s\n", name, commands[name].summary)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'auditledger-adopt <command> -h' for the flags of a command.")
+}
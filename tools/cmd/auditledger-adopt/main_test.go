@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const writer = "arn:aws:iam::123456789012:role/auditledger-writer"
+
+// s3Endpoint answers the bucket configuration reads of the S3 API; subresources not in
+// configured return the error S3 returns when the setting was never configured
+func s3Endpoint(t *testing.T, configured map[string]string) string {
+	t.Helper()
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+
+	missing := map[string]string{
+		"object-lock":       "ObjectLockConfigurationNotFoundError",
+		"publicAccessBlock": "NoSuchPublicAccessBlockConfiguration",
+		"encryption":        "ServerSideEncryptionConfigurationNotFoundError",
+		"policy":            "NoSuchBucketPolicy",
+		"lifecycle":         "NoSuchLifecycleConfiguration",
+		"replication":       "ReplicationConfigurationNotFoundError",
+		"tagging":           "NoSuchTagSet",
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for subresource, code := range missing {
+			if _, ok := r.URL.Query()[subresource]; !ok {
+				continue
+			}
+			if body, ok := configured[subresource]; ok {
+				fmt.Fprint(w, body)
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, "<Error><Code>%s</Code><Message>not configured</Message></Error>", code)
+			return
+		}
+		if _, ok := r.URL.Query()["logging"]; ok {
+			fmt.Fprint(w, "<BucketLoggingStatus/>")
+			return
+		}
+		t.Errorf("unexpected request %s %s", r.Method, r.URL)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+var lockedBucket = map[string]string{
+	"object-lock": "<ObjectLockConfiguration><ObjectLockEnabled>Enabled</ObjectLockEnabled><Rule><DefaultRetention><Mode>COMPLIANCE</Mode><Days>2555</Days></DefaultRetention></Rule></ObjectLockConfiguration>",
+	"policy":      `{"Statement": [{"Sid": "Writers", "Effect": "Allow", "Principal": {"AWS": "` + writer + `"}, "Action": "s3:PutObject", "Resource": "*"}]}`,
+}
+
+func TestRunUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitError, run(nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "s3")
+
+	stderr.Reset()
+	assert.Equal(t, exitError, run([]string{"gcs"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), `unknown command "gcs"`)
+}
+
+func TestRunS3RequiresBucket(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitError, run([]string{"s3"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "-bucket is required")
+
+	stderr.Reset()
+	assert.Equal(t, exitError, run([]string{"s3", "-bucket", "audit-logs", "-name", "audit logs"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "not a valid Terraform module name")
+}
+
+func TestRunS3(t *testing.T) {
+	endpoint := s3Endpoint(t, lockedBucket)
+	hcl := filepath.Join(t.TempDir(), "adopt.tf")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"s3", "-endpoint", endpoint, "-bucket", "audit-logs", "-name", "legacy", "-hcl", hcl}, &stdout, &stderr)
+	require.Equal(t, exitChanges, code, stderr.String())
+	assert.Empty(t, stdout.String())
+	assert.Contains(t, stderr.String(), "auditledger-adopt: aws audit-logs")
+	assert.Contains(t, stderr.String(), "aws_s3_bucket_public_access_block.audit_logs block_public_acls")
+
+	data, err := os.ReadFile(hcl)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `module "legacy" {`)
+	assert.Contains(t, string(data), `auditledger_role_arns  = ["`+writer+`"]`)
+	assert.Contains(t, string(data), "to = module.legacy.aws_s3_bucket_object_lock_configuration.audit_logs")
+	assert.NotContains(t, string(data), "aws_s3_bucket_public_access_block", "There is no public access block to import")
+}
+
+func TestRunS3Blocked(t *testing.T) {
+	endpoint := s3Endpoint(t, map[string]string{"policy": lockedBucket["policy"]})
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitError, run([]string{"s3", "-endpoint", endpoint, "-bucket", "audit-logs", "-format", "json"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "created without Object Lock")
+	assert.Empty(t, stdout.String(), "No configuration is written for an unsafe adoption")
+}
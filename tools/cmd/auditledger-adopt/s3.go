@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/adopt"
+	"github.com/auditledger/auditledger-terraform/tools/internal/report"
+)
+
+var moduleName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// awsFlags configure the AWS session; -endpoint targets LocalStack or another S3-compatible API
+type awsFlags struct {
+	region   string
+	endpoint string
+}
+
+func (f *awsFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.region, "region", envOrDefault("AWS_DEFAULT_REGION", "us-east-1"), "AWS region")
+	fs.StringVar(&f.endpoint, "endpoint", os.Getenv("AWS_ENDPOINT_URL"), "Custom endpoint, e.g. http://localhost:4566 for LocalStack")
+}
+
+func (f *awsFlags) session() (*session.Session, error) {
+	config := aws.NewConfig().WithRegion(f.region)
+	if f.endpoint != "" {
+		config = config.WithEndpoint(f.endpoint).WithS3ForcePathStyle(true)
+	}
+	return session.NewSessionWithOptions(session.Options{
+		Config:            *config,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+}
+
+func runS3(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("s3", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var awsConfig awsFlags
+	awsConfig.register(fs)
+
+	bucket := fs.String("bucket", "", "Existing bucket to adopt (required)")
+	name := fs.String("name", "audit_logs", "Name of the generated module block")
+	source := fs.String("source", adopt.DefaultSource, "Module source in the generated configuration")
+	roleARNs := fs.String("auditledger-role-arns", "", "Comma-separated writer role ARNs, instead of the principals the bucket policy allows to write")
+	hcl := fs.String("hcl", "", "Write the generated configuration to this file instead of stdout")
+	format := fs.String("format", "text", "Report format: "+strings.Join(report.Formats, ", "))
+	out := fs.String("out", "", "Write the report to this file instead of stderr")
+
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if *bucket == "" {
+		fmt.Fprintln(stderr, "-bucket is required")
+		return exitError
+	}
+	if !moduleName.MatchString(*name) {
+		fmt.Fprintf(stderr, "-name %q is not a valid Terraform module name\n", *name)
+		return exitError
+	}
+
+	var options adopt.Options
+	for _, arn := range strings.Split(*roleARNs, ",") {
+		if arn = strings.TrimSpace(arn); arn != "" {
+			options.AuditLedgerRoleARNs = append(options.AuditLedgerRoleARNs, arn)
+		}
+	}
+
+	sess, err := awsConfig.session()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	inspector := &adopt.Inspector{S3: s3.New(sess)}
+	b, err := inspector.Inspect(context.Background(), *bucket)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	a := adopt.Plan(b, options)
+
+	reportTo := stderr
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		defer file.Close()
+		reportTo = file
+	}
+	if err := a.Report().Write(reportTo, *format); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	// Never hand out configuration whose apply would replace the bucket
+	if len(a.Blockers) > 0 {
+		return exitError
+	}
+
+	if err := writeHCL(a, *hcl, *name, *source, stdout); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	if len(a.Changes()) > 0 {
+		return exitChanges
+	}
+	return exitOK
+}
+
+func writeHCL(a *adopt.Adoption, path, name, source string, stdout io.Writer) error {
+	if path == "" {
+		return a.WriteHCL(stdout, name, source)
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := a.WriteHCL(file, name, source); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func envOrDefault(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
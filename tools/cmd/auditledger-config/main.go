@@ -0,0 +1,70 @@
+// Command auditledger-config renders the AuditLedger application storage configuration from
+// the outputs of the S3 or Azure Blob module.
+//
+// It reads the app_configuration output from `terraform output -json` or a state file, checks
+// it against the module's immutability, encryption and replication outputs (the lock mode and
+// encryption headers the bucket policy requires, a key prefix outside the reserved and
+// unreplicated prefixes) and writes it as appsettings JSON, YAML or environment variables. The
+// exit code is 0 when the configuration is valid, 1 when it is not and 2 on usage or input
+// errors.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitError   = 2
+)
+
+type command struct {
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+var commands = map[string]command{
+	"check":  {"Validate the app_configuration output without rendering it", runCheck},
+	"render": {"Validate the app_configuration output and write it as JSON, YAML or env", runRender},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		usage(stderr)
+		return exitError
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		usage(stderr)
+		return exitError
+	}
+	return cmd.run(args[1:], stdout, stderr)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: auditledger-config <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-14s %s\n", name, commands[name].summary)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'auditledger-config <command> -h' for the flags of a command.")
+}
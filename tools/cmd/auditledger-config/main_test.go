@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitError, run(nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "render")
+
+	stderr.Reset()
+	assert.Equal(t, exitError, run([]string{"apply"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), `unknown command "apply"`)
+}
+
+func TestRunRenderEnv(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	code := run([]string{"render", "-outputs", "testdata/s3_outputs.json", "-format", "env"}, &stdout, &stderr)
+	require.Equal(t, exitOK, code, stderr.String())
+	assert.True(t, strings.HasPrefix(stdout.String(), "AuditLedger__Storage__AwsS3__BucketName='prod-audit-logs'\n"), stdout.String())
+	assert.Contains(t, stdout.String(), "AuditLedger__Storage__AwsS3__RequiredHeaders__0__Value='COMPLIANCE'\n")
+}
+
+func TestRunRenderNestedOutputs(t *testing.T) {
+	data, err := os.ReadFile("testdata/s3_outputs.json")
+	require.NoError(t, err)
+
+	// A root module exposing auditledger-storage as output "audit_storage"
+	var module map[string]struct {
+		Value json.RawMessage `json:"value"`
+	}
+	require.NoError(t, json.Unmarshal(data, &module))
+	aws := map[string]json.RawMessage{}
+	for name, output := range module {
+		aws[name] = output.Value
+	}
+	root, err := json.Marshal(map[string]interface{}{"audit_storage": map[string]interface{}{"value": map[string]interface{}{"aws": aws}}})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "outputs.json")
+	require.NoError(t, os.WriteFile(path, root, 0o600))
+	out := filepath.Join(t.TempDir(), "appsettings.json")
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"render", "-outputs", path, "-output-key", "audit_storage.aws", "-out", out}, &stdout, &stderr)
+	require.Equal(t, exitOK, code, stderr.String())
+
+	rendered, err := os.ReadFile(out)
+	require.NoError(t, err)
+	assert.Contains(t, string(rendered), `"BucketName": "prod-audit-logs"`)
+	assert.Empty(t, stdout.String())
+}
+
+func TestRunRenderInvalid(t *testing.T) {
+	data, err := os.ReadFile("testdata/s3_outputs.json")
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "outputs.json")
+	require.NoError(t, os.WriteFile(path, bytes.Replace(data, []byte(`"mode": "COMPLIANCE"`), []byte(`"mode": "GOVERNANCE"`), 1), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitInvalid, run([]string{"render", "-outputs", path}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), `is not the bucket lock mode "GOVERNANCE"`)
+	assert.Empty(t, stdout.String())
+
+	stderr.Reset()
+	assert.Equal(t, exitInvalid, run([]string{"check", "-outputs", path}, &stdout, &stderr))
+}
+
+func TestRunCheck(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	require.Equal(t, exitOK, run([]string{"check", "-outputs", "testdata/s3_outputs.json"}, &stdout, &stderr), stderr.String())
+	assert.Equal(t, "AwsS3 application configuration is valid\n", stdout.String())
+
+	stderr.Reset()
+	assert.Equal(t, exitError, run([]string{"check", "-outputs", "testdata/missing.json"}, &stdout, &stderr))
+	assert.Equal(t, exitError, run([]string{"render", "-outputs", "testdata/s3_outputs.json", "-format", "xml"}, &stdout, &stderr))
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/appconfig"
+	"github.com/auditledger/auditledger-terraform/tools/internal/tfoutputs"
+)
+
+// outputFlags select the module outputs with the app_configuration output
+type outputFlags struct {
+	path string
+	key  string
+}
+
+func (f *outputFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.path, "outputs", "-", "`terraform output -json` file or terraform.tfstate with the module outputs (- for stdin)")
+	fs.StringVar(&f.key, "output-key", "", "Root output that holds the module's outputs as an object; separate nested outputs with dots, e.g. audit_storage.aws")
+}
+
+// load reads the module outputs and validates their app_configuration. Invalid settings are
+// returned along with the *appconfig.ValidationError
+func (f *outputFlags) load() (*appconfig.Storage, error) {
+	outputs, err := tfoutputs.Load(f.path)
+	if err != nil {
+		return nil, err
+	}
+	if outputs, err = outputs.Path(f.key); err != nil {
+		return nil, err
+	}
+	return appconfig.FromOutputs(outputs)
+}
+
+// exitCode reports a load error and maps it to the exit code
+func exitCode(err error, stderr io.Writer) int {
+	fmt.Fprintln(stderr, err)
+	var validation *appconfig.ValidationError
+	if errors.As(err, &validation) {
+		return exitInvalid
+	}
+	return exitError
+}
+
+func runRender(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("render", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var outputs outputFlags
+	outputs.register(fs)
+	format := fs.String("format", "json", "Output format: "+strings.Join(appconfig.Formats, ", "))
+	out := fs.String("out", "", "Write the configuration to this file instead of stdout")
+
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	storage, err := outputs.load()
+	if err != nil {
+		// Invalid settings are not rendered: an application started with them could not write
+		return exitCode(err, stderr)
+	}
+
+	w := stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := storage.Write(w, *format); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	return exitOK
+}
+
+func runCheck(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var outputs outputFlags
+	outputs.register(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	storage, err := outputs.load()
+	if err != nil {
+		return exitCode(err, stderr)
+	}
+	fmt.Fprintf(stdout, "%s application configuration is valid\n", storage.Provider)
+	return exitOK
+}
@@ -0,0 +1,212 @@
+// Command auditledger-cost forecasts how much audit data the AuditLedger modules keep in each
+// storage tier over the retention period, and what it costs.
+//
+// It reads the lifecycle configuration and retention of every auditledger-s3 and
+// auditledger-azure-blob instance from `terraform show -json`, ingests a daily volume for every
+// day of the forecast and prices the tiers month by month, including the charges for objects
+// that leave a tier before its minimum storage duration. -transitions prices a different
+// lifecycle before the module is changed. No network access or cloud credentials are needed.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/forecast"
+	"github.com/auditledger/auditledger-terraform/tools/internal/tfstate"
+)
+
+const (
+	exitOK    = 0
+	exitError = 2
+)
+
+type command struct {
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+var commands = map[string]command{
+	"forecast": {"Simulate storage tier occupancy and cost from `terraform show -json` for a plan", runForecast},
+	"prices":   {"Print the price table with per-tier minimum storage durations", runPrices},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		usage(stderr)
+		return exitError
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		usage(stderr)
+		return exitError
+	}
+	return cmd.run(args[1:], stdout, stderr)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: auditledger-cost <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].summary)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'auditledger-cost <command> -h' for the flags of a command.")
+}
+
+func runForecast(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("forecast", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	planPath := fs.String("plan", "", "`terraform show -json` output for a saved plan or the state (- for stdin)")
+	dailyGB := fs.Float64("daily-gb", 0, "Expected audit data ingested per day, in GB")
+	days := fs.Int("days", 0, "Days to simulate (default: each instance's retention period)")
+	module := fs.String("module", "", "Only forecast this module address, e.g. module.audit_logs")
+	transitions := fs.String("transitions", "", "Replace the planned transitions, e.g. STANDARD_IA=30,GLACIER=180 ('none' keeps objects in the initial tier)")
+	retentionDays := fs.Int("retention-days", 0, "Replace the planned retention period")
+	deleteAfterDays := fs.Int("delete-after-days", -1, "Replace the planned deletion age (0 keeps objects after retention)")
+	pricesPath := fs.String("prices", "", "Price table JSON that adds to or overrides the built-in prices")
+	format := fs.String("format", "text", "Output format: "+strings.Join(forecast.Formats, ", "))
+
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if *planPath == "" {
+		fmt.Fprintln(stderr, "-plan is required (terraform show -json plan.out > plan.json)")
+		return exitError
+	}
+	if *dailyGB <= 0 {
+		fmt.Fprintln(stderr, "-daily-gb is required and must be positive")
+		return exitError
+	}
+
+	var override []forecast.Transition
+	if *transitions != "" && *transitions != "none" {
+		var err error
+		if override, err = forecast.ParseTransitions(*transitions); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+	}
+
+	prices := forecast.DefaultPrices()
+	if *pricesPath != "" {
+		custom, err := forecast.LoadPrices(*pricesPath)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		prices = prices.Merge(custom)
+	}
+
+	show, _, err := tfstate.Load(*planPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	lifecycles, err := forecast.FromPlan(show)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	var forecasts []*forecast.Forecast
+	for _, l := range lifecycles {
+		if *module != "" && l.Module != *module {
+			continue
+		}
+		if *transitions != "" {
+			l.SetTransitions(override)
+		}
+		if *retentionDays > 0 {
+			l.RetentionDays = *retentionDays
+		}
+		if *deleteAfterDays >= 0 {
+			l.DeleteAfterDays = *deleteAfterDays
+		}
+
+		horizon := *days
+		if horizon == 0 {
+			horizon = l.RetentionDays
+		}
+		if horizon == 0 {
+			fmt.Fprintf(stderr, "%s has no retention period in the plan - set -days\n", l.Module)
+			return exitError
+		}
+
+		f, err := forecast.Simulate(l, prices, *dailyGB, horizon)
+		if err != nil {
+			fmt.Fprintf(stderr, "%s: %v\n", l.Module, err)
+			return exitError
+		}
+		forecasts = append(forecasts, f)
+	}
+	if len(forecasts) == 0 {
+		fmt.Fprintf(stderr, "no module %q in the plan\n", *module)
+		return exitError
+	}
+
+	if err := forecast.Write(stdout, forecasts, *format); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	return exitOK
+}
+
+func runPrices(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("prices", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	pricesPath := fs.String("prices", "", "Price table JSON that adds to or overrides the built-in prices")
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	prices := forecast.DefaultPrices()
+	if *pricesPath != "" {
+		custom, err := forecast.LoadPrices(*pricesPath)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		prices = prices.Merge(custom)
+	}
+
+	if prices.Source != "" {
+		fmt.Fprintf(stdout, "%s\n\n", prices.Source)
+	}
+	providers := make([]string, 0, len(prices.Providers))
+	for provider := range prices.Providers {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	for _, provider := range providers {
+		fmt.Fprintf(stdout, "%s\n", provider)
+		for _, tier := range prices.Tiers(provider) {
+			price := prices.Providers[provider][tier]
+			fmt.Fprintf(stdout, "  %-20s %9.5f %s/GB-month", tier, price.GBMonth, prices.Currency)
+			if price.MinimumDays > 0 {
+				fmt.Fprintf(stdout, ", billed for at least %d days", price.MinimumDays)
+			}
+			fmt.Fprintln(stdout)
+		}
+	}
+	return exitOK
+}
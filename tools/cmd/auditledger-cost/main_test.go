@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const plan = "../../internal/forecast/testdata/plan.json"
+
+func TestRunUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitError, run(nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "forecast")
+
+	stderr.Reset()
+	assert.Equal(t, exitError, run([]string{"estimate"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), `unknown command "estimate"`)
+}
+
+func TestRunForecastRequiresVolume(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitError, run([]string{"forecast", "-plan", plan}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "-daily-gb is required")
+}
+
+func TestRunForecast(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	code := run([]string{"forecast", "-plan", plan, "-daily-gb", "10"}, &stdout, &stderr)
+	require.Equal(t, exitOK, code, stderr.String())
+	assert.Contains(t, stdout.String(), "module.audit_logs acme-audit-logs (aws): 10 GB/day for 2555 days")
+	assert.Contains(t, stdout.String(), "STANDARD GB STANDARD_IA GB GLACIER_IR GB GLACIER GB")
+	assert.Contains(t, stdout.String(), "module.azure_logs acmeauditlogs (azure): 10 GB/day for 365 days")
+}
+
+func TestRunForecastWhatIf(t *testing.T) {
+	var planned, whatIf, stderr bytes.Buffer
+
+	require.Equal(t, exitOK, run([]string{"forecast", "-plan", plan, "-module", "module.audit_logs", "-daily-gb", "10", "-format", "json"}, &planned, &stderr), stderr.String())
+	require.Equal(t, exitOK, run([]string{"forecast", "-plan", plan, "-module", "module.audit_logs", "-daily-gb", "10", "-transitions", "GLACIER=30", "-format", "json"}, &whatIf, &stderr), stderr.String())
+
+	var before, after []struct {
+		Cost      float64 `json:"cost"`
+		Lifecycle struct {
+			Transitions []struct{ Days int } `json:"transitions"`
+		} `json:"lifecycle"`
+	}
+	require.NoError(t, json.Unmarshal(planned.Bytes(), &before))
+	require.NoError(t, json.Unmarshal(whatIf.Bytes(), &after))
+	require.Len(t, before, 1, "-module selects one instance")
+	require.Len(t, after, 1)
+	assert.Len(t, before[0].Lifecycle.Transitions, 3)
+	assert.Len(t, after[0].Lifecycle.Transitions, 1)
+	assert.Less(t, after[0].Cost, before[0].Cost, "Moving to Glacier after 30 days is cheaper")
+
+	var stdout bytes.Buffer
+	assert.Equal(t, exitError, run([]string{"forecast", "-plan", plan, "-daily-gb", "10", "-transitions", "TAPE=30"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), `no aws price for tier "TAPE"`)
+}
+
+func TestRunPrices(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"currency": "EUR", "providers": {"aws": {"GLACIER": {"gb_month": 0.003, "minimum_days": 90}}}}`), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitOK, run([]string{"prices", "-prices", path}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "  GLACIER                0.00300 EUR/GB-month, billed for at least 90 days")
+	assert.Contains(t, stdout.String(), "  Hot                    0.01840 EUR/GB-month\n")
+}
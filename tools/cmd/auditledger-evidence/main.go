@@ -0,0 +1,234 @@
+// Command auditledger-evidence builds signed compliance evidence bundles for auditors.
+//
+// It reads `terraform show -json` for configurations that use the auditledger-s3 or
+// auditledger-azure-blob modules and writes a zip with the rendered configuration of
+// each bucket or storage account, mapped to SOC 2, HIPAA and PCI DSS controls, a
+// Markdown and HTML summary, and a manifest signed with Ed25519.
+package main
+
+import (
+	"crypto/ed25519"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/evidence"
+	"github.com/auditledger/auditledger-terraform/tools/internal/signing"
+	"github.com/auditledger/auditledger-terraform/tools/internal/tfstate"
+)
+
+const (
+	exitOK      = 0
+	exitInvalid = 1
+	exitError   = 2
+)
+
+type command struct {
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+var commands = map[string]command{
+	"generate": {"Build a signed evidence bundle from `terraform show -json`", runGenerate},
+	"verify":   {"Check the signature and file digests of an evidence bundle", runVerify},
+	"keygen":   {"Create an Ed25519 signing key pair", runKeygen},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		usage(stderr)
+		return exitError
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		usage(stderr)
+		return exitError
+	}
+	return cmd.run(args[1:], stdout, stderr)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: auditledger-evidence <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].summary)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'auditledger-evidence <command> -h' for the flags of a command.")
+}
+
+func runGenerate(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("generate", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	statePath := fs.String("state", "", "`terraform show -json` output for the state or a saved plan (- for stdin)")
+	out := fs.String("out", "", "Bundle to write, e.g. evidence-2026.zip")
+	keyPath := fs.String("signing-key", os.Getenv("AUDITLEDGER_EVIDENCE_SIGNING_KEY"), "Ed25519 private key (PKCS#8 PEM) that signs the manifest")
+	controlsPath := fs.String("controls", "", "Additional control mapping (JSON) - frameworks with the same id replace the built-in ones")
+	frameworks := fs.String("frameworks", "", "Comma-separated frameworks to include (default: all)")
+
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if *statePath == "" || *out == "" || *keyPath == "" {
+		fmt.Fprintln(stderr, "-state, -out and -signing-key are required (create a key with 'auditledger-evidence keygen')")
+		return exitError
+	}
+
+	key, err := signing.LoadPrivateKey(*keyPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	mapping := evidence.DefaultMapping()
+	if *controlsPath != "" {
+		custom, err := evidence.LoadMapping(*controlsPath)
+		if err == nil {
+			err = mapping.Merge(custom)
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+	}
+	if err := mapping.Select(splitList(*frameworks)); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	show, data, err := tfstate.Load(*statePath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	targets, err := evidence.Collect(show, mapping)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	pack := &evidence.Pack{
+		GeneratedAt: time.Now(),
+		Source:      evidence.NewSource(*statePath, data, show.TerraformVersion),
+		Mapping:     mapping,
+		Targets:     targets,
+	}
+
+	file, err := os.Create(*out)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	manifest, err := evidence.WriteBundle(file, pack, key)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	fmt.Fprintf(stdout, "Wrote %s: %d targets, %d evidence files, frameworks %s\n", *out, len(targets), len(manifest.Evidence), strings.Join(manifest.Frameworks, ", "))
+	fmt.Fprintf(stdout, "Signed with %s\n", manifest.SigningKeyFingerprint)
+	return exitOK
+}
+
+func runVerify(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("verify", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	bundlePath := fs.String("bundle", "", "Evidence bundle to verify")
+	publicKeyPath := fs.String("public-key", "", "Signer's Ed25519 public key (PKIX PEM); without it only integrity is checked")
+
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if *bundlePath == "" {
+		fmt.Fprintln(stderr, "-bundle is required")
+		return exitError
+	}
+
+	var public ed25519.PublicKey
+	if *publicKeyPath != "" {
+		var err error
+		if public, err = signing.LoadPublicKey(*publicKeyPath); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+	}
+
+	data, err := os.ReadFile(*bundlePath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	manifest, err := evidence.VerifyBundle(data, public)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: INVALID: %v\n", *bundlePath, err)
+		return exitInvalid
+	}
+
+	fmt.Fprintf(stdout, "%s: valid, %d files signed by %s at %s\n", *bundlePath, len(manifest.Files), manifest.SigningKeyFingerprint, manifest.GeneratedAt.Format(time.RFC3339))
+	if public == nil {
+		fmt.Fprintln(stdout, "warning: checked against the key embedded in the bundle - pass -public-key to prove who signed it")
+	}
+	return exitOK
+}
+
+func runKeygen(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("keygen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	out := fs.String("out", "evidence-signing-key", "Key file prefix: writes <prefix>.pem and <prefix>.pub.pem")
+
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	private, public, err := signing.GenerateKey()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	if err := os.WriteFile(*out+".pem", private, 0o600); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	if err := os.WriteFile(*out+".pub.pem", public, 0o644); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	fmt.Fprintf(stdout, "Wrote %s.pem (keep secret) and %s.pub.pem (give to auditors)\n", *out, *out)
+	return exitOK
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixture = "../../internal/evidence/testdata/show.json"
+
+func TestRunUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitError, run(nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "generate")
+
+	stderr.Reset()
+	assert.Equal(t, exitError, run([]string{"generate", "-state", fixture}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "-signing-key are required")
+}
+
+func TestGenerateAndVerify(t *testing.T) {
+	dir := t.TempDir()
+	key := filepath.Join(dir, "signing")
+	other := filepath.Join(dir, "other")
+	bundle := filepath.Join(dir, "evidence.zip")
+
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitOK, run([]string{"keygen", "-out", key}, &stdout, &stderr), stderr.String())
+	require.Equal(t, exitOK, run([]string{"keygen", "-out", other}, &stdout, &stderr), stderr.String())
+
+	stdout.Reset()
+	require.Equal(t, exitOK, run([]string{
+		"generate", "-state", fixture, "-out", bundle, "-signing-key", key + ".pem", "-frameworks", "soc2, pci_dss",
+	}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "2 targets, 16 evidence files, frameworks soc2, pci_dss")
+
+	stdout.Reset()
+	assert.Equal(t, exitOK, run([]string{"verify", "-bundle", bundle, "-public-key", key + ".pub.pem"}, &stdout, &stderr))
+	assert.Contains(t, stdout.String(), "valid")
+	assert.NotContains(t, stdout.String(), "warning")
+
+	stderr.Reset()
+	assert.Equal(t, exitInvalid, run([]string{"verify", "-bundle", bundle, "-public-key", other + ".pub.pem"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "INVALID")
+}
+
+func TestGenerateUnknownFramework(t *testing.T) {
+	dir := t.TempDir()
+	var stdout, stderr bytes.Buffer
+	require.Equal(t, exitOK, run([]string{"keygen", "-out", filepath.Join(dir, "key")}, &stdout, &stderr))
+
+	assert.Equal(t, exitError, run([]string{
+		"generate", "-state", fixture, "-out", filepath.Join(dir, "e.zip"), "-signing-key", filepath.Join(dir, "key.pem"), "-frameworks", "nist",
+	}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), `unknown framework "nist"`)
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/signing"
+)
+
+func TestRunUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitError, run(nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "write")
+
+	stderr.Reset()
+	assert.Equal(t, exitError, run([]string{"seal"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), `unknown command "seal"`)
+}
+
+func TestRunWriteRequiresStore(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitError, run([]string{"write", "-signing-key", "key.pem"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "no bucket_id or container_name output")
+}
+
+func TestRunWriteRequiresSigner(t *testing.T) {
+	t.Setenv("AUDITLEDGER_MANIFEST_SIGNING_KEY", "")
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitError, run([]string{"write", "-bucket", "audit-logs"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "-signing-key or -kms-key-id is required")
+}
+
+type blob struct {
+	name, version, modified string
+	body                    []byte
+}
+
+// blobService is a versioned Blob service container; Last-Modified has whole seconds like Azure
+type blobService struct {
+	mu    sync.Mutex
+	blobs []*blob
+	next  int
+}
+
+func (b *blobService) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer storage-token" {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+	name := strings.TrimPrefix(r.URL.Path, "/audit-logs/")
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Get("comp") == "list":
+		prefix := r.URL.Query().Get("prefix")
+		fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><EnumerationResults><Blobs>`)
+		for _, item := range b.blobs {
+			if strings.HasPrefix(item.name, prefix) {
+				fmt.Fprintf(w, `<Blob><Name>%s</Name><VersionId>%s</VersionId><Properties><Last-Modified>%s</Last-Modified><Content-Length>%d</Content-Length></Properties></Blob>`,
+					item.name, item.version, item.modified, len(item.body))
+			}
+		}
+		fmt.Fprint(w, `</Blobs><NextMarker /></EnumerationResults>`)
+	case r.Method == http.MethodGet:
+		for _, item := range b.blobs {
+			if item.name == name && item.version == r.URL.Query().Get("versionid") {
+				_, _ = w.Write(item.body)
+				return
+			}
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		version := b.put(name, body, time.Now())
+		w.Header().Set("x-ms-version-id", version)
+		w.WriteHeader(http.StatusCreated)
+	}
+}
+
+func (b *blobService) put(name string, body []byte, modified time.Time) string {
+	b.next++
+	version := fmt.Sprintf("2026-01-15T09:00:00.%07dZ", b.next)
+	b.blobs = append(b.blobs, &blob{name: name, version: version, modified: modified.UTC().Format(http.TimeFormat), body: body})
+	return version
+}
+
+func TestRunWriteAndVerifyChainAzure(t *testing.T) {
+	service := &blobService{}
+	server := httptest.NewServer(service)
+	defer server.Close()
+	t.Setenv("AZURE_STORAGE_ACCESS_TOKEN", "storage-token")
+
+	dir := t.TempDir()
+	private, public, err := signing.GenerateKey()
+	require.NoError(t, err)
+	keyPath := filepath.Join(dir, "key.pem")
+	publicKeyPath := filepath.Join(dir, "key.pub.pem")
+	require.NoError(t, os.WriteFile(keyPath, private, 0o600))
+	require.NoError(t, os.WriteFile(publicKeyPath, public, 0o600))
+
+	outputsPath := filepath.Join(dir, "outputs.json")
+	require.NoError(t, os.WriteFile(outputsPath, []byte(fmt.Sprintf(`{
+		"container_name": {"value": "audit-logs"},
+		"primary_blob_endpoint": {"value": %q},
+		"manifest_configuration": {"value": {"prefix": "_chain/"}}
+	}`, server.URL+"/")), 0o600))
+
+	service.put("logs/a.json", []byte(`{"event": "login"}`), time.Now().Add(-time.Hour))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"write", "-outputs", outputsPath, "-signing-key", keyPath}, &stdout, &stderr)
+	require.Equal(t, exitOK, code, stderr.String())
+	assert.Contains(t, stdout.String(), "sequence 1, 1 records")
+	assert.Regexp(t, `^_chain/00000000000000000001-\d{8}T\d{6}Z\.json$`, service.blobs[1].name, "The prefix comes from manifest_configuration")
+
+	stdout.Reset()
+	code = run([]string{"verify-chain", "-outputs", outputsPath, "-public-key", publicKeyPath}, &stdout, &stderr)
+	require.Equal(t, exitOK, code, stdout.String()+stderr.String())
+	assert.Contains(t, stdout.String(), "[PASS] chain: 1 manifests (sequence 1-1)")
+
+	service.blobs[0].body = []byte(`{"event": "logout"}`)
+	stdout.Reset()
+	code = run([]string{"verify-chain", "-outputs", outputsPath, "-public-key", publicKeyPath, "-format", "json"}, &stdout, &stderr)
+	assert.Equal(t, exitInvalid, code)
+	assert.Contains(t, stdout.String(), `"name": "record.altered"`)
+	assert.Contains(t, stdout.String(), "logs/a.json version 2026-01-15T09:00:00.0000001Z")
+}
+
+func TestRunVerifyChainRequiresKey(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitError, run([]string{"verify-chain", "-bucket", "audit-logs"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "-public-key or -kms-key-id is required")
+
+	stderr.Reset()
+	assert.Equal(t, exitError, run([]string{"verify-chain", "-bucket", "audit-logs", "-from", "yesterday"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "-from:")
+}
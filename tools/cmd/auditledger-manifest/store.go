@@ -0,0 +1,154 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/arm"
+	"github.com/auditledger/auditledger-terraform/tools/internal/manifest"
+	"github.com/auditledger/auditledger-terraform/tools/internal/tfoutputs"
+)
+
+// storeFlags select the bucket or container, either from module outputs or explicitly
+type storeFlags struct {
+	outputs   string
+	outputKey string
+	bucket    string
+	container string
+	endpoint  string
+	prefix    string
+	region    string
+	s3URL     string
+}
+
+func (f *storeFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.outputs, "outputs", "", "`terraform output -json` file or terraform.tfstate with the module outputs (- for stdin)")
+	fs.StringVar(&f.outputKey, "output-key", "", "Root output that holds the module's outputs as an object; separate nested outputs with dots, e.g. audit_storage.aws")
+	fs.StringVar(&f.bucket, "bucket", "", "S3 bucket (overrides the bucket_id output)")
+	fs.StringVar(&f.container, "container", "", "Azure container (overrides the container_name output)")
+	fs.StringVar(&f.endpoint, "blob-endpoint", "", "Azure primary blob endpoint (overrides the primary_blob_endpoint output)")
+	fs.StringVar(&f.prefix, "prefix", "", "Manifest prefix (default: manifest_configuration.prefix, or "+manifest.DefaultPrefix+")")
+	fs.StringVar(&f.region, "region", envOrDefault("AWS_DEFAULT_REGION", "us-east-1"), "AWS region")
+	fs.StringVar(&f.s3URL, "endpoint", os.Getenv("AWS_ENDPOINT_URL"), "Custom S3 endpoint, e.g. http://localhost:4566 for LocalStack or http://localhost:9000 for MinIO")
+}
+
+// manifestConfiguration mirrors the manifest_configuration output of both storage modules
+type manifestConfiguration struct {
+	Prefix           string `json:"prefix"`
+	SigningKMSKeyARN string `json:"signing_kms_key_arn"`
+}
+
+// target is the opened store with the settings the module outputs describe
+type target struct {
+	store   manifest.Store
+	prefix  string
+	config  manifestConfiguration
+	session *session.Session
+}
+
+func (f *storeFlags) open() (*target, error) {
+	outputs := tfoutputs.Outputs{}
+	if f.outputs != "" {
+		loaded, err := tfoutputs.Load(f.outputs)
+		if err == nil {
+			outputs, err = loaded.Path(f.outputKey)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	t := &target{}
+	if err := outputs.Decode("manifest_configuration", &t.config); err != nil {
+		return nil, err
+	}
+	t.prefix = firstNonEmpty(f.prefix, t.config.Prefix, manifest.DefaultPrefix)
+
+	config := aws.NewConfig().WithRegion(f.region)
+	if f.s3URL != "" {
+		config = config.WithEndpoint(f.s3URL).WithS3ForcePathStyle(true)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{Config: *config, SharedConfigState: session.SharedConfigEnable})
+	if err != nil {
+		return nil, err
+	}
+	t.session = sess
+
+	bucket := firstNonEmpty(f.bucket, outputs.String("bucket_id"))
+	container := firstNonEmpty(f.container, outputs.String("container_name"))
+	switch {
+	case bucket != "" && container != "":
+		return nil, errors.New("both a bucket and a container are set; pass the outputs of one module")
+	case bucket != "":
+		t.store, err = s3Store(sess, bucket, outputs)
+	case container != "":
+		t.store, err = azureStore(firstNonEmpty(f.endpoint, outputs.String("primary_blob_endpoint")), container)
+	default:
+		err = errors.New("no bucket_id or container_name output: pass -outputs with the module outputs, -bucket or -container")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// s3Store writes with the lock mode and encryption the module's bucket policy requires
+func s3Store(sess *session.Session, bucket string, outputs tfoutputs.Outputs) (*manifest.S3Store, error) {
+	var lock struct {
+		Mode          string `json:"mode"`
+		RetentionDays int    `json:"retention_days"`
+	}
+	var encryption struct {
+		SSEAlgorithm string `json:"sse_algorithm"`
+		KMSKeyID     string `json:"kms_key_id"`
+	}
+	if err := outputs.Decode("object_lock_configuration", &lock); err != nil {
+		return nil, err
+	}
+	if err := outputs.Decode("encryption_configuration", &encryption); err != nil {
+		return nil, err
+	}
+
+	return &manifest.S3Store{
+		API:           s3.New(sess),
+		Bucket:        bucket,
+		LockMode:      lock.Mode,
+		RetentionDays: lock.RetentionDays,
+		SSEAlgorithm:  encryption.SSEAlgorithm,
+		KMSKeyID:      encryption.KMSKeyID,
+	}, nil
+}
+
+// azureStore authenticates with AZURE_STORAGE_ACCESS_TOKEN if set, otherwise with the Azure CLI session
+func azureStore(endpoint, container string) (*manifest.AzureBlobStore, error) {
+	if endpoint == "" {
+		return nil, errors.New("no primary_blob_endpoint output: pass -blob-endpoint, e.g. https://acmeauditlogs.blob.core.windows.net/")
+	}
+
+	var token arm.TokenSource = &arm.CLIToken{Resource: arm.StorageResource}
+	if value := os.Getenv("AZURE_STORAGE_ACCESS_TOKEN"); value != "" {
+		token = arm.StaticToken(value)
+	}
+	return &manifest.AzureBlobStore{Endpoint: endpoint, Container: container, Token: token}, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func envOrDefault(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/kms"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/manifest"
+	"github.com/auditledger/auditledger-terraform/tools/internal/report"
+	"github.com/auditledger/auditledger-terraform/tools/internal/signing"
+)
+
+// passed describes each finding kind when the chain has none of it
+var passed = map[string]string{
+	manifest.FindingManifestUnexpected:  "only manifests are stored under the prefix",
+	manifest.FindingManifestInvalid:     "every manifest decodes and matches its Merkle root",
+	manifest.FindingManifestSignature:   "every manifest is signed by the configured key",
+	manifest.FindingManifestMissing:     "manifest sequence numbers have no gaps",
+	manifest.FindingManifestLink:        "every manifest links to the digest of its predecessor",
+	manifest.FindingManifestOverwritten: "no manifest was overwritten or hidden by a delete marker",
+	manifest.FindingRecordOutOfOrder:    "records are in upload order and inside their manifest's window",
+	manifest.FindingRecordDuplicate:     "no version is recorded twice",
+	manifest.FindingRecordMissing:       "every recorded version still exists",
+	manifest.FindingRecordAltered:       "every recorded version matches its digest",
+	manifest.FindingRecordUnrecorded:    "every version in the sealed period is recorded",
+}
+
+func runVerifyChain(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("verify-chain", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var storeConfig storeFlags
+	storeConfig.register(fs)
+
+	publicKeyPath := fs.String("public-key", "", "Signer's Ed25519 or ECDSA P-256 public key (PKIX PEM), e.g. from 'aws kms get-public-key'")
+	kmsKeyID := fs.String("kms-key-id", "", "KMS signing key whose public key verifies the chain (default: manifest_configuration.signing_kms_key_arn)")
+	from := fs.String("from", "", "Only re-digest versions written after this time (RFC 3339 or YYYY-MM-DD)")
+	to := fs.String("to", "", "Only re-digest versions written up to this time (RFC 3339 or YYYY-MM-DD)")
+	parallelism := fs.Int("parallelism", 8, "Objects to read at once")
+	format := fs.String("format", "text", "Report format: "+strings.Join(report.Formats, ", "))
+	out := fs.String("out", "", "Write the report to this file instead of stdout")
+
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if *parallelism < 1 {
+		fmt.Fprintln(stderr, "-parallelism must be at least 1")
+		return exitError
+	}
+
+	verifier := &manifest.ChainVerifier{Parallelism: *parallelism}
+	var err error
+	if verifier.From, err = parseTime(*from); err != nil {
+		fmt.Fprintln(stderr, "-from:", err)
+		return exitError
+	}
+	if verifier.To, err = parseTime(*to); err != nil {
+		fmt.Fprintln(stderr, "-to:", err)
+		return exitError
+	}
+
+	t, err := storeConfig.open()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	verifier.Store, verifier.Prefix = t.store, t.prefix
+
+	switch {
+	case *publicKeyPath != "":
+		if verifier.Verifier, err = signing.LoadVerifier(*publicKeyPath); err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+	case firstNonEmpty(*kmsKeyID, t.config.SigningKMSKeyARN) != "":
+		verifier.Verifier = &signing.KMSVerifier{API: kms.New(t.session), Key: firstNonEmpty(*kmsKeyID, t.config.SigningKMSKeyARN)}
+	default:
+		fmt.Fprintln(stderr, "-public-key or -kms-key-id is required")
+		return exitError
+	}
+
+	chain, err := verifier.Verify(context.Background())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	provider := "aws"
+	if _, ok := t.store.(*manifest.AzureBlobStore); ok {
+		provider = "azure"
+	}
+	r := chainReport(chain, provider, t.store.URI()+"/"+t.prefix)
+	w := stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := r.Write(w, *format); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	if !r.Passed() {
+		return exitInvalid
+	}
+	return exitOK
+}
+
+// chainReport lists every finding as a failed check, and each kind without findings as a passed one
+func chainReport(chain *manifest.ChainReport, provider, target string) *report.Report {
+	r := report.New(provider, target)
+	r.Tool = "auditledger-manifest"
+
+	if chain.Manifests == 0 {
+		r.Failf("chain", "no valid manifests - run 'auditledger-manifest write' first")
+	} else {
+		r.Passf("chain", "%d manifests (sequence %d-%d) sealed up to %s; %d records re-digested, %d newer versions await the next manifest",
+			chain.Manifests, chain.FirstSequence, chain.LastSequence, chain.SealedUntil.Format(time.RFC3339), chain.Records, chain.Pending)
+	}
+
+	byKind := map[string][]manifest.Finding{}
+	for _, finding := range chain.Findings {
+		byKind[finding.Kind] = append(byKind[finding.Kind], finding)
+	}
+	for _, kind := range manifest.FindingKinds {
+		if len(byKind[kind]) == 0 {
+			r.Passf(kind, "%s", passed[kind])
+			continue
+		}
+		for _, finding := range byKind[kind] {
+			r.Failf(kind, "%s", finding)
+		}
+	}
+	return r
+}
+
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", value)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/kms"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/manifest"
+	"github.com/auditledger/auditledger-terraform/tools/internal/signing"
+)
+
+func runWrite(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("write", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var storeConfig storeFlags
+	storeConfig.register(fs)
+
+	keyPath := fs.String("signing-key", os.Getenv("AUDITLEDGER_MANIFEST_SIGNING_KEY"), "Ed25519 private key (PKCS#8 PEM) that signs manifests (create one with 'auditledger-evidence keygen')")
+	kmsKeyID := fs.String("kms-key-id", "", "Asymmetric KMS key (ECC_NIST_P256) that signs manifests (default: manifest_configuration.signing_kms_key_arn)")
+	settleDelay := fs.Duration("settle-delay", 5*time.Minute, "Leave uploads this recent for the next manifest, so uploads still in flight are not missed")
+	parallelism := fs.Int("parallelism", 8, "Objects to digest at once")
+	interval := fs.Duration("interval", 0, "Keep running and write a manifest at this interval (default: write one and exit)")
+
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if *parallelism < 1 {
+		fmt.Fprintln(stderr, "-parallelism must be at least 1")
+		return exitError
+	}
+
+	t, err := storeConfig.open()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	signer, err := newSigner(*keyPath, firstNonEmpty(*kmsKeyID, t.config.SigningKMSKeyARN), t)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	writer := &manifest.Writer{
+		Store:       t.store,
+		Signer:      signer,
+		Prefix:      t.prefix,
+		SettleDelay: *settleDelay,
+		Parallelism: *parallelism,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	for {
+		if code := writeOnce(ctx, writer, stdout, stderr); code != exitOK || *interval <= 0 {
+			return code
+		}
+
+		select {
+		case <-ctx.Done():
+			return exitOK
+		case <-time.After(*interval):
+		}
+	}
+}
+
+func writeOnce(ctx context.Context, writer *manifest.Writer, stdout, stderr io.Writer) int {
+	result, err := writer.Write(ctx)
+	if errors.Is(err, manifest.ErrNothingToSeal) {
+		fmt.Fprintf(stdout, "Nothing to seal: %v\n", err)
+		return exitOK
+	}
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	m := result.Manifest
+	fmt.Fprintf(stdout, "Wrote %s (version %s): sequence %d, %d records up to %s, root %s\n",
+		result.Key, result.VersionID, m.Sequence, m.RecordCount, m.Window.To.Format(time.RFC3339), m.MerkleRoot)
+	return exitOK
+}
+
+// newSigner prefers a local key when both are configured, so a key file overrides the module default
+func newSigner(keyPath, kmsKeyID string, t *target) (signing.Signer, error) {
+	switch {
+	case keyPath != "":
+		key, err := signing.LoadPrivateKey(keyPath)
+		if err != nil {
+			return nil, err
+		}
+		return signing.Ed25519Signer{Key: key}, nil
+	case kmsKeyID != "":
+		return signing.KMSSigner{API: kms.New(t.session), Key: kmsKeyID}, nil
+	default:
+		return nil, errors.New("-signing-key or -kms-key-id is required")
+	}
+}
@@ -0,0 +1,152 @@
+// Command auditledger-policy checks Terraform plans that use the AuditLedger modules for
+// unsafe settings that generic scanners cannot judge.
+//
+// It reads `terraform show -json` for a saved plan, runs the built-in rule pack against every
+// auditledger-s3 and auditledger-azure-blob instance, and exits non-zero when a rule at or
+// above the -fail-on severity fails. No network access or cloud credentials are needed.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/policy"
+	"github.com/auditledger/auditledger-terraform/tools/internal/tfstate"
+)
+
+const (
+	exitOK        = 0
+	exitViolation = 1
+	exitError     = 2
+)
+
+type command struct {
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+var commands = map[string]command{
+	"check": {"Evaluate the rule pack against `terraform show -json` for a plan", runCheck},
+	"rules": {"List the rules with their severity and remediation", runRules},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		usage(stderr)
+		return exitError
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		usage(stderr)
+		return exitError
+	}
+	return cmd.run(args[1:], stdout, stderr)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: auditledger-policy <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-10s %s\n", name, commands[name].summary)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'auditledger-policy <command> -h' for the flags of a command.")
+}
+
+func runCheck(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	defaults := policy.DefaultConfig()
+	planPath := fs.String("plan", "", "`terraform show -json` output for a saved plan or the state (- for stdin)")
+	profile := fs.String("profile", "", "Compliance profile every module instance must meet: "+strings.Join(policy.ProfileNames(), ", "))
+	environmentTag := fs.String("environment-tag", defaults.EnvironmentTag, "Tag that names the environment")
+	productionValues := fs.String("production-values", strings.Join(defaults.ProductionValues, ","), "Comma-separated environment tag values treated as production")
+	skip := fs.String("skip", "", "Comma-separated rule IDs not to evaluate")
+	failOn := fs.String("fail-on", string(policy.Medium), "Lowest severity that fails the check: low, medium or high")
+	format := fs.String("format", "text", "Output format: "+strings.Join(policy.Formats, ", "))
+
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if *planPath == "" {
+		fmt.Fprintln(stderr, "-plan is required (terraform show -json plan.out > plan.json)")
+		return exitError
+	}
+	threshold, err := policy.ParseSeverity(*failOn)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	show, _, err := tfstate.Load(*planPath)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	evaluation, err := policy.Evaluate(show, policy.Config{
+		Profile:          *profile,
+		EnvironmentTag:   *environmentTag,
+		ProductionValues: splitList(*productionValues),
+		Skip:             splitList(*skip),
+	})
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	evaluation.Plan = *planPath
+
+	if err := evaluation.Write(stdout, *format, threshold); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	if len(evaluation.Violations(threshold)) > 0 {
+		return exitViolation
+	}
+	return exitOK
+}
+
+func runRules(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("rules", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	for _, rule := range policy.Rules {
+		fmt.Fprintf(stdout, "%s %s (%s, %s)\n", rule.ID, rule.Name, rule.Severity, strings.Join(rule.Providers, ", "))
+		fmt.Fprintf(stdout, "    %s\n", rule.Description)
+		fmt.Fprintf(stdout, "    fix: %s\n\n", rule.Remediation)
+	}
+	fmt.Fprintf(stdout, "Waive rules for one bucket or storage account with the %s tag, e.g. %s = \"AL004 AL005\"\n", policy.SkipTag, policy.SkipTag)
+	return exitOK
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const fixture = "../../internal/policy/testdata/plan.json"
+
+func TestRunUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitError, run(nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "check")
+
+	stderr.Reset()
+	assert.Equal(t, exitError, run([]string{"check"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "-plan is required")
+
+	stderr.Reset()
+	assert.Equal(t, exitError, run([]string{"check", "-plan", fixture, "-fail-on", "critical"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), `unknown severity "critical"`)
+}
+
+func TestCheckExitCodes(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitViolation, run([]string{"check", "-plan", fixture}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "FAILED: 6 violations at or above medium severity")
+
+	// Only the medium-severity AL005 finding remains
+	skip := "AL001,AL002,AL003,AL006,AL007"
+
+	stdout.Reset()
+	assert.Equal(t, exitViolation, run([]string{"check", "-plan", fixture, "-format", "json", "-skip", skip}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), `"violations": 1`)
+
+	stdout.Reset()
+	assert.Equal(t, exitOK, run([]string{"check", "-plan", fixture, "-fail-on", "high", "-skip", skip}, &stdout, &stderr), stderr.String())
+	assert.Contains(t, stdout.String(), "[WARN] AL005")
+	assert.Contains(t, stdout.String(), "PASSED: 0 violations")
+}
+
+func TestRulesListsEveryRule(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitOK, run([]string{"rules"}, &stdout, &stderr))
+	for _, id := range []string{"AL001", "AL002", "AL003", "AL004", "AL005", "AL006", "AL007"} {
+		assert.Contains(t, stdout.String(), id)
+	}
+	assert.Contains(t, stdout.String(), "AuditLedgerPolicySkip")
+}
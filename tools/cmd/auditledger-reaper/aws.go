@@ -0,0 +1,123 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/reaper"
+	"github.com/auditledger/auditledger-terraform/tools/internal/report"
+)
+
+// awsFlags configure the AWS session; -endpoint targets LocalStack or another S3-compatible API
+type awsFlags struct {
+	region   string
+	endpoint string
+	roleARN  string
+}
+
+func (f *awsFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.region, "region", envOrDefault("AWS_DEFAULT_REGION", "us-east-1"), "AWS region")
+	fs.StringVar(&f.endpoint, "endpoint", os.Getenv("AWS_ENDPOINT_URL"), "Custom endpoint, e.g. http://localhost:4566 for LocalStack")
+	fs.StringVar(&f.roleARN, "role-arn", "", "Role to assume for the deletes, e.g. one of the buckets' governance_bypass_role_arns")
+}
+
+func (f *awsFlags) session() (*session.Session, error) {
+	config := aws.NewConfig().WithRegion(f.region)
+	if f.endpoint != "" {
+		config = config.WithEndpoint(f.endpoint).WithS3ForcePathStyle(true)
+	}
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *config,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil || f.roleARN == "" {
+		return sess, err
+	}
+	return sess.Copy(aws.NewConfig().WithCredentials(stscreds.NewCredentials(sess, f.roleARN))), nil
+}
+
+func runAWS(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("aws", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var awsConfig awsFlags
+	awsConfig.register(fs)
+
+	prefixes := fs.String("prefixes", strings.Join(reaper.DefaultPrefixes, ","), "Comma-separated name prefixes of the resources to delete")
+	olderThan := fs.Duration("older-than", time.Hour, "Only delete resources created at least this long ago, to spare running tests (0 for all)")
+	dryRun := fs.Bool("dry-run", false, "Report what would be deleted without deleting anything")
+	format := fs.String("format", "text", "Report format: "+strings.Join(report.Formats, ", "))
+	out := fs.String("out", "", "Write the report to this file instead of stdout")
+
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+	if *olderThan < 0 {
+		fmt.Fprintln(stderr, "-older-than must not be negative")
+		return exitError
+	}
+
+	sess, err := awsConfig.session()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	r := &reaper.Reaper{
+		S3:        s3.New(sess),
+		IAM:       iam.New(sess),
+		KMS:       kms.New(sess),
+		Region:    awsConfig.region,
+		Prefixes:  strings.Split(*prefixes, ","),
+		OlderThan: *olderThan,
+		DryRun:    *dryRun,
+	}
+	for i := range r.Prefixes {
+		r.Prefixes[i] = strings.TrimSpace(r.Prefixes[i])
+	}
+
+	result, err := r.Reap(context.Background())
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	w := stdout
+	if *out != "" {
+		file, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		defer file.Close()
+		w = file
+	}
+	if err := result.Write(w, *format); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	if !result.Passed() {
+		return exitFailed
+	}
+	return exitOK
+}
+
+func envOrDefault(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
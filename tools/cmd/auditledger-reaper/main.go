@@ -0,0 +1,68 @@
+// Command auditledger-reaper removes resources leaked by the AuditLedger integration and smoke
+// tests from LocalStack or a sandbox account.
+//
+// It finds buckets, IAM policies and KMS key aliases whose names start with the test prefixes,
+// bypasses GOVERNANCE retention to delete every object version and delete marker, and deletes
+// the buckets, detaches and deletes the policies and schedules the keys for deletion. The exit
+// code is 0 when everything matching was removed (or would be, with -dry-run), 1 when some
+// resource could not be removed and 2 on usage or input errors.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+const (
+	exitOK     = 0
+	exitFailed = 1
+	exitError  = 2
+)
+
+type command struct {
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+var commands = map[string]command{
+	"aws": {"Delete leaked test buckets, IAM policies and KMS keys in one AWS region", runAWS},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		usage(stderr)
+		return exitError
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		usage(stderr)
+		return exitError
+	}
+	return cmd.run(args[1:], stdout, stderr)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: auditledger-reaper <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-14s %s\n", name, commands[name].summary)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'auditledger-reaper <command> -h' for the flags of a command.")
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// emptyAccount answers S3, IAM and KMS list calls on one endpoint, the way LocalStack does,
+// for an account with nothing to reap
+func emptyAccount(t *testing.T) string {
+	t.Helper()
+	t.Setenv("AWS_ACCESS_KEY_ID", "test")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "test")
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Header.Get("X-Amz-Target") == "TrentService.ListAliases":
+			fmt.Fprint(w, `{"Aliases": [], "Truncated": false}`)
+		case r.Method == http.MethodPost:
+			require.NoError(t, r.ParseForm())
+			assert.Equal(t, "ListPolicies", r.PostForm.Get("Action"))
+			assert.Equal(t, "Local", r.PostForm.Get("Scope"))
+			fmt.Fprint(w, `<ListPoliciesResponse><ListPoliciesResult><Policies/><IsTruncated>false</IsTruncated></ListPoliciesResult></ListPoliciesResponse>`)
+		case r.Method == http.MethodGet && r.URL.Path == "/":
+			fmt.Fprint(w, `<ListAllMyBucketsResult><Buckets/></ListAllMyBucketsResult>`)
+		default:
+			t.Errorf("unexpected request %s %s", r.Method, r.URL)
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server.URL
+}
+
+func TestRunUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitError, run(nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "aws")
+
+	stderr.Reset()
+	assert.Equal(t, exitError, run([]string{"gcp"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), `unknown command "gcp"`)
+}
+
+func TestRunAWSRejectsEmptyPrefix(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitError, run([]string{"aws", "-prefixes", "test-local-,", "-endpoint", "http://127.0.0.1:1"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "empty prefix")
+
+	stderr.Reset()
+	assert.Equal(t, exitError, run([]string{"aws", "-older-than", "-1h"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "must not be negative")
+}
+
+func TestRunAWSNothingToReap(t *testing.T) {
+	endpoint := emptyAccount(t)
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"aws", "-endpoint", endpoint, "-older-than", "0", "-dry-run"}, &stdout, &stderr)
+	require.Equal(t, exitOK, code, stderr.String())
+	assert.True(t, strings.HasPrefix(stdout.String(), "auditledger-reaper: aws test-auditledger-,test-local-,test-ops-,smoke-test-\n"), stdout.String())
+	assert.Contains(t, stdout.String(), "PASSED: 0 passed, 0 failed, 0 skipped")
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/arm"
+	"github.com/auditledger/auditledger-terraform/tools/internal/verify"
+)
+
+// armFlags configure the Resource Manager client; -arm-endpoint targets sovereign clouds or a recorded server
+type armFlags struct {
+	endpoint string
+}
+
+func (f *armFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.endpoint, "arm-endpoint", envOrDefault("AZURE_RESOURCE_MANAGER_ENDPOINT", arm.DefaultEndpoint), "Resource Manager endpoint")
+}
+
+// client authenticates with AZURE_ACCESS_TOKEN if set, otherwise with the Azure CLI session
+func (f *armFlags) client(subscription string) *arm.Client {
+	var token arm.TokenSource = &arm.CLIToken{Subscription: subscription}
+	if value := os.Getenv("AZURE_ACCESS_TOKEN"); value != "" {
+		token = arm.StaticToken(value)
+	}
+	return &arm.Client{Endpoint: f.endpoint, Token: token}
+}
+
+func runAzure(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("azure", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var outputs outputFlags
+	var reporting reportFlags
+	var armConfig armFlags
+	outputs.register(fs)
+	reporting.register(fs)
+	armConfig.register(fs)
+
+	storageAccountID := fs.String("storage-account-id", "", "Storage account resource ID (overrides the storage_account_id output)")
+	container := fs.String("container", "", "Audit container name (overrides the container_name output)")
+	requireDiagnostics := fs.Bool("require-diagnostics", false, "Fail if the module's diagnostic settings are missing")
+
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	moduleOutputs, err := outputs.load()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	if *storageAccountID != "" {
+		moduleOutputs["storage_account_id"] = []byte(fmt.Sprintf("%q", *storageAccountID))
+	}
+	if *container != "" {
+		moduleOutputs["container_name"] = []byte(fmt.Sprintf("%q", *container))
+	}
+
+	expected, err := verify.AzureExpectationsFromOutputs(moduleOutputs)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	expected.RequireDiagnostics = expected.RequireDiagnostics || *requireDiagnostics
+
+	verifier := &verify.AzureVerifier{ARM: armConfig.client(subscriptionID(expected.StorageAccountID))}
+	return reporting.write(verifier.Verify(context.Background(), expected), stdout, stderr)
+}
+
+// subscriptionID extracts the subscription from a resource ID, or "" if it has none
+func subscriptionID(resourceID string) string {
+	segments := strings.Split(strings.Trim(resourceID, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		if strings.EqualFold(segments[i], "subscriptions") {
+			return segments[i+1]
+		}
+	}
+	return ""
+}
@@ -0,0 +1,67 @@
+// Command auditledger-verify audits the immutability posture of deployed AuditLedger storage.
+//
+// It reads the outputs of an AuditLedger module (from `terraform output -json` or a
+// state file), queries the live cloud account and reports every check as text, JSON
+// or JUnit XML. The exit code is 0 when all checks pass, 1 when a check fails and 2
+// on usage or input errors.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+const (
+	exitPassed = 0
+	exitFailed = 1
+	exitError  = 2
+)
+
+type command struct {
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+var commands = map[string]command{
+	"s3":    {"Verify an S3 bucket created by modules/auditledger-s3", runS3},
+	"azure": {"Verify a storage account created by modules/auditledger-azure-blob", runAzure},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "--help" {
+		usage(stderr)
+		return exitError
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command %q\n\n", args[0])
+		usage(stderr)
+		return exitError
+	}
+	return cmd.run(args[1:], stdout, stderr)
+}
+
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: auditledger-verify <command> [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-14s %s\n", name, commands[name].summary)
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Run 'auditledger-verify <command> -h' for the flags of a command.")
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunUsage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitError, run(nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "s3")
+
+	stderr.Reset()
+	assert.Equal(t, exitError, run([]string{"gcs"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), `unknown command "gcs"`)
+}
+
+func TestRunS3RequiresBucket(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitError, run([]string{"s3"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "bucket_id")
+}
+
+func TestRunS3OutputKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outputs.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"cloud": {"value": "aws"}}`), 0o600))
+
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, exitError, run([]string{"s3", "-outputs", path, "-output-key", "aws"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), `output "aws" not found`)
+}
+
+func TestRunAzureRequiresAccount(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+
+	assert.Equal(t, exitError, run([]string{"azure"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "storage_account_id")
+}
+
+func TestSubscriptionID(t *testing.T) {
+	assert.Equal(t, "1234", subscriptionID("/subscriptions/1234/resourceGroups/audit/providers/Microsoft.Storage/storageAccounts/auditlogs"))
+	assert.Equal(t, "", subscriptionID("auditlogs"))
+}
+
+func TestOutputFlagsNestedKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "outputs.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"audit_storage": {"value": {"cloud": "azure", "azure": {"container_name": "audit-logs"}}}}`), 0o600))
+
+	outputs, err := (&outputFlags{path: path, key: "audit_storage.azure"}).load()
+	require.NoError(t, err)
+	assert.Equal(t, "audit-logs", outputs.String("container_name"))
+}
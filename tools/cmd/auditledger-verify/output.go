@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/report"
+	"github.com/auditledger/auditledger-terraform/tools/internal/tfoutputs"
+)
+
+// reportFlags are shared by every command that produces a report
+type reportFlags struct {
+	format string
+	out    string
+}
+
+func (f *reportFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.format, "format", "text", "Report format: "+strings.Join(report.Formats, ", "))
+	fs.StringVar(&f.out, "out", "", "Write the report to this file instead of stdout")
+}
+
+// write renders the report and returns the exit code for its result
+func (f *reportFlags) write(r *report.Report, stdout, stderr io.Writer) int {
+	w := stdout
+	if f.out != "" {
+		file, err := os.Create(f.out)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return exitError
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if err := r.Write(w, f.format); err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	if !r.Passed() {
+		return exitFailed
+	}
+	return exitPassed
+}
+
+// outputFlags select the module outputs that describe the expected configuration
+type outputFlags struct {
+	path string
+	key  string
+}
+
+func (f *outputFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.path, "outputs", "", "`terraform output -json` file or terraform.tfstate with the module outputs (- for stdin)")
+	fs.StringVar(&f.key, "output-key", "", "Root output that holds the module's outputs as an object; separate nested outputs with dots, e.g. audit_storage.aws")
+}
+
+// load returns the module outputs, or empty outputs if no file was given
+func (f *outputFlags) load() (tfoutputs.Outputs, error) {
+	if f.path == "" {
+		return tfoutputs.Outputs{}, nil
+	}
+
+	outputs, err := tfoutputs.Load(f.path)
+	if err != nil {
+		return nil, err
+	}
+	return outputs.Path(f.key)
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/verify"
+)
+
+// awsFlags configure the AWS session; -endpoint targets LocalStack or another S3-compatible API
+type awsFlags struct {
+	region   string
+	endpoint string
+}
+
+func (f *awsFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.region, "region", envOrDefault("AWS_DEFAULT_REGION", "us-east-1"), "AWS region")
+	fs.StringVar(&f.endpoint, "endpoint", os.Getenv("AWS_ENDPOINT_URL"), "Custom endpoint, e.g. http://localhost:4566 for LocalStack")
+}
+
+func (f *awsFlags) session() (*session.Session, error) {
+	config := aws.NewConfig().WithRegion(f.region)
+	if f.endpoint != "" {
+		config = config.WithEndpoint(f.endpoint).WithS3ForcePathStyle(true)
+	}
+	return session.NewSessionWithOptions(session.Options{
+		Config:            *config,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+}
+
+func runS3(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("s3", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+
+	var outputs outputFlags
+	var reporting reportFlags
+	var awsConfig awsFlags
+	outputs.register(fs)
+	reporting.register(fs)
+	awsConfig.register(fs)
+
+	bucket := fs.String("bucket", "", "Bucket name (overrides the bucket_id output)")
+	lockMode := fs.String("lock-mode", "", "Expected Object Lock mode (overrides object_lock_configuration.mode)")
+	retentionDays := fs.Int("retention-days", 0, "Expected default retention in days (overrides object_lock_configuration.retention_days)")
+	kmsKeyID := fs.String("kms-key-id", "", "Expected KMS key ID or ARN (overrides encryption_configuration.kms_key_id)")
+	iamPolicyARN := fs.String("iam-policy-arn", "", "Writer IAM policy to inspect (overrides the iam_policy_arn output)")
+	requireLogging := fs.Bool("require-logging", false, "Fail if access logging is not enabled")
+	requireReplication := fs.Bool("require-replication", false, "Fail if replication is not configured")
+
+	if err := fs.Parse(args); err != nil {
+		return exitError
+	}
+
+	moduleOutputs, err := outputs.load()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	if *bucket != "" {
+		moduleOutputs["bucket_id"] = []byte(fmt.Sprintf("%q", *bucket))
+	}
+
+	expected, err := verify.S3ExpectationsFromOutputs(moduleOutputs)
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+	if *lockMode != "" {
+		expected.LockMode = *lockMode
+	}
+	if *retentionDays > 0 {
+		expected.RetentionDays = *retentionDays
+	}
+	if *kmsKeyID != "" {
+		expected.KMSKeyID = *kmsKeyID
+	}
+	if *iamPolicyARN != "" {
+		expected.IAMPolicyARN = *iamPolicyARN
+	}
+	expected.RequireLogging = expected.RequireLogging || *requireLogging
+	expected.RequireReplication = expected.RequireReplication || *requireReplication
+
+	sess, err := awsConfig.session()
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return exitError
+	}
+
+	verifier := &verify.S3Verifier{S3: s3.New(sess), IAM: iam.New(sess)}
+	return reporting.write(verifier.Verify(context.Background(), expected), stdout, stderr)
+}
+
+func envOrDefault(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
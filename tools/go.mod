@@ -0,0 +1,15 @@
+module github.com/auditledger/auditledger-terraform/tools
+
+go 1.21
+
+require (
+	github.com/aws/aws-sdk-go v1.49.0
+	github.com/stretchr/testify v1.8.4
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/jmespath/go-jmespath v0.4.0 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+)
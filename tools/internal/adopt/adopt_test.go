@@ -0,0 +1,311 @@
+package adopt
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	writerRole = "arn:aws:iam::123456789012:role/auditledger-writer"
+	adminRole  = "arn:aws:iam::123456789012:role/security-admin"
+	keyARN     = "arn:aws:kms:us-east-1:123456789012:key/1234abcd"
+)
+
+// fakeS3 serves a bucket; nil fields answer with the error S3 returns for unconfigured settings
+type fakeS3 struct {
+	s3iface.S3API
+	lock        *s3.ObjectLockConfiguration
+	block       *s3.PublicAccessBlockConfiguration
+	encryption  *s3.ServerSideEncryptionRule
+	policy      map[string]interface{}
+	lifecycle   []*s3.LifecycleRule
+	logging     *s3.LoggingEnabled
+	replication *s3.ReplicationConfiguration
+	tags        map[string]string
+}
+
+func notFound(code string) error {
+	return awserr.New(code, "not configured", nil)
+}
+
+// moduleBucket is configured exactly as modules/auditledger-s3 configures it
+func moduleBucket() *fakeS3 {
+	statement := func(sid, effect string, principal interface{}, condition interface{}, actions ...string) map[string]interface{} {
+		s := map[string]interface{}{"Sid": sid, "Effect": effect, "Principal": principal, "Action": actions, "Resource": "arn:aws:s3:::audit-logs/*"}
+		if condition != nil {
+			s["Condition"] = condition
+		}
+		return s
+	}
+	exempt := func(arns ...string) interface{} {
+		return map[string]interface{}{"StringNotEquals": map[string]interface{}{"aws:PrincipalArn": arns}}
+	}
+	writers := map[string]interface{}{"AWS": writerRole}
+
+	return &fakeS3{
+		lock: &s3.ObjectLockConfiguration{
+			ObjectLockEnabled: aws.String("Enabled"),
+			Rule:              &s3.ObjectLockRule{DefaultRetention: &s3.DefaultRetention{Mode: aws.String("COMPLIANCE"), Days: aws.Int64(2555)}},
+		},
+		block: &s3.PublicAccessBlockConfiguration{BlockPublicAcls: aws.Bool(true), BlockPublicPolicy: aws.Bool(true), IgnorePublicAcls: aws.Bool(true), RestrictPublicBuckets: aws.Bool(true)},
+		encryption: &s3.ServerSideEncryptionRule{
+			ApplyServerSideEncryptionByDefault: &s3.ServerSideEncryptionByDefault{SSEAlgorithm: aws.String("aws:kms"), KMSMasterKeyID: aws.String(keyARN)},
+			BucketKeyEnabled:                   aws.Bool(true),
+		},
+		policy: map[string]interface{}{"Version": "2012-10-17", "Statement": []interface{}{
+			statement("DenyDeleteObject", "Deny", "*", nil, "s3:DeleteObject", "s3:DeleteObjectVersion"),
+			statement("DenyBypassGovernanceRetention", "Deny", "*", exempt(), "s3:BypassGovernanceRetention"),
+			statement("DenyDisableObjectLock", "Deny", "*", exempt(adminRole), "s3:PutBucketObjectLockConfiguration", "s3:PutObjectLegalHold", "s3:PutObjectRetention"),
+			statement("AllowAuditLedgerWrite", "Allow", writers, nil, "s3:PutObject", "s3:PutObjectLegalHold", "s3:PutObjectRetention"),
+			statement("AllowAuditLedgerRead", "Allow", writers, nil, "s3:GetObject", "s3:GetObjectVersion", "s3:ListBucket", "s3:ListBucketVersions"),
+			statement("DenyUnencryptedObjectUploads", "Deny", "*", nil, "s3:PutObject"),
+			statement("EnforceTLSRequestsOnly", "Deny", "*", nil, "s3:*"),
+		}},
+		lifecycle: []*s3.LifecycleRule{
+			{ID: aws.String("transition-to-ia"), Status: aws.String("Enabled"), Filter: &s3.LifecycleRuleFilter{}, Transitions: []*s3.Transition{
+				{Days: aws.Int64(180), StorageClass: aws.String("GLACIER_IR")},
+				{Days: aws.Int64(90), StorageClass: aws.String("STANDARD_IA")},
+				{Days: aws.Int64(365), StorageClass: aws.String("GLACIER")},
+			}},
+			{ID: aws.String("expire-old-versions"), Status: aws.String("Enabled"), Filter: &s3.LifecycleRuleFilter{}, NoncurrentVersionExpiration: &s3.NoncurrentVersionExpiration{NoncurrentDays: aws.Int64(2555)}},
+		},
+		tags: map[string]string{"Name": "audit-logs", "Purpose": "AuditLedger Immutable Audit Logs", "Immutable": "true", "ManagedBy": "Terraform", "Team": "security"},
+	}
+}
+
+func (f *fakeS3) GetObjectLockConfigurationWithContext(aws.Context, *s3.GetObjectLockConfigurationInput, ...request.Option) (*s3.GetObjectLockConfigurationOutput, error) {
+	if f.lock == nil {
+		return nil, notFound("ObjectLockConfigurationNotFoundError")
+	}
+	return &s3.GetObjectLockConfigurationOutput{ObjectLockConfiguration: f.lock}, nil
+}
+
+func (f *fakeS3) GetPublicAccessBlockWithContext(aws.Context, *s3.GetPublicAccessBlockInput, ...request.Option) (*s3.GetPublicAccessBlockOutput, error) {
+	if f.block == nil {
+		return nil, notFound("NoSuchPublicAccessBlockConfiguration")
+	}
+	return &s3.GetPublicAccessBlockOutput{PublicAccessBlockConfiguration: f.block}, nil
+}
+
+func (f *fakeS3) GetBucketEncryptionWithContext(aws.Context, *s3.GetBucketEncryptionInput, ...request.Option) (*s3.GetBucketEncryptionOutput, error) {
+	return &s3.GetBucketEncryptionOutput{ServerSideEncryptionConfiguration: &s3.ServerSideEncryptionConfiguration{Rules: []*s3.ServerSideEncryptionRule{f.encryption}}}, nil
+}
+
+func (f *fakeS3) GetBucketPolicyWithContext(aws.Context, *s3.GetBucketPolicyInput, ...request.Option) (*s3.GetBucketPolicyOutput, error) {
+	if f.policy == nil {
+		return nil, notFound("NoSuchBucketPolicy")
+	}
+	data, _ := json.Marshal(f.policy)
+	return &s3.GetBucketPolicyOutput{Policy: aws.String(string(data))}, nil
+}
+
+func (f *fakeS3) GetBucketLifecycleConfigurationWithContext(aws.Context, *s3.GetBucketLifecycleConfigurationInput, ...request.Option) (*s3.GetBucketLifecycleConfigurationOutput, error) {
+	if f.lifecycle == nil {
+		return nil, notFound("NoSuchLifecycleConfiguration")
+	}
+	return &s3.GetBucketLifecycleConfigurationOutput{Rules: f.lifecycle}, nil
+}
+
+func (f *fakeS3) GetBucketLoggingWithContext(aws.Context, *s3.GetBucketLoggingInput, ...request.Option) (*s3.GetBucketLoggingOutput, error) {
+	return &s3.GetBucketLoggingOutput{LoggingEnabled: f.logging}, nil
+}
+
+func (f *fakeS3) GetBucketReplicationWithContext(aws.Context, *s3.GetBucketReplicationInput, ...request.Option) (*s3.GetBucketReplicationOutput, error) {
+	if f.replication == nil {
+		return nil, notFound("ReplicationConfigurationNotFoundError")
+	}
+	return &s3.GetBucketReplicationOutput{ReplicationConfiguration: f.replication}, nil
+}
+
+func (f *fakeS3) GetBucketTaggingWithContext(aws.Context, *s3.GetBucketTaggingInput, ...request.Option) (*s3.GetBucketTaggingOutput, error) {
+	if f.tags == nil {
+		return nil, notFound("NoSuchTagSet")
+	}
+	out := &s3.GetBucketTaggingOutput{}
+	for key, value := range f.tags {
+		out.TagSet = append(out.TagSet, &s3.Tag{Key: aws.String(key), Value: aws.String(value)})
+	}
+	return out, nil
+}
+
+func adopt(t *testing.T, fake *fakeS3, options Options) *Adoption {
+	t.Helper()
+	bucket, err := (&Inspector{S3: fake}).Inspect(context.Background(), "audit-logs")
+	require.NoError(t, err)
+	return Plan(bucket, options)
+}
+
+func inputs(a *Adoption) map[string]string {
+	values := map[string]string{}
+	for _, input := range a.Inputs {
+		values[input.Name] = input.Value
+	}
+	return values
+}
+
+func changed(a *Adoption) []string {
+	var names []string
+	for _, setting := range a.Changes() {
+		names = append(names, setting.Resource+" "+setting.Name)
+	}
+	return names
+}
+
+func TestPlanModuleBucket(t *testing.T) {
+	a := adopt(t, moduleBucket(), Options{})
+
+	assert.Empty(t, a.Blockers)
+	assert.Empty(t, changed(a), "A bucket the module built needs no changes")
+	assert.Empty(t, a.Unmanaged)
+	assert.Equal(t, map[string]string{
+		"bucket_name":           `"audit-logs"`,
+		"object_lock_mode":      `"COMPLIANCE"`,
+		"retention_days":        "2555",
+		"kms_key_id":            `"` + keyARN + `"`,
+		"auditledger_role_arns": `["` + writerRole + `"]`,
+		"admin_role_arns":       `["` + adminRole + `"]`,
+		"tags":                  "{\n    Team = \"security\"\n  }",
+	}, inputs(a))
+
+	var imported []string
+	for _, imp := range a.Imports {
+		assert.Equal(t, "audit-logs", imp.ID)
+		imported = append(imported, imp.Resource)
+	}
+	assert.Equal(t, []string{
+		"aws_s3_bucket.audit_logs",
+		"aws_s3_bucket_public_access_block.audit_logs",
+		"aws_s3_bucket_object_lock_configuration.audit_logs",
+		"aws_s3_bucket_server_side_encryption_configuration.audit_logs",
+		"aws_s3_bucket_policy.audit_logs",
+		"aws_s3_bucket_lifecycle_configuration.audit_logs[0]",
+	}, imported)
+}
+
+func TestPlanHandBuiltBucket(t *testing.T) {
+	fake := moduleBucket()
+	fake.lock.Rule.DefaultRetention = &s3.DefaultRetention{Mode: aws.String("GOVERNANCE"), Years: aws.Int64(7)}
+	fake.block = nil
+	fake.encryption = &s3.ServerSideEncryptionRule{ApplyServerSideEncryptionByDefault: &s3.ServerSideEncryptionByDefault{SSEAlgorithm: aws.String("aws:kms")}}
+	fake.policy = map[string]interface{}{"Statement": map[string]interface{}{
+		"Sid": "LegacyWriters", "Effect": "Allow", "Principal": map[string]interface{}{"AWS": []string{writerRole, "arn:aws:iam::123456789012:role/app"}}, "Action": "s3:PutObject", "Resource": "*",
+	}}
+	fake.lifecycle = []*s3.LifecycleRule{{ID: aws.String("archive"), Status: aws.String("Enabled"), Transitions: []*s3.Transition{{Days: aws.Int64(30), StorageClass: aws.String("GLACIER")}}}}
+	fake.logging = &s3.LoggingEnabled{TargetBucket: aws.String("access-logs"), TargetPrefix: aws.String("audit/")}
+	fake.replication = &s3.ReplicationConfiguration{Role: aws.String("arn:aws:iam::123456789012:role/replication"), Rules: []*s3.ReplicationRule{
+		{ID: aws.String("dr"), Status: aws.String("Enabled"), Destination: &s3.Destination{Bucket: aws.String("arn:aws:s3:::audit-logs-dr")}},
+		{ID: aws.String("old"), Status: aws.String("Disabled"), Destination: &s3.Destination{Bucket: aws.String("arn:aws:s3:::old")}},
+	}}
+	fake.tags = map[string]string{"ComplianceProfile": "sox", "Compliance": "SOX", "Owner": "platform"}
+
+	a := adopt(t, fake, Options{})
+	assert.Empty(t, a.Blockers)
+
+	values := inputs(a)
+	assert.Equal(t, `"GOVERNANCE"`, values["object_lock_mode"])
+	assert.Equal(t, "2555", values["retention_days"])
+	assert.NotContains(t, values, "kms_key_id", "The AWS managed key cannot be passed to the module")
+	assert.Equal(t, `["arn:aws:iam::123456789012:role/app", "`+writerRole+`"]`, values["auditledger_role_arns"])
+	assert.Equal(t, "false", values["enable_lifecycle_rules"])
+	assert.Equal(t, `"access-logs"`, values["access_log_bucket"])
+	assert.Equal(t, `"arn:aws:s3:::audit-logs-dr"`, values["replication_bucket_arn"])
+	assert.Equal(t, `"sox"`, values["compliance_profile"])
+	assert.Equal(t, "{\n    Owner = \"platform\"\n  }", values["tags"])
+	assert.Len(t, a.Unmanaged, 1)
+
+	assert.Subset(t, changed(a), []string{
+		"aws_s3_bucket.audit_logs tags.Name",
+		"aws_s3_bucket_public_access_block.audit_logs block_public_acls",
+		"aws_s3_bucket_object_lock_configuration.audit_logs rule.default_retention",
+		"aws_s3_bucket_server_side_encryption_configuration.audit_logs sse_algorithm",
+		"aws_s3_bucket_policy.audit_logs Statement.DenyDeleteObject",
+		"aws_s3_bucket_policy.audit_logs Statement.LegacyWriters",
+		"aws_s3_bucket_logging.audit_logs[0] target_prefix",
+		"aws_s3_bucket_replication_configuration.audit_logs[0] rule.id",
+		"aws_s3_bucket_replication_configuration.audit_logs[0] rule.old",
+	})
+	assert.NotContains(t, changed(a), "aws_s3_bucket_object_lock_configuration.audit_logs rule.default_retention.mode", "The lock mode is kept")
+	for _, setting := range a.Settings {
+		if setting.Name == "rule.default_retention" {
+			assert.Equal(t, "7 years", setting.Current)
+			assert.Equal(t, "2555 days", setting.Planned)
+		}
+	}
+	for _, imp := range a.Imports {
+		assert.NotEqual(t, "aws_s3_bucket_public_access_block.audit_logs", imp.Resource, "There is no public access block to import")
+		assert.NotEqual(t, "aws_s3_bucket_lifecycle_configuration.audit_logs[0]", imp.Resource, "Different lifecycle rules stay unmanaged")
+	}
+}
+
+func TestPlanBlockers(t *testing.T) {
+	fake := moduleBucket()
+	fake.lock = nil
+	fake.policy = nil
+
+	a := adopt(t, fake, Options{})
+	require.Len(t, a.Blockers, 2)
+	assert.Contains(t, a.Blockers[0], "destroy and re-create the bucket")
+	assert.Contains(t, a.Blockers[1], "-auditledger-role-arns")
+
+	a = adopt(t, fake, Options{AuditLedgerRoleARNs: []string{writerRole}})
+	assert.Len(t, a.Blockers, 1, "Writer roles can be given explicitly")
+}
+
+func TestPlanShortRetention(t *testing.T) {
+	fake := moduleBucket()
+	fake.lock.Rule.DefaultRetention.Days = aws.Int64(90)
+
+	a := adopt(t, fake, Options{})
+	assert.Equal(t, "365", inputs(a)["retention_days"])
+	for _, setting := range a.Changes() {
+		if setting.Name == "rule.default_retention" {
+			assert.Contains(t, setting.Note, "at least 365 days")
+		}
+	}
+}
+
+func TestWriteHCL(t *testing.T) {
+	fake := moduleBucket()
+	fake.tags["ComplianceProfile"] = "soc2"
+	fake.tags["cost-center"] = "${var.x}"
+	a := adopt(t, fake, Options{})
+
+	var b bytes.Buffer
+	require.NoError(t, a.WriteHCL(&b, "audit_logs", "./modules/auditledger-s3"))
+	hcl := b.String()
+
+	assert.Contains(t, hcl, `module "audit_logs" {
+  source = "./modules/auditledger-s3"
+
+  bucket_name           = "audit-logs"
+  object_lock_mode      = "COMPLIANCE"
+  retention_days        = 2555
+`)
+	assert.Contains(t, hcl, `
+  # From the ComplianceProfile tag; the profile's retention, lock mode, key and logging requirements are checked at plan time
+  compliance_profile = "soc2"
+`)
+	assert.Contains(t, hcl, `
+  tags = {
+    Team        = "security"
+    cost-center = "$${var.x}"
+  }
+}
+`)
+	assert.Contains(t, hcl, `
+import {
+  to = module.audit_logs.aws_s3_bucket_policy.audit_logs
+  id = "audit-logs"
+}
+`)
+}
@@ -0,0 +1,102 @@
+package adopt
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultSource is the module source used in the generated configuration
+const DefaultSource = "github.com/auditledger/auditledger-terraform//modules/auditledger-s3?ref=v2.0.0"
+
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// quote returns an HCL string literal; "${" and "%{" would start a template sequence
+func quote(value string) string {
+	quoted := strconv.Quote(value)
+	quoted = strings.ReplaceAll(quoted, "${", "$${")
+	return strings.ReplaceAll(quoted, "%{", "%%{")
+}
+
+func hclList(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = quote(value)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// hclMap renders a map with aligned keys, the way terraform fmt does
+func hclMap(values map[string]string) string {
+	keys := make([]string, 0, len(values))
+	width := 0
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	rendered := make([]string, len(keys))
+	for i, key := range keys {
+		rendered[i] = key
+		if !identifier.MatchString(key) {
+			rendered[i] = quote(key)
+		}
+		if len(rendered[i]) > width {
+			width = len(rendered[i])
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("{\n")
+	for i, key := range keys {
+		fmt.Fprintf(&b, "    %-*s = %s\n", width, rendered[i], quote(values[key]))
+	}
+	b.WriteString("  }")
+	return b.String()
+}
+
+// WriteHCL writes the module call and an `import` block for every existing resource. Single-line
+// inputs come first with aligned equals signs, then commented and multi-line ones
+func (a *Adoption) WriteHCL(w io.Writer, name, source string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Adopts the existing bucket %s into modules/auditledger-s3 (generated by auditledger-adopt).\n", a.Bucket)
+	b.WriteString("# Run terraform plan and check that it imports every resource below and replaces none\n")
+	b.WriteString("# before you apply.\n\n")
+
+	fmt.Fprintf(&b, "module %q {\n", name)
+	fmt.Fprintf(&b, "  source = %s\n\n", quote(source))
+
+	var simple, rest []Input
+	width := 0
+	for _, input := range a.Inputs {
+		if input.Comment != "" || strings.Contains(input.Value, "\n") {
+			rest = append(rest, input)
+			continue
+		}
+		simple = append(simple, input)
+		if len(input.Name) > width {
+			width = len(input.Name)
+		}
+	}
+	for _, input := range simple {
+		fmt.Fprintf(&b, "  %-*s = %s\n", width, input.Name, input.Value)
+	}
+	for _, input := range rest {
+		b.WriteString("\n")
+		if input.Comment != "" {
+			fmt.Fprintf(&b, "  # %s\n", input.Comment)
+		}
+		fmt.Fprintf(&b, "  %s = %s\n", input.Name, input.Value)
+	}
+	b.WriteString("}\n")
+
+	for _, imp := range a.Imports {
+		fmt.Fprintf(&b, "\nimport {\n  to = module.%s.%s\n  id = %s\n}\n", name, imp.Resource, quote(imp.ID))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
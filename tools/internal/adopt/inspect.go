@@ -0,0 +1,122 @@
+// Package adopt brings existing, hand-built audit buckets under modules/auditledger-s3. It reads
+// a bucket's configuration, derives the module inputs that reproduce it, lists the `import`
+// blocks for every resource the module would otherwise create, and reports each setting the
+// module would still change on the next apply. Object Lock cannot be added to a bucket later,
+// so anything that would make Terraform replace the bucket blocks the adoption
+package adopt
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/verify"
+)
+
+// Bucket is the configuration of an existing bucket; nil fields were not configured
+type Bucket struct {
+	Name              string
+	ObjectLock        *s3.ObjectLockConfiguration
+	PublicAccessBlock *s3.PublicAccessBlockConfiguration
+	Encryption        *s3.ServerSideEncryptionRule
+	Policy            *verify.PolicyDocument
+	Lifecycle         []*s3.LifecycleRule
+	Logging           *s3.LoggingEnabled
+	Replication       *s3.ReplicationConfiguration
+	Tags              map[string]string
+}
+
+// ObjectLockEnabled reports whether the bucket was created with Object Lock
+func (b *Bucket) ObjectLockEnabled() bool {
+	return b.ObjectLock != nil && aws.StringValue(b.ObjectLock.ObjectLockEnabled) == s3.ObjectLockEnabledEnabled
+}
+
+// Inspector reads bucket configuration through the S3 API; it never writes
+type Inspector struct {
+	S3 s3iface.S3API
+}
+
+// Inspect reads every setting of the bucket that modules/auditledger-s3 manages
+func (i *Inspector) Inspect(ctx context.Context, name string) (*Bucket, error) {
+	b := &Bucket{Name: name, Tags: map[string]string{}}
+	bucket := aws.String(name)
+
+	lock, err := i.S3.GetObjectLockConfigurationWithContext(ctx, &s3.GetObjectLockConfigurationInput{Bucket: bucket})
+	if err := absent(err, "ObjectLockConfigurationNotFoundError"); err != nil {
+		return nil, fmt.Errorf("reading Object Lock configuration of %s: %w", name, err)
+	}
+	if lock != nil {
+		b.ObjectLock = lock.ObjectLockConfiguration
+	}
+
+	block, err := i.S3.GetPublicAccessBlockWithContext(ctx, &s3.GetPublicAccessBlockInput{Bucket: bucket})
+	if err := absent(err, "NoSuchPublicAccessBlockConfiguration"); err != nil {
+		return nil, fmt.Errorf("reading public access block of %s: %w", name, err)
+	}
+	if block != nil {
+		b.PublicAccessBlock = block.PublicAccessBlockConfiguration
+	}
+
+	encryption, err := i.S3.GetBucketEncryptionWithContext(ctx, &s3.GetBucketEncryptionInput{Bucket: bucket})
+	if err := absent(err, "ServerSideEncryptionConfigurationNotFoundError"); err != nil {
+		return nil, fmt.Errorf("reading default encryption of %s: %w", name, err)
+	}
+	if encryption != nil && encryption.ServerSideEncryptionConfiguration != nil && len(encryption.ServerSideEncryptionConfiguration.Rules) > 0 {
+		b.Encryption = encryption.ServerSideEncryptionConfiguration.Rules[0]
+	}
+
+	policy, err := i.S3.GetBucketPolicyWithContext(ctx, &s3.GetBucketPolicyInput{Bucket: bucket})
+	if err := absent(err, "NoSuchBucketPolicy"); err != nil {
+		return nil, fmt.Errorf("reading bucket policy of %s: %w", name, err)
+	}
+	if policy != nil && aws.StringValue(policy.Policy) != "" {
+		if b.Policy, err = verify.ParsePolicy(aws.StringValue(policy.Policy)); err != nil {
+			return nil, fmt.Errorf("bucket policy of %s: %w", name, err)
+		}
+	}
+
+	lifecycle, err := i.S3.GetBucketLifecycleConfigurationWithContext(ctx, &s3.GetBucketLifecycleConfigurationInput{Bucket: bucket})
+	if err := absent(err, "NoSuchLifecycleConfiguration"); err != nil {
+		return nil, fmt.Errorf("reading lifecycle configuration of %s: %w", name, err)
+	}
+	if lifecycle != nil {
+		b.Lifecycle = lifecycle.Rules
+	}
+
+	logging, err := i.S3.GetBucketLoggingWithContext(ctx, &s3.GetBucketLoggingInput{Bucket: bucket})
+	if err != nil {
+		return nil, fmt.Errorf("reading access logging of %s: %w", name, err)
+	}
+	b.Logging = logging.LoggingEnabled
+
+	replication, err := i.S3.GetBucketReplicationWithContext(ctx, &s3.GetBucketReplicationInput{Bucket: bucket})
+	if err := absent(err, "ReplicationConfigurationNotFoundError"); err != nil {
+		return nil, fmt.Errorf("reading replication of %s: %w", name, err)
+	}
+	if replication != nil {
+		b.Replication = replication.ReplicationConfiguration
+	}
+
+	tagging, err := i.S3.GetBucketTaggingWithContext(ctx, &s3.GetBucketTaggingInput{Bucket: bucket})
+	if err := absent(err, "NoSuchTagSet"); err != nil {
+		return nil, fmt.Errorf("reading tags of %s: %w", name, err)
+	}
+	if tagging != nil {
+		for _, tag := range tagging.TagSet {
+			b.Tags[aws.StringValue(tag.Key)] = aws.StringValue(tag.Value)
+		}
+	}
+	return b, nil
+}
+
+// absent treats the error S3 returns for an unconfigured setting as no error
+func absent(err error, code string) error {
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == code {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,519 @@
+package adopt
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/verify"
+)
+
+const none = "(none)"
+
+// Input is a module argument as an HCL expression
+type Input struct {
+	Name    string `json:"name"`
+	Value   string `json:"value"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// Import is an `import` block for a resource of the module, by its address inside the module
+type Import struct {
+	Resource string `json:"resource"`
+	ID       string `json:"id"`
+}
+
+// Setting is a setting the module manages, as it is now and as the module would apply it
+type Setting struct {
+	Resource string `json:"resource"`
+	Name     string `json:"name"`
+	Current  string `json:"current"`
+	Planned  string `json:"planned"`
+	Note     string `json:"note,omitempty"`
+}
+
+// Changed reports whether the next apply would change the setting
+func (s Setting) Changed() bool {
+	return s.Current != s.Planned
+}
+
+// Adoption is how to bring one bucket under modules/auditledger-s3
+type Adoption struct {
+	Bucket   string    `json:"bucket"`
+	Inputs   []Input   `json:"inputs"`
+	Imports  []Import  `json:"imports"`
+	Settings []Setting `json:"settings"`
+	// Creates lists resources the module adds next to the bucket
+	Creates []string `json:"creates,omitempty"`
+	// Unmanaged lists existing configuration the module leaves as it is
+	Unmanaged []string `json:"unmanaged,omitempty"`
+	// Blockers make the adoption unsafe; no configuration should be generated
+	Blockers []string `json:"blockers,omitempty"`
+}
+
+// Changes returns the settings the next apply would change
+func (a *Adoption) Changes() []Setting {
+	var changes []Setting
+	for _, setting := range a.Settings {
+		if setting.Changed() {
+			changes = append(changes, setting)
+		}
+	}
+	return changes
+}
+
+// Options override what cannot be read reliably from the bucket
+type Options struct {
+	// AuditLedgerRoleARNs are the writer roles, instead of the principals the bucket policy allows to write
+	AuditLedgerRoleARNs []string
+}
+
+func (a *Adoption) input(name, value, comment string) {
+	a.Inputs = append(a.Inputs, Input{Name: name, Value: value, Comment: comment})
+}
+
+func (a *Adoption) imports(resource string) {
+	a.Imports = append(a.Imports, Import{Resource: resource, ID: a.Bucket})
+}
+
+func (a *Adoption) compare(resource, name, current, planned string) {
+	a.Settings = append(a.Settings, Setting{Resource: resource, Name: name, Current: current, Planned: planned})
+}
+
+// moduleTags are the tags aws_s3_bucket.audit_logs always sets, after var.tags
+func moduleTags(bucket string) [][2]string {
+	return [][2]string{
+		{"Name", bucket},
+		{"Purpose", "AuditLedger Immutable Audit Logs"},
+		{"Immutable", "true"},
+		{"ManagedBy", "Terraform"},
+	}
+}
+
+// Plan derives the module inputs, imports and changes for a bucket
+func Plan(b *Bucket, options Options) *Adoption {
+	a := &Adoption{Bucket: b.Name}
+	a.input("bucket_name", quote(b.Name), "")
+	a.imports("aws_s3_bucket.audit_logs")
+
+	// object_lock_enabled forces a new bucket in the AWS provider: Terraform would destroy the
+	// bucket and every audit log in it to add Object Lock
+	if !b.ObjectLockEnabled() {
+		a.Blockers = append(a.Blockers, fmt.Sprintf("%s was created without Object Lock; the module's object_lock_enabled = true would make Terraform destroy and re-create the bucket. Create a new bucket with the module and copy the audit logs to it instead", b.Name))
+	}
+
+	planTags(a, b)
+	planPublicAccessBlock(a, b)
+	retentionDays := planObjectLock(a, b)
+	kms := planEncryption(a, b)
+	planPolicy(a, b, options, kms)
+	planLifecycle(a, b, retentionDays)
+	planLogging(a, b)
+	planReplication(a, b)
+
+	a.Creates = append(a.Creates, fmt.Sprintf("aws_iam_policy.s3_access (%s-access-policy)", b.Name))
+	return a
+}
+
+func planTags(a *Adoption, b *Bucket) {
+	reserved := map[string]bool{}
+	for _, tag := range moduleTags(b.Name) {
+		reserved[tag[0]] = true
+		current, ok := b.Tags[tag[0]]
+		if !ok {
+			current = none
+		}
+		a.compare("aws_s3_bucket.audit_logs", "tags."+tag[0], current, tag[1])
+	}
+
+	// The profile's own tags are set by the module from compliance_profile
+	if profile := b.Tags["ComplianceProfile"]; profile != "" {
+		a.input("compliance_profile", quote(profile), "From the ComplianceProfile tag; the profile's retention, lock mode, key and logging requirements are checked at plan time")
+		reserved["Compliance"] = true
+		reserved["ComplianceProfile"] = true
+	}
+
+	tags := map[string]string{}
+	for key, value := range b.Tags {
+		if !reserved[key] {
+			tags[key] = value
+		}
+	}
+	if len(tags) > 0 {
+		a.input("tags", hclMap(tags), "")
+	}
+}
+
+func planPublicAccessBlock(a *Adoption, b *Bucket) {
+	const resource = "aws_s3_bucket_public_access_block.audit_logs"
+	block := b.PublicAccessBlock
+	if block != nil {
+		a.imports(resource)
+	}
+	for _, setting := range []struct {
+		name  string
+		value func(*s3.PublicAccessBlockConfiguration) *bool
+	}{
+		{"block_public_acls", func(c *s3.PublicAccessBlockConfiguration) *bool { return c.BlockPublicAcls }},
+		{"block_public_policy", func(c *s3.PublicAccessBlockConfiguration) *bool { return c.BlockPublicPolicy }},
+		{"ignore_public_acls", func(c *s3.PublicAccessBlockConfiguration) *bool { return c.IgnorePublicAcls }},
+		{"restrict_public_buckets", func(c *s3.PublicAccessBlockConfiguration) *bool { return c.RestrictPublicBuckets }},
+	} {
+		current := none
+		if block != nil {
+			current = fmt.Sprint(aws.BoolValue(setting.value(block)))
+		}
+		a.compare(resource, setting.name, current, "true")
+	}
+}
+
+// planObjectLock returns the retention period the module will apply
+func planObjectLock(a *Adoption, b *Bucket) int {
+	const resource = "aws_s3_bucket_object_lock_configuration.audit_logs"
+	if !b.ObjectLockEnabled() {
+		return 2555
+	}
+	a.imports(resource)
+
+	var retention *s3.DefaultRetention
+	if b.ObjectLock.Rule != nil {
+		retention = b.ObjectLock.Rule.DefaultRetention
+	}
+
+	mode, days, current := "COMPLIANCE", 2555, none
+	if retention != nil {
+		mode = aws.StringValue(retention.Mode)
+		switch {
+		case retention.Days != nil:
+			days = int(aws.Int64Value(retention.Days))
+			current = fmt.Sprintf("%d days", days)
+		case retention.Years != nil:
+			days = int(aws.Int64Value(retention.Years)) * 365
+			current = fmt.Sprintf("%d years", aws.Int64Value(retention.Years))
+		}
+		a.input("object_lock_mode", quote(mode), "")
+	}
+
+	planned := days
+	if planned < 365 {
+		planned = 365
+	}
+	if retention != nil {
+		a.input("retention_days", fmt.Sprint(planned), "")
+	}
+
+	currentMode := none
+	if retention != nil {
+		currentMode = mode
+	}
+	a.compare(resource, "rule.default_retention.mode", currentMode, mode)
+	a.Settings = append(a.Settings, Setting{Resource: resource, Name: "rule.default_retention", Current: current, Planned: fmt.Sprintf("%d days", planned)})
+	if planned != days {
+		last := &a.Settings[len(a.Settings)-1]
+		last.Note = "the module requires at least 365 days; new objects will be locked for longer"
+	}
+	return planned
+}
+
+// planEncryption returns whether the module will use a customer-managed KMS key
+func planEncryption(a *Adoption, b *Bucket) bool {
+	const resource = "aws_s3_bucket_server_side_encryption_configuration.audit_logs"
+	algorithm, key, bucketKey := none, none, none
+	if b.Encryption != nil {
+		a.imports(resource)
+		if rule := b.Encryption.ApplyServerSideEncryptionByDefault; rule != nil {
+			algorithm = aws.StringValue(rule.SSEAlgorithm)
+			key = aws.StringValue(rule.KMSMasterKeyID)
+		}
+		bucketKey = fmt.Sprint(aws.BoolValue(b.Encryption.BucketKeyEnabled))
+	}
+
+	kms := algorithm == s3.ServerSideEncryptionAwsKms && key != ""
+	if kms {
+		a.input("kms_key_id", quote(key), "")
+		a.compare(resource, "sse_algorithm", algorithm, s3.ServerSideEncryptionAwsKms)
+		a.compare(resource, "kms_master_key_id", key, key)
+		a.compare(resource, "bucket_key_enabled", bucketKey, "true")
+		return true
+	}
+
+	a.compare(resource, "sse_algorithm", algorithm, s3.ServerSideEncryptionAes256)
+	if algorithm != s3.ServerSideEncryptionAes256 && algorithm != none {
+		last := &a.Settings[len(a.Settings)-1]
+		last.Note = "the module uses SSE-S3 unless kms_key_id names a customer-managed key"
+	}
+	if key == "" {
+		key = none
+	}
+	a.compare(resource, "kms_master_key_id", key, none)
+	a.compare(resource, "bucket_key_enabled", bucketKey, "false")
+	return false
+}
+
+func describe(effect string, actions []string) string {
+	sorted := append([]string(nil), actions...)
+	sort.Strings(sorted)
+	return effect + " " + strings.Join(sorted, ", ")
+}
+
+func planPolicy(a *Adoption, b *Bucket, options Options, kms bool) {
+	const resource = "aws_s3_bucket_policy.audit_logs"
+	policy := b.Policy
+	if policy == nil {
+		policy = &verify.PolicyDocument{}
+	} else {
+		a.imports(resource)
+	}
+
+	writers := options.AuditLedgerRoleARNs
+	if len(writers) == 0 {
+		if existing := policy.StatementBySid("AllowAuditLedgerWrite"); existing != nil {
+			writers = principals(existing)
+		}
+	}
+	if len(writers) == 0 {
+		for i := range policy.Statement {
+			existing := &policy.Statement[i]
+			if strings.EqualFold(existing.Effect, "Allow") && existing.Grants("s3:PutObject") && !strings.HasPrefix(existing.Sid, "AllowManifest") {
+				writers = append(writers, principals(existing)...)
+			}
+		}
+	}
+	writers = unique(writers)
+	if len(writers) == 0 {
+		a.Blockers = append(a.Blockers, "no role is allowed to write to the bucket, so auditledger_role_arns cannot be derived - pass the writer roles with -auditledger-role-arns")
+	}
+	a.input("auditledger_role_arns", hclList(writers), "")
+
+	var admins, bypass, manifest []string
+	if existing := policy.StatementBySid("DenyDisableObjectLock"); existing != nil {
+		admins = unique(exemptions(existing))
+	}
+	if existing := policy.StatementBySid("DenyBypassGovernanceRetention"); existing != nil {
+		bypass = unique(exemptions(existing))
+	}
+	if existing := policy.StatementBySid("AllowManifestWrite"); existing != nil {
+		manifest = unique(principals(existing))
+	}
+	if len(admins) > 0 {
+		a.input("admin_role_arns", hclList(admins), "")
+	}
+	if len(bypass) > 0 {
+		a.input("governance_bypass_role_arns", hclList(bypass), "")
+	}
+	if len(manifest) > 0 {
+		a.input("manifest_writer_role_arns", hclList(manifest), "")
+		a.Creates = append(a.Creates, fmt.Sprintf("aws_iam_policy.manifest_writer[0] (%s-manifest-writer-policy)", b.Name))
+	}
+
+	expected := append([]verify.BucketStatement(nil), verify.ExpectedBucketStatements...)
+	if len(manifest) > 0 {
+		expected = append(expected, verify.ManifestBucketStatements...)
+	}
+	known := map[string]bool{}
+	for _, planned := range expected {
+		known[planned.Sid] = true
+		current := none
+		if existing := policy.StatementBySid(planned.Sid); existing != nil {
+			current = describe(existing.Effect, existing.Action)
+		}
+		a.compare(resource, "Statement."+planned.Sid, current, describe(planned.Effect, planned.Actions))
+		if planned.Sid == "DenyUnencryptedObjectUploads" {
+			header := "AES256"
+			if kms {
+				header = "aws:kms"
+			}
+			a.Settings[len(a.Settings)-1].Note = "uploads must send x-amz-server-side-encryption: " + header
+		}
+	}
+	for i, existing := range policy.Statement {
+		if known[existing.Sid] {
+			continue
+		}
+		sid := existing.Sid
+		if sid == "" {
+			sid = fmt.Sprintf("#%d", i+1)
+		}
+		a.compare(resource, "Statement."+sid, describe(existing.Effect, existing.Action), "(removed)")
+	}
+}
+
+// principals returns the AWS principals of a statement
+func principals(s *verify.PolicyStatement) []string {
+	var principal struct {
+		AWS verify.StringList `json:"AWS"`
+	}
+	if err := json.Unmarshal(s.Principal, &principal); err != nil {
+		return nil
+	}
+	return principal.AWS
+}
+
+// exemptions returns the aws:PrincipalArn values a Deny statement's StringNotEquals condition exempts
+func exemptions(s *verify.PolicyStatement) []string {
+	var condition struct {
+		StringNotEquals map[string]verify.StringList `json:"StringNotEquals"`
+	}
+	if err := json.Unmarshal(s.Condition, &condition); err != nil {
+		return nil
+	}
+	for key, values := range condition.StringNotEquals {
+		if strings.EqualFold(key, "aws:PrincipalArn") {
+			return values
+		}
+	}
+	return nil
+}
+
+// moduleLifecycle describes the rules of aws_s3_bucket_lifecycle_configuration.audit_logs
+func moduleLifecycle(retentionDays int) map[string]string {
+	return map[string]string{
+		"transition-to-ia":    "Enabled: STANDARD_IA after 90 days, GLACIER_IR after 180 days, GLACIER after 365 days",
+		"expire-old-versions": fmt.Sprintf("Enabled: noncurrent versions expire after %d days", retentionDays),
+	}
+}
+
+func describeRule(rule *s3.LifecycleRule) string {
+	var parts []string
+	if prefix := aws.StringValue(rule.Prefix); prefix != "" {
+		parts = append(parts, "prefix "+prefix)
+	}
+	if rule.Filter != nil && (aws.StringValue(rule.Filter.Prefix) != "" || rule.Filter.Tag != nil || rule.Filter.And != nil) {
+		parts = append(parts, "filtered")
+	}
+	transitions := append([]*s3.Transition(nil), rule.Transitions...)
+	sort.Slice(transitions, func(i, j int) bool { return aws.Int64Value(transitions[i].Days) < aws.Int64Value(transitions[j].Days) })
+	for _, transition := range transitions {
+		parts = append(parts, fmt.Sprintf("%s after %d days", aws.StringValue(transition.StorageClass), aws.Int64Value(transition.Days)))
+	}
+	if rule.Expiration != nil && rule.Expiration.Days != nil {
+		parts = append(parts, fmt.Sprintf("current versions expire after %d days", aws.Int64Value(rule.Expiration.Days)))
+	}
+	if rule.NoncurrentVersionExpiration != nil {
+		parts = append(parts, fmt.Sprintf("noncurrent versions expire after %d days", aws.Int64Value(rule.NoncurrentVersionExpiration.NoncurrentDays)))
+	}
+	if len(rule.NoncurrentVersionTransitions) > 0 {
+		parts = append(parts, "noncurrent version transitions")
+	}
+	if rule.AbortIncompleteMultipartUpload != nil {
+		parts = append(parts, fmt.Sprintf("incomplete uploads aborted after %d days", aws.Int64Value(rule.AbortIncompleteMultipartUpload.DaysAfterInitiation)))
+	}
+	return aws.StringValue(rule.Status) + ": " + strings.Join(parts, ", ")
+}
+
+// planLifecycle only lets the module manage lifecycle rules that already match its own;
+// different rules are left alone rather than replaced
+func planLifecycle(a *Adoption, b *Bucket, retentionDays int) {
+	const resource = "aws_s3_bucket_lifecycle_configuration.audit_logs[0]"
+	if len(b.Lifecycle) == 0 {
+		a.input("enable_lifecycle_rules", "false", "")
+		return
+	}
+
+	expected := moduleLifecycle(retentionDays)
+
+	current := map[string]string{}
+	for i, rule := range b.Lifecycle {
+		id := aws.StringValue(rule.ID)
+		if id == "" {
+			id = fmt.Sprintf("#%d", i+1)
+		}
+		current[id] = describeRule(rule)
+	}
+
+	matches := len(current) == len(expected)
+	for id, description := range expected {
+		matches = matches && current[id] == description
+	}
+	if !matches {
+		ids := make([]string, 0, len(current))
+		for id := range current {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		a.input("enable_lifecycle_rules", "false", "The existing lifecycle rules differ from the module's and stay unmanaged")
+		a.Unmanaged = append(a.Unmanaged, fmt.Sprintf("lifecycle rules %s differ from the module's and stay as they are; set enable_lifecycle_rules = true to replace them with transitions at 90, 180 and 365 days", strings.Join(ids, ", ")))
+		return
+	}
+
+	a.imports(resource)
+	for _, id := range []string{"transition-to-ia", "expire-old-versions"} {
+		a.compare(resource, "rule."+id, current[id], expected[id])
+	}
+}
+
+func planLogging(a *Adoption, b *Bucket) {
+	const resource = "aws_s3_bucket_logging.audit_logs[0]"
+	if b.Logging == nil {
+		return
+	}
+	a.imports(resource)
+	a.input("access_log_bucket", quote(aws.StringValue(b.Logging.TargetBucket)), "")
+	a.compare(resource, "target_prefix", aws.StringValue(b.Logging.TargetPrefix), "audit-logs-access/")
+}
+
+func planReplication(a *Adoption, b *Bucket) {
+	const resource = "aws_s3_bucket_replication_configuration.audit_logs[0]"
+	if b.Replication == nil || len(b.Replication.Rules) == 0 {
+		return
+	}
+
+	var kept *s3.ReplicationRule
+	for _, rule := range b.Replication.Rules {
+		if aws.StringValue(rule.Status) == s3.ReplicationRuleStatusEnabled && rule.Destination != nil {
+			kept = rule
+			break
+		}
+	}
+	if kept == nil {
+		a.Unmanaged = append(a.Unmanaged, "replication has no enabled rule and stays unmanaged; set replication_bucket_arn to let the module replace it")
+		return
+	}
+
+	a.imports(resource)
+	destination := kept.Destination
+	a.input("replication_bucket_arn", quote(aws.StringValue(destination.Bucket)), "")
+	a.input("replication_role_arn", quote(aws.StringValue(b.Replication.Role)), "")
+	if encryption := destination.EncryptionConfiguration; encryption != nil && aws.StringValue(encryption.ReplicaKmsKeyID) != "" {
+		a.input("replication_kms_key_id", quote(aws.StringValue(encryption.ReplicaKmsKeyID)), "")
+	}
+
+	storageClass := aws.StringValue(destination.StorageClass)
+	if storageClass == "" {
+		storageClass = "source storage class"
+	}
+	replicationTime, metrics := none, none
+	if destination.ReplicationTime != nil && destination.ReplicationTime.Time != nil && aws.StringValue(destination.ReplicationTime.Status) == "Enabled" {
+		replicationTime = fmt.Sprintf("%d minutes", aws.Int64Value(destination.ReplicationTime.Time.Minutes))
+	}
+	if destination.Metrics != nil && aws.StringValue(destination.Metrics.Status) == "Enabled" {
+		metrics = "enabled"
+	}
+
+	a.compare(resource, "rule.id", aws.StringValue(kept.ID), "replicate-all")
+	a.compare(resource, "rule.destination.storage_class", storageClass, "STANDARD_IA")
+	a.compare(resource, "rule.destination.replication_time", replicationTime, "15 minutes")
+	a.compare(resource, "rule.destination.metrics", metrics, "enabled")
+	for _, rule := range b.Replication.Rules {
+		if rule != kept {
+			a.compare(resource, "rule."+aws.StringValue(rule.ID), aws.StringValue(rule.Status), "(removed)")
+		}
+	}
+}
+
+func unique(values []string) []string {
+	seen := map[string]bool{}
+	var result []string
+	for _, value := range values {
+		if value != "" && value != "*" && !seen[value] {
+			seen[value] = true
+			result = append(result, value)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
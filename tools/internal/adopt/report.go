@@ -0,0 +1,40 @@
+package adopt
+
+import (
+	"github.com/auditledger/auditledger-terraform/tools/internal/report"
+)
+
+// Report lists the adoption as checks: blockers and settings the next apply would change fail,
+// settings the module keeps and resources it adds pass, and configuration it leaves alone is skipped
+func (a *Adoption) Report() *report.Report {
+	r := report.New("aws", a.Bucket)
+	r.Tool = "auditledger-adopt"
+
+	for _, blocker := range a.Blockers {
+		r.Failf("adoption", "%s", blocker)
+	}
+	for _, setting := range a.Settings {
+		check := report.Check{
+			Name:     setting.Resource + " " + setting.Name,
+			Status:   report.Pass,
+			Message:  "kept as it is",
+			Expected: setting.Planned,
+			Actual:   setting.Current,
+		}
+		if setting.Changed() {
+			check.Status = report.Fail
+			check.Message = "changed by the next apply"
+		}
+		if setting.Note != "" {
+			check.Message += "; " + setting.Note
+		}
+		r.Add(check)
+	}
+	for _, resource := range a.Creates {
+		r.Passf(resource, "created by the next apply")
+	}
+	for _, unmanaged := range a.Unmanaged {
+		r.Skipf("unmanaged", "%s", unmanaged)
+	}
+	return r
+}
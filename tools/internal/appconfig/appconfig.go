@@ -0,0 +1,346 @@
+// Package appconfig renders the AuditLedger application storage configuration from the
+// app_configuration output of the S3 and Azure Blob modules, after checking it against the
+// immutability, encryption and replication outputs of the same module
+package appconfig
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/tfoutputs"
+)
+
+// Providers as named in the AuditLedger Storage:Provider setting
+const (
+	ProviderAwsS3     = "AwsS3"
+	ProviderAzureBlob = "AzureBlob"
+)
+
+// Request headers the S3 bucket policy requires on every write
+const (
+	HeaderObjectLockMode       = "x-amz-object-lock-mode"
+	HeaderServerSideEncryption = "x-amz-server-side-encryption"
+	HeaderKMSKeyID             = "x-amz-server-side-encryption-aws-kms-key-id"
+)
+
+var storageAccountName = regexp.MustCompile(`^[a-z0-9]{3,24}$`)
+
+// Storage is the AuditLedger:Storage configuration section
+type Storage struct {
+	Provider  string     `json:"Provider"`
+	AwsS3     *AwsS3     `json:"AwsS3,omitempty"`
+	AzureBlob *AzureBlob `json:"AzureBlob,omitempty"`
+}
+
+// Header is a request header AuditLedger sends with every write
+type Header struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+// AwsS3 configures the S3 storage provider
+type AwsS3 struct {
+	BucketName           string   `json:"BucketName"`
+	Region               string   `json:"Region"`
+	Prefix               string   `json:"Prefix"`
+	ObjectLockMode       string   `json:"ObjectLockMode"`
+	RetentionDays        int      `json:"RetentionDays"`
+	ServerSideEncryption string   `json:"ServerSideEncryption"`
+	KmsKeyID             string   `json:"KmsKeyId,omitempty"`
+	RequiredHeaders      []Header `json:"RequiredHeaders"`
+	ReservedPrefixes     []string `json:"ReservedPrefixes"`
+}
+
+// AzureBlob configures the Azure Blob storage provider
+type AzureBlob struct {
+	AccountName              string `json:"AccountName"`
+	ContainerName            string `json:"ContainerName"`
+	BlobEndpoint             string `json:"BlobEndpoint"`
+	UseAzurite               bool   `json:"UseAzurite"`
+	Authentication           string `json:"Authentication"`
+	Prefix                   string `json:"Prefix"`
+	RetentionDays            int    `json:"RetentionDays"`
+	ImmutabilityPolicyLocked bool   `json:"ImmutabilityPolicyLocked"`
+	VersionLevelImmutability bool   `json:"VersionLevelImmutability"`
+	ProtectedAppendWrites    bool   `json:"ProtectedAppendWrites"`
+}
+
+// ValidationError lists every problem found in a configuration
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid application configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+type problems []string
+
+func (p *problems) addf(format string, args ...interface{}) {
+	*p = append(*p, fmt.Sprintf(format, args...))
+}
+
+func (p problems) err() error {
+	if len(p) == 0 {
+		return nil
+	}
+	return &ValidationError{Problems: p}
+}
+
+// FromOutputs reads app_configuration.settings from the outputs of an S3 or Azure Blob
+// module and validates it, including against the module's other outputs. A
+// *ValidationError is returned when the settings are present but invalid
+func FromOutputs(outputs tfoutputs.Outputs) (*Storage, error) {
+	if !outputs.Has("app_configuration") {
+		return nil, fmt.Errorf("output %q not found; the module is older than app_configuration or the outputs are not those of an S3 or Azure Blob module", "app_configuration")
+	}
+
+	var appConfiguration struct {
+		Settings *Storage `json:"settings"`
+	}
+	if err := outputs.Decode("app_configuration", &appConfiguration); err != nil {
+		return nil, err
+	}
+	if appConfiguration.Settings == nil {
+		return nil, fmt.Errorf("output %q has no settings; pass the outputs of the S3 or Azure Blob module (the aws or azure output of auditledger-storage)", "app_configuration")
+	}
+
+	storage := appConfiguration.Settings
+	p := storage.problems()
+	switch {
+	case storage.AwsS3 != nil:
+		p = append(p, checkS3Outputs(storage.AwsS3, outputs)...)
+	case storage.AzureBlob != nil:
+		p = append(p, checkAzureOutputs(storage.AzureBlob, outputs)...)
+	}
+	return storage, p.err()
+}
+
+// Validate checks the settings on their own; FromOutputs also checks them against the module
+func (s *Storage) Validate() error {
+	return s.problems().err()
+}
+
+func (s *Storage) problems() problems {
+	var p problems
+	switch s.Provider {
+	case ProviderAwsS3:
+		if s.AwsS3 == nil {
+			p.addf("Provider is %s but the AwsS3 section is missing", ProviderAwsS3)
+			return p
+		}
+		p = append(p, s.AwsS3.problems()...)
+	case ProviderAzureBlob:
+		if s.AzureBlob == nil {
+			p.addf("Provider is %s but the AzureBlob section is missing", ProviderAzureBlob)
+			return p
+		}
+		p = append(p, s.AzureBlob.problems()...)
+	default:
+		p.addf("Provider %q is not %s or %s", s.Provider, ProviderAwsS3, ProviderAzureBlob)
+	}
+	return p
+}
+
+// ExpectedHeaders returns the headers the bucket policy requires for these settings
+func (s *AwsS3) ExpectedHeaders() []Header {
+	headers := []Header{
+		{Name: HeaderObjectLockMode, Value: s.ObjectLockMode},
+		{Name: HeaderServerSideEncryption, Value: s.ServerSideEncryption},
+	}
+	if s.KmsKeyID != "" {
+		headers = append(headers, Header{Name: HeaderKMSKeyID, Value: s.KmsKeyID})
+	}
+	return headers
+}
+
+func (s *AwsS3) problems() problems {
+	var p problems
+	if s.BucketName == "" {
+		p.addf("AwsS3.BucketName is empty")
+	}
+	if s.Region == "" {
+		p.addf("AwsS3.Region is empty")
+	}
+	if s.ObjectLockMode != "GOVERNANCE" && s.ObjectLockMode != "COMPLIANCE" {
+		p.addf("AwsS3.ObjectLockMode %q is not GOVERNANCE or COMPLIANCE", s.ObjectLockMode)
+	}
+	if s.RetentionDays <= 0 {
+		p.addf("AwsS3.RetentionDays %d is not positive", s.RetentionDays)
+	}
+
+	switch s.ServerSideEncryption {
+	case "aws:kms":
+		if s.KmsKeyID == "" {
+			p.addf("AwsS3.KmsKeyId is empty with aws:kms encryption; uploads would use the aws/s3 key instead of the bucket key")
+		}
+	case "AES256":
+		if s.KmsKeyID != "" {
+			p.addf("AwsS3.KmsKeyId is set with AES256 encryption")
+		}
+	default:
+		p.addf("AwsS3.ServerSideEncryption %q is not aws:kms or AES256", s.ServerSideEncryption)
+	}
+
+	sent := map[string]string{}
+	for _, header := range s.RequiredHeaders {
+		sent[strings.ToLower(header.Name)] = header.Value
+	}
+	for _, header := range s.ExpectedHeaders() {
+		value, ok := sent[header.Name]
+		switch {
+		case !ok:
+			p.addf("AwsS3.RequiredHeaders is missing %s; the bucket policy denies writes without it", header.Name)
+		case value != header.Value:
+			p.addf("AwsS3.RequiredHeaders sends %s %q, want %q", header.Name, value, header.Value)
+		}
+	}
+
+	p = append(p, prefixProblems("AwsS3.Prefix", s.Prefix)...)
+	for _, reserved := range s.ReservedPrefixes {
+		if reserved != "" && strings.HasPrefix(s.Prefix, reserved) {
+			p.addf("AwsS3.Prefix %q is under the reserved prefix %q", s.Prefix, reserved)
+		}
+	}
+	return p
+}
+
+func (s *AzureBlob) problems() problems {
+	var p problems
+	if !storageAccountName.MatchString(s.AccountName) {
+		p.addf("AzureBlob.AccountName %q is not a storage account name", s.AccountName)
+	}
+	if s.ContainerName == "" {
+		p.addf("AzureBlob.ContainerName is empty")
+	}
+	if !strings.HasPrefix(s.BlobEndpoint, "https://") {
+		p.addf("AzureBlob.BlobEndpoint %q is not an https:// endpoint", s.BlobEndpoint)
+	}
+	if s.UseAzurite {
+		p.addf("AzureBlob.UseAzurite is true; Azurite does not enforce immutability")
+	}
+	if s.Authentication != "EntraId" {
+		p.addf("AzureBlob.Authentication %q is not EntraId; the module grants an RBAC role, not keys", s.Authentication)
+	}
+	if s.RetentionDays <= 0 {
+		p.addf("AzureBlob.RetentionDays %d is not positive", s.RetentionDays)
+	}
+	return append(p, prefixProblems("AzureBlob.Prefix", s.Prefix)...)
+}
+
+func prefixProblems(name, prefix string) problems {
+	var p problems
+	if prefix == "" {
+		return p
+	}
+	if !strings.HasSuffix(prefix, "/") {
+		p.addf("%s %q does not end with /", name, prefix)
+	}
+	if strings.HasPrefix(prefix, "/") {
+		p.addf("%s %q starts with /", name, prefix)
+	}
+	return p
+}
+
+func checkS3Outputs(s *AwsS3, outputs tfoutputs.Outputs) problems {
+	var p problems
+	if bucket := outputs.String("bucket_id"); bucket != "" && bucket != s.BucketName {
+		p.addf("AwsS3.BucketName %q is not the module bucket %q", s.BucketName, bucket)
+	}
+
+	var lock struct {
+		Mode          string `json:"mode"`
+		RetentionDays int    `json:"retention_days"`
+	}
+	if err := outputs.Decode("object_lock_configuration", &lock); err != nil {
+		p.addf("%v", err)
+	}
+	if lock.Mode != "" && lock.Mode != s.ObjectLockMode {
+		p.addf("AwsS3.ObjectLockMode %q is not the bucket lock mode %q", s.ObjectLockMode, lock.Mode)
+	}
+	if lock.RetentionDays != 0 && lock.RetentionDays != s.RetentionDays {
+		p.addf("AwsS3.RetentionDays %d is not the bucket retention %d", s.RetentionDays, lock.RetentionDays)
+	}
+
+	var encryption struct {
+		SSEAlgorithm string  `json:"sse_algorithm"`
+		KMSKeyID     *string `json:"kms_key_id"`
+	}
+	if err := outputs.Decode("encryption_configuration", &encryption); err != nil {
+		p.addf("%v", err)
+	}
+	if encryption.SSEAlgorithm != "" {
+		if encryption.SSEAlgorithm != s.ServerSideEncryption {
+			p.addf("AwsS3.ServerSideEncryption %q is not the bucket encryption %q", s.ServerSideEncryption, encryption.SSEAlgorithm)
+		}
+		if keyID := stringValue(encryption.KMSKeyID); keyID != s.KmsKeyID {
+			p.addf("AwsS3.KmsKeyId %q is not the bucket key %q", s.KmsKeyID, keyID)
+		}
+	}
+
+	var manifest struct {
+		Prefix string `json:"prefix"`
+	}
+	if err := outputs.Decode("manifest_configuration", &manifest); err != nil {
+		p.addf("%v", err)
+	}
+	if manifest.Prefix != "" && strings.HasPrefix(s.Prefix, manifest.Prefix) {
+		p.addf("AwsS3.Prefix %q is under the digest manifest prefix %q", s.Prefix, manifest.Prefix)
+	}
+	return p
+}
+
+func checkAzureOutputs(s *AzureBlob, outputs tfoutputs.Outputs) problems {
+	var p problems
+	if account := outputs.String("storage_account_name"); account != "" && account != s.AccountName {
+		p.addf("AzureBlob.AccountName %q is not the module account %q", s.AccountName, account)
+	}
+	if container := outputs.String("container_name"); container != "" && container != s.ContainerName {
+		p.addf("AzureBlob.ContainerName %q is not the module container %q", s.ContainerName, container)
+	}
+
+	var immutability struct {
+		PeriodDays int   `json:"immutability_period_days"`
+		Locked     *bool `json:"immutability_policy_locked"`
+	}
+	if err := outputs.Decode("immutability_configuration", &immutability); err != nil {
+		p.addf("%v", err)
+	}
+	if immutability.PeriodDays != 0 && immutability.PeriodDays != s.RetentionDays {
+		p.addf("AzureBlob.RetentionDays %d is not the container immutability period %d", s.RetentionDays, immutability.PeriodDays)
+	}
+	if immutability.Locked != nil && *immutability.Locked != s.ImmutabilityPolicyLocked {
+		p.addf("AzureBlob.ImmutabilityPolicyLocked %t does not match the container policy", s.ImmutabilityPolicyLocked)
+	}
+
+	var replication struct {
+		Enabled       bool     `json:"enabled"`
+		PrefixFilters []string `json:"prefix_filters"`
+	}
+	if err := outputs.Decode("replication_configuration", &replication); err != nil {
+		p.addf("%v", err)
+	}
+	if replication.Enabled && len(replication.PrefixFilters) > 0 && !coveredBy(s.Prefix, replication.PrefixFilters) {
+		p.addf("AzureBlob.Prefix %q is not covered by the replication prefix filters %s, so audit logs would not be replicated", s.Prefix, strings.Join(replication.PrefixFilters, ", "))
+	}
+	return p
+}
+
+// coveredBy reports whether every blob under prefix matches one of the replication filters,
+// which are container-relative blob name prefixes
+func coveredBy(prefix string, filters []string) bool {
+	for _, filter := range filters {
+		if strings.HasPrefix(prefix, filter) {
+			return true
+		}
+	}
+	return false
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,192 @@
+package appconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/tfoutputs"
+)
+
+func loadOutputs(t *testing.T, path string) tfoutputs.Outputs {
+	t.Helper()
+	outputs, err := tfoutputs.Load(path)
+	require.NoError(t, err)
+	return outputs
+}
+
+// moduleEnv is the env map the module computed for the fixture
+func moduleEnv(t *testing.T, outputs tfoutputs.Outputs) map[string]string {
+	t.Helper()
+	var appConfiguration struct {
+		Env map[string]string `json:"env"`
+	}
+	require.NoError(t, outputs.Decode("app_configuration", &appConfiguration))
+	return appConfiguration.Env
+}
+
+// setOutput replaces an output value in the fixture
+func setOutput(t *testing.T, outputs tfoutputs.Outputs, name string, value interface{}) {
+	t.Helper()
+	data, err := json.Marshal(value)
+	require.NoError(t, err)
+	outputs[name] = data
+}
+
+func problemsOf(t *testing.T, err error) string {
+	t.Helper()
+	var validation *ValidationError
+	require.ErrorAs(t, err, &validation)
+	return strings.Join(validation.Problems, "\n")
+}
+
+func TestFromOutputsS3(t *testing.T) {
+	outputs := loadOutputs(t, "testdata/s3_outputs.json")
+
+	storage, err := FromOutputs(outputs)
+	require.NoError(t, err)
+	require.NotNil(t, storage.AwsS3)
+	assert.Equal(t, "prod-audit-logs", storage.AwsS3.BucketName)
+	assert.Equal(t, storage.AwsS3.ExpectedHeaders(), storage.AwsS3.RequiredHeaders)
+
+	env, err := storage.Env()
+	require.NoError(t, err)
+	assert.Equal(t, moduleEnv(t, outputs), env)
+}
+
+func TestFromOutputsAzure(t *testing.T) {
+	outputs := loadOutputs(t, "testdata/azure_outputs.json")
+
+	storage, err := FromOutputs(outputs)
+	require.NoError(t, err)
+	require.NotNil(t, storage.AzureBlob)
+
+	env, err := storage.Env()
+	require.NoError(t, err)
+	assert.Equal(t, moduleEnv(t, outputs), env)
+}
+
+func TestFromOutputsMissing(t *testing.T) {
+	_, err := FromOutputs(tfoutputs.Outputs{"bucket_id": json.RawMessage(`"logs"`)})
+	assert.ErrorContains(t, err, `output "app_configuration" not found`)
+
+	// auditledger-storage exposes only the rendered forms
+	_, err = FromOutputs(tfoutputs.Outputs{"app_configuration": json.RawMessage(`{"json": "{}", "yaml": "", "env": {}}`)})
+	assert.ErrorContains(t, err, "has no settings")
+}
+
+func TestValidateS3(t *testing.T) {
+	storage, err := FromOutputs(loadOutputs(t, "testdata/s3_outputs.json"))
+	require.NoError(t, err)
+
+	s3 := storage.AwsS3
+	s3.Prefix = "_manifests/orders"
+	s3.ObjectLockMode = "LEGAL_HOLD"
+	s3.RequiredHeaders = s3.RequiredHeaders[1:2]
+
+	problems := problemsOf(t, storage.Validate())
+	assert.Contains(t, problems, `AwsS3.Prefix "_manifests/orders" does not end with /`)
+	assert.Contains(t, problems, `under the reserved prefix "_manifests/"`)
+	assert.Contains(t, problems, `ObjectLockMode "LEGAL_HOLD"`)
+	assert.Contains(t, problems, "missing x-amz-object-lock-mode")
+	assert.Contains(t, problems, "missing x-amz-server-side-encryption-aws-kms-key-id")
+
+	storage.AwsS3 = &AwsS3{BucketName: "logs", Region: "us-east-1", ObjectLockMode: "GOVERNANCE", RetentionDays: 1, ServerSideEncryption: "aws:kms"}
+	storage.AwsS3.RequiredHeaders = storage.AwsS3.ExpectedHeaders()
+	assert.Contains(t, problemsOf(t, storage.Validate()), "AwsS3.KmsKeyId is empty with aws:kms encryption")
+}
+
+func TestFromOutputsS3Drift(t *testing.T) {
+	outputs := loadOutputs(t, "testdata/s3_outputs.json")
+	setOutput(t, outputs, "object_lock_configuration", map[string]interface{}{"mode": "GOVERNANCE", "retention_days": 365})
+	setOutput(t, outputs, "encryption_configuration", map[string]interface{}{"sse_algorithm": "AES256", "kms_key_id": nil})
+
+	_, err := FromOutputs(outputs)
+	problems := problemsOf(t, err)
+	assert.Contains(t, problems, `AwsS3.ObjectLockMode "COMPLIANCE" is not the bucket lock mode "GOVERNANCE"`)
+	assert.Contains(t, problems, "AwsS3.RetentionDays 2555 is not the bucket retention 365")
+	assert.Contains(t, problems, `AwsS3.ServerSideEncryption "aws:kms" is not the bucket encryption "AES256"`)
+}
+
+func TestFromOutputsAzureReplicationPrefix(t *testing.T) {
+	outputs := loadOutputs(t, "testdata/azure_outputs.json")
+	setOutput(t, outputs, "replication_configuration", map[string]interface{}{"enabled": true, "prefix_filters": []string{"billing/"}})
+
+	_, err := FromOutputs(outputs)
+	assert.Contains(t, problemsOf(t, err), `AzureBlob.Prefix "tenants/contoso/" is not covered by the replication prefix filters billing/`)
+
+	setOutput(t, outputs, "replication_configuration", map[string]interface{}{"enabled": false, "prefix_filters": []string{"billing/"}})
+	_, err = FromOutputs(outputs)
+	assert.NoError(t, err)
+}
+
+func TestValidateAzure(t *testing.T) {
+	storage := &Storage{Provider: ProviderAzureBlob, AzureBlob: &AzureBlob{
+		AccountName:    "Audit_Logs",
+		ContainerName:  "audit-logs",
+		BlobEndpoint:   "http://127.0.0.1:10000/devstoreaccount1/",
+		UseAzurite:     true,
+		Authentication: "AccountKey",
+		RetentionDays:  30,
+	}}
+
+	problems := problemsOf(t, storage.Validate())
+	assert.Contains(t, problems, `AzureBlob.AccountName "Audit_Logs" is not a storage account name`)
+	assert.Contains(t, problems, "is not an https:// endpoint")
+	assert.Contains(t, problems, "AzureBlob.UseAzurite is true")
+	assert.Contains(t, problems, `AzureBlob.Authentication "AccountKey" is not EntraId`)
+
+	assert.Contains(t, problemsOf(t, (&Storage{Provider: "GcpStorage"}).Validate()), `Provider "GcpStorage"`)
+}
+
+func TestWriteJSON(t *testing.T) {
+	storage, err := FromOutputs(loadOutputs(t, "testdata/azure_outputs.json"))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, storage.Write(&buf, "json"))
+
+	var doc map[string]map[string]map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &doc))
+	assert.Equal(t, "AzureBlob", doc["AuditLedger"]["Storage"]["Provider"])
+	assert.NotContains(t, buf.String(), "AwsS3")
+}
+
+func TestWriteYAML(t *testing.T) {
+	storage, err := FromOutputs(loadOutputs(t, "testdata/s3_outputs.json"))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, storage.Write(&buf, "yaml"))
+	assert.True(t, strings.HasPrefix(buf.String(), "AuditLedger:\n  Storage:\n    Provider: AwsS3\n    AwsS3:\n      BucketName: prod-audit-logs\n"), buf.String())
+	assert.Contains(t, buf.String(), "      RetentionDays: 2555\n")
+	assert.Contains(t, buf.String(), "        - Name: x-amz-object-lock-mode\n          Value: COMPLIANCE\n")
+}
+
+func TestWriteEnv(t *testing.T) {
+	storage := &Storage{Provider: ProviderAwsS3, AwsS3: &AwsS3{
+		BucketName:           "logs",
+		Region:               "eu-west-1",
+		Prefix:               "it's/",
+		ObjectLockMode:       "GOVERNANCE",
+		RetentionDays:        30,
+		ServerSideEncryption: "AES256",
+	}}
+	storage.AwsS3.RequiredHeaders = storage.AwsS3.ExpectedHeaders()
+
+	var buf bytes.Buffer
+	require.NoError(t, storage.Write(&buf, "env"))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Equal(t, "AuditLedger__Storage__AwsS3__BucketName='logs'", lines[0])
+	assert.Contains(t, lines, `AuditLedger__Storage__AwsS3__Prefix='it'\''s/'`)
+	assert.Contains(t, lines, "AuditLedger__Storage__AwsS3__RequiredHeaders__1__Value='AES256'")
+	assert.NotContains(t, buf.String(), "ReservedPrefixes")
+	assert.NotContains(t, buf.String(), "KmsKeyId")
+
+	assert.ErrorContains(t, storage.Write(&buf, "toml"), `unsupported format "toml"`)
+}
@@ -0,0 +1,148 @@
+package appconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Formats lists the supported output formats
+var Formats = []string{"json", "yaml", "env"}
+
+// document is the appsettings layout the module outputs: {"AuditLedger": {"Storage": ...}}
+type document struct {
+	AuditLedger struct {
+		Storage *Storage `json:"Storage"`
+	} `json:"AuditLedger"`
+}
+
+// Write renders the settings in the given format
+func (s *Storage) Write(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		return s.WriteJSON(w)
+	case "yaml":
+		return s.WriteYAML(w)
+	case "env":
+		return s.WriteEnv(w)
+	default:
+		return fmt.Errorf("unsupported format %q (use json, yaml or env)", format)
+	}
+}
+
+func (s *Storage) document() document {
+	var doc document
+	doc.AuditLedger.Storage = s
+	return doc
+}
+
+// WriteJSON writes an appsettings.json document
+func (s *Storage) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(s.document())
+}
+
+// WriteYAML writes the appsettings document as YAML, keeping the key order of the JSON form
+func (s *Storage) WriteYAML(w io.Writer) error {
+	data, err := json.Marshal(s.document())
+	if err != nil {
+		return err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&node); err != nil {
+		return err
+	}
+	return encoder.Close()
+}
+
+// blockStyle drops the flow and quoting styles the JSON source gives every node
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+// Env returns the settings as .NET configuration environment variables: sections
+// separated by __, list items by their index, null values left out. The keys match the
+// env map of the module's app_configuration output
+func (s *Storage) Env() (map[string]string, error) {
+	data, err := json.Marshal(s.document())
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	env := map[string]string{}
+	flatten(env, "", value)
+	return env, nil
+}
+
+func flatten(env map[string]string, key string, value interface{}) {
+	join := func(name string) string {
+		if key == "" {
+			return name
+		}
+		return key + "__" + name
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, child := range v {
+			flatten(env, join(name), child)
+		}
+	case []interface{}:
+		for i, child := range v {
+			flatten(env, join(strconv.Itoa(i)), child)
+		}
+	case nil:
+	case string:
+		env[key] = v
+	default:
+		env[key] = fmt.Sprint(v)
+	}
+}
+
+// WriteEnv writes the environment variables as sorted, single-quoted KEY='value' lines
+// that both shells and .env loaders accept
+func (s *Storage) WriteEnv(w io.Writer) error {
+	env, err := s.Env()
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		quoted := "'" + strings.ReplaceAll(env[key], "'", `'\''`) + "'"
+		if _, err := fmt.Fprintf(w, "%s=%s\n", key, quoted); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,111 @@
+// Package arm is a minimal Azure Resource Manager client for reading resources by ID.
+//
+// It only issues GET requests and decodes JSON. Tests point Endpoint at an
+// httptest server (or recorded responses) so no Azure access is needed.
+package arm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultEndpoint is the Azure public cloud Resource Manager endpoint
+const DefaultEndpoint = "https://management.azure.com"
+
+// Getter reads an ARM resource by ID and decodes its JSON body into out
+type Getter interface {
+	Get(ctx context.Context, resourceID, apiVersion string, out interface{}) error
+}
+
+// Client reads resources from Resource Manager over HTTP
+type Client struct {
+	Endpoint   string
+	Token      TokenSource
+	HTTPClient *http.Client
+}
+
+// NotFoundError is returned when Resource Manager answers 404
+type NotFoundError struct {
+	ResourceID string
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("resource %s not found", e.ResourceID)
+}
+
+// IsNotFound reports whether err is a NotFoundError
+func IsNotFound(err error) bool {
+	var notFound *NotFoundError
+	return errors.As(err, &notFound)
+}
+
+// Get implements Getter
+func (c *Client) Get(ctx context.Context, resourceID, apiVersion string, out interface{}) error {
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = DefaultEndpoint
+	}
+
+	requestURL := strings.TrimSuffix(endpoint, "/") + "/" + strings.TrimPrefix(resourceID, "/") + "?api-version=" + url.QueryEscape(apiVersion)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, requestURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	if c.Token != nil {
+		token, err := c.Token.Token(ctx)
+		if err != nil {
+			return fmt.Errorf("getting Azure access token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return &NotFoundError{ResourceID: resourceID}
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("GET %s: %s", resourceID, errorMessage(resp.Status, body))
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("decoding %s: %w", resourceID, err)
+	}
+	return nil
+}
+
+// errorMessage extracts the ARM error envelope, falling back to the HTTP status
+func errorMessage(status string, body []byte) string {
+	var envelope struct {
+		Error struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &envelope) == nil && envelope.Error.Code != "" {
+		return fmt.Sprintf("%s: %s", envelope.Error.Code, envelope.Error.Message)
+	}
+	return status
+}
@@ -0,0 +1,54 @@
+package arm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const accountID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/audit/providers/Microsoft.Storage/storageAccounts/auditlogs"
+
+func TestClientGet(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, accountID, r.URL.Path)
+		assert.Equal(t, "2023-01-01", r.URL.Query().Get("api-version"))
+		assert.Equal(t, "Bearer test-token", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"name": "auditlogs"}`))
+	}))
+	defer server.Close()
+
+	client := &Client{Endpoint: server.URL + "/", Token: StaticToken("test-token")}
+
+	var account struct {
+		Name string `json:"name"`
+	}
+	require.NoError(t, client.Get(context.Background(), accountID, "2023-01-01", &account))
+	assert.Equal(t, "auditlogs", account.Name)
+}
+
+func TestClientErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == accountID {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"error": {"code": "AuthorizationFailed", "message": "no read access"}}`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := &Client{Endpoint: server.URL}
+	var out map[string]interface{}
+
+	err := client.Get(context.Background(), accountID, "2023-01-01", &out)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "AuthorizationFailed: no read access")
+	assert.False(t, IsNotFound(err))
+
+	err = client.Get(context.Background(), accountID+"/blobServices/default", "2023-01-01", &out)
+	assert.True(t, IsNotFound(err))
+}
@@ -0,0 +1,73 @@
+package arm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+// StorageResource is the token audience for the Blob service data plane
+const StorageResource = "https://storage.azure.com/"
+
+// TokenSource provides bearer tokens for Resource Manager or the storage data plane
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// StaticToken is a pre-issued access token, e.g. from AZURE_ACCESS_TOKEN
+type StaticToken string
+
+// Token implements TokenSource
+func (t StaticToken) Token(context.Context) (string, error) {
+	return string(t), nil
+}
+
+// CLIToken asks the Azure CLI for a token, reusing the signed-in `az login` session
+type CLIToken struct {
+	// Subscription selects the subscription whose tenant issues the token (optional)
+	Subscription string
+	// Resource is the token audience (default: Resource Manager)
+	Resource string
+
+	mu    sync.Mutex
+	token string
+}
+
+// Token implements TokenSource; the token is fetched once and cached for the run
+func (t *CLIToken) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.token != "" {
+		return t.token, nil
+	}
+
+	resource := t.Resource
+	if resource == "" {
+		resource = DefaultEndpoint + "/"
+	}
+	args := []string{"account", "get-access-token", "--resource", resource, "--output", "json"}
+	if t.Subscription != "" {
+		args = append(args, "--subscription", t.Subscription)
+	}
+
+	out, err := exec.CommandContext(ctx, "az", args...).Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return "", fmt.Errorf("az account get-access-token: %s", strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return "", fmt.Errorf("az account get-access-token: %w (install the Azure CLI or set AZURE_ACCESS_TOKEN)", err)
+	}
+
+	var response struct {
+		AccessToken string `json:"accessToken"`
+	}
+	if err := json.Unmarshal(out, &response); err != nil {
+		return "", fmt.Errorf("decoding az account get-access-token output: %w", err)
+	}
+	t.token = response.AccessToken
+	return t.token, nil
+}
@@ -0,0 +1,231 @@
+package evidence
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/signing"
+)
+
+// Files that describe the bundle rather than belong to it
+const (
+	ManifestFile  = "manifest.json"
+	SignatureFile = "manifest.json.sig"
+	PublicKeyFile = "signing-key.pub.pem"
+)
+
+// Pack is the evidence gathered from one state
+type Pack struct {
+	GeneratedAt time.Time
+	Source      Source
+	Mapping     *Mapping
+	Targets     []Target
+}
+
+// Source describes the `terraform show -json` document the evidence came from
+type Source struct {
+	Path             string `json:"path"`
+	SHA256           string `json:"sha256"`
+	TerraformVersion string `json:"terraform_version"`
+}
+
+// NewSource fingerprints the state document
+func NewSource(path string, data []byte, terraformVersion string) Source {
+	sum := sha256.Sum256(data)
+	return Source{Path: path, SHA256: hex.EncodeToString(sum[:]), TerraformVersion: terraformVersion}
+}
+
+// BundleFile is a file in the bundle and its digest
+type BundleFile struct {
+	Path   string `json:"path"`
+	SHA256 string `json:"sha256"`
+	Size   int    `json:"size"`
+}
+
+// Manifest lists every file in the bundle; its signature covers the whole bundle
+type Manifest struct {
+	Tool                  string       `json:"tool"`
+	GeneratedAt           time.Time    `json:"generated_at"`
+	Source                Source       `json:"source"`
+	Frameworks            []string     `json:"frameworks"`
+	Targets               []Target     `json:"targets"`
+	Evidence              []ItemRef    `json:"evidence"`
+	Files                 []BundleFile `json:"files"`
+	SigningKeyFingerprint string       `json:"signing_key_fingerprint"`
+}
+
+// ItemRef indexes an evidence file in the manifest
+type ItemRef struct {
+	Target   string       `json:"target"`
+	Category string       `json:"category"`
+	Status   string       `json:"status"`
+	File     string       `json:"file"`
+	Controls []ControlRef `json:"controls"`
+}
+
+// WriteBundle writes the evidence, mapping and summaries as a zip with a signed manifest
+func WriteBundle(w io.Writer, pack *Pack, key ed25519.PrivateKey) (*Manifest, error) {
+	files := map[string][]byte{}
+	manifest := &Manifest{
+		Tool:                  "auditledger-evidence",
+		GeneratedAt:           pack.GeneratedAt.UTC(),
+		Source:                pack.Source,
+		Frameworks:            pack.Mapping.FrameworkIDs(),
+		Targets:               pack.Targets,
+		SigningKeyFingerprint: signing.Fingerprint(key.Public().(ed25519.PublicKey)),
+	}
+
+	for _, target := range pack.Targets {
+		for _, item := range target.Items {
+			data, err := json.MarshalIndent(item, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			files[item.File()] = append(data, '\n')
+			manifest.Evidence = append(manifest.Evidence, ItemRef{
+				Target: item.Target, Category: item.Category, Status: item.Status, File: item.File(), Controls: item.Controls,
+			})
+		}
+	}
+
+	mapping, err := json.MarshalIndent(pack.Mapping, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	files["controls.json"] = append(mapping, '\n')
+
+	markdown, err := RenderMarkdown(pack)
+	if err != nil {
+		return nil, err
+	}
+	files["summary.md"] = markdown
+
+	html, err := RenderHTML(pack)
+	if err != nil {
+		return nil, err
+	}
+	files["summary.html"] = html
+
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		sum := sha256.Sum256(files[path])
+		manifest.Files = append(manifest.Files, BundleFile{Path: path, SHA256: hex.EncodeToString(sum[:]), Size: len(files[path])})
+	}
+
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	manifestData = append(manifestData, '\n')
+
+	publicDER, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	files[ManifestFile] = manifestData
+	files[SignatureFile] = []byte(base64.StdEncoding.EncodeToString(ed25519.Sign(key, manifestData)) + "\n")
+	files[PublicKeyFile] = pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})
+	paths = append([]string{ManifestFile, SignatureFile, PublicKeyFile}, paths...)
+
+	archive := zip.NewWriter(w)
+	for _, path := range paths {
+		header := &zip.FileHeader{Name: path, Method: zip.Deflate, Modified: manifest.GeneratedAt}
+		entry, err := archive.CreateHeader(header)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := entry.Write(files[path]); err != nil {
+			return nil, err
+		}
+	}
+	return manifest, archive.Close()
+}
+
+// VerifyBundle checks the manifest signature against the public key and every file against the manifest.
+// Without a public key the key embedded in the bundle is used, which only proves integrity, not origin
+func VerifyBundle(data []byte, public ed25519.PublicKey) (*Manifest, error) {
+	archive, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("reading bundle: %w", err)
+	}
+
+	files := map[string][]byte{}
+	for _, entry := range archive.File {
+		reader, err := entry.Open()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		if _, duplicate := files[entry.Name]; duplicate {
+			return nil, fmt.Errorf("bundle contains %s twice", entry.Name)
+		}
+		files[entry.Name] = content
+	}
+
+	manifestData, ok := files[ManifestFile]
+	if !ok {
+		return nil, fmt.Errorf("bundle has no %s", ManifestFile)
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(files[SignatureFile])))
+	if err != nil || len(signature) == 0 {
+		return nil, fmt.Errorf("bundle has no valid %s", SignatureFile)
+	}
+
+	if public == nil {
+		block, _ := pem.Decode(files[PublicKeyFile])
+		if block == nil {
+			return nil, fmt.Errorf("bundle has no %s - pass the signer's public key", PublicKeyFile)
+		}
+		if public, err = signing.ParsePublicKey(block.Bytes); err != nil {
+			return nil, fmt.Errorf("%s: %w", PublicKeyFile, err)
+		}
+	}
+	if !ed25519.Verify(public, manifestData, signature) {
+		return nil, fmt.Errorf("manifest signature does not match the public key %s", signing.Fingerprint(public))
+	}
+
+	var manifest Manifest
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("decoding manifest: %w", err)
+	}
+
+	listed := map[string]bool{ManifestFile: true, SignatureFile: true, PublicKeyFile: true}
+	for _, file := range manifest.Files {
+		listed[file.Path] = true
+		content, ok := files[file.Path]
+		if !ok {
+			return nil, fmt.Errorf("%s is listed in the manifest but missing", file.Path)
+		}
+		sum := sha256.Sum256(content)
+		if hex.EncodeToString(sum[:]) != file.SHA256 {
+			return nil, fmt.Errorf("%s was modified after signing", file.Path)
+		}
+	}
+	for path := range files {
+		if !listed[path] {
+			return nil, fmt.Errorf("%s is not listed in the manifest", path)
+		}
+	}
+	return &manifest, nil
+}
@@ -0,0 +1,132 @@
+package evidence
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/signing"
+)
+
+func writeFixtureBundle(t *testing.T) ([]byte, ed25519.PublicKey) {
+	t.Helper()
+
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	pack := &Pack{
+		GeneratedAt: time.Date(2026, 1, 15, 9, 30, 0, 0, time.UTC),
+		Source:      NewSource("show.json", []byte("{}"), "1.5.7"),
+		Mapping:     DefaultMapping(),
+		Targets:     loadFixture(t),
+	}
+
+	var out bytes.Buffer
+	manifest, err := WriteBundle(&out, pack, private)
+	require.NoError(t, err)
+	assert.Len(t, manifest.Evidence, 16, "8 categories for each of the 2 targets")
+	assert.Equal(t, signing.Fingerprint(public), manifest.SigningKeyFingerprint)
+
+	return out.Bytes(), public
+}
+
+// rewrite copies a bundle, replacing (or adding) one file
+func rewrite(t *testing.T, bundle []byte, name string, content []byte) []byte {
+	t.Helper()
+
+	reader, err := zip.NewReader(bytes.NewReader(bundle), int64(len(bundle)))
+	require.NoError(t, err)
+
+	var out bytes.Buffer
+	writer := zip.NewWriter(&out)
+	replaced := false
+	for _, entry := range reader.File {
+		data := content
+		if entry.Name != name {
+			file, err := entry.Open()
+			require.NoError(t, err)
+			data, err = io.ReadAll(file)
+			require.NoError(t, err)
+		} else {
+			replaced = true
+		}
+		w, err := writer.Create(entry.Name)
+		require.NoError(t, err)
+		_, err = w.Write(data)
+		require.NoError(t, err)
+	}
+	if !replaced {
+		w, err := writer.Create(name)
+		require.NoError(t, err)
+		_, err = w.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return out.Bytes()
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	bundle, public := writeFixtureBundle(t)
+
+	manifest, err := VerifyBundle(bundle, public)
+	require.NoError(t, err)
+	assert.Equal(t, "1.5.7", manifest.Source.TerraformVersion)
+	assert.Equal(t, []string{"soc2", "hipaa", "pci_dss"}, manifest.Frameworks)
+
+	var paths []string
+	for _, file := range manifest.Files {
+		paths = append(paths, file.Path)
+	}
+	assert.Contains(t, paths, "evidence/acme-audit-logs-prod/access_policy.json")
+	assert.Contains(t, paths, "summary.md")
+	assert.Contains(t, paths, "summary.html")
+	assert.Contains(t, paths, "controls.json")
+}
+
+func TestVerifyBundleDetectsTampering(t *testing.T) {
+	bundle, public := writeFixtureBundle(t)
+
+	_, err := VerifyBundle(rewrite(t, bundle, "evidence/acme-audit-logs-prod/lock_configuration.json", []byte(`{}`)), public)
+	assert.ErrorContains(t, err, "modified after signing")
+
+	_, err = VerifyBundle(rewrite(t, bundle, "evidence/extra.json", []byte(`{}`)), public)
+	assert.ErrorContains(t, err, "not listed")
+
+	_, err = VerifyBundle(rewrite(t, bundle, ManifestFile, []byte(`{"files": []}`)), public)
+	assert.ErrorContains(t, err, "signature")
+
+	other, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, err = VerifyBundle(bundle, other)
+	assert.ErrorContains(t, err, "signature")
+}
+
+func TestVerifyBundleWithEmbeddedKey(t *testing.T) {
+	bundle, _ := writeFixtureBundle(t)
+
+	_, err := VerifyBundle(bundle, nil)
+	assert.NoError(t, err)
+}
+
+func TestSummaries(t *testing.T) {
+	pack := &Pack{GeneratedAt: time.Now(), Mapping: DefaultMapping(), Targets: loadFixture(t)}
+
+	markdown, err := RenderMarkdown(pack)
+	require.NoError(t, err)
+	assert.Contains(t, string(markdown), "## acme-audit-logs-prod")
+	assert.Contains(t, string(markdown), "| PCI DSS v4.0 | 10.5.1 | Retain audit log history for at least 12 months | retention | covered |")
+	assert.Contains(t, string(markdown), "| SOC 2 (2017 Trust Services Criteria) | A1.2 | Backup and recovery infrastructure | replication, versioning | partial |")
+	assert.Contains(t, string(markdown), "No resources in the state provide this evidence.")
+
+	html, err := RenderHTML(pack)
+	require.NoError(t, err)
+	assert.Contains(t, string(html), `<td class="covered">covered</td>`)
+	assert.Contains(t, string(html), "&#34;DenyDeleteObject&#34;", "Configuration is HTML-escaped")
+}
@@ -0,0 +1,203 @@
+// Package evidence builds signed compliance evidence bundles from the Terraform state of
+// the AuditLedger storage modules and maps each piece of evidence to framework controls
+package evidence
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/tfstate"
+)
+
+// Evidence status values
+const (
+	Configured    = "configured"
+	NotConfigured = "not_configured"
+)
+
+// source selects module resources that hold evidence for a category
+type source struct {
+	Category   string
+	Type       string
+	Name       string   // "" matches every resource of the type in the module
+	Attributes []string // nil keeps every attribute
+}
+
+// s3Sources mirrors the resources of modules/auditledger-s3
+var s3Sources = []source{
+	{"lock_configuration", "aws_s3_bucket", "audit_logs", []string{"bucket", "arn", "object_lock_enabled"}},
+	{"lock_configuration", "aws_s3_bucket_object_lock_configuration", "audit_logs", nil},
+	{"versioning", "aws_s3_bucket", "audit_logs", []string{"bucket", "versioning"}},
+	{"access_policy", "aws_s3_bucket_policy", "audit_logs", nil},
+	{"access_policy", "aws_iam_policy", "s3_access", []string{"name", "arn", "policy"}},
+	{"public_access", "aws_s3_bucket_public_access_block", "audit_logs", nil},
+	{"encryption", "aws_s3_bucket_server_side_encryption_configuration", "audit_logs", nil},
+	{"retention", "aws_s3_bucket_object_lock_configuration", "audit_logs", []string{"bucket", "rule"}},
+	{"retention", "aws_s3_bucket_lifecycle_configuration", "audit_logs", nil},
+	{"replication", "aws_s3_bucket_replication_configuration", "audit_logs", nil},
+	{"logging", "aws_s3_bucket_logging", "audit_logs", nil},
+	{"logging", "aws_s3_bucket_inventory", "audit_logs", nil},
+}
+
+// azureSources mirrors the resources of modules/auditledger-azure-blob
+var azureSources = []source{
+	{"lock_configuration", "azurerm_storage_container_immutability_policy", "audit_logs", nil},
+	{"lock_configuration", "azapi_resource_action", "version_level_worm", []string{"resource_id", "action", "body"}},
+	{"lock_configuration", "azapi_resource_action", "legal_hold", []string{"resource_id", "action", "body"}},
+	{"lock_configuration", "azurerm_management_lock", "", []string{"name", "scope", "lock_level", "notes"}},
+	{"versioning", "azurerm_storage_account", "audit_logs", []string{"name", "blob_properties"}},
+	{"access_policy", "azurerm_role_definition", "", []string{"name", "scope", "description", "permissions", "assignable_scopes"}},
+	{"access_policy", "azurerm_role_assignment", "", []string{"scope", "role_definition_id", "role_definition_name", "principal_id"}},
+	{"public_access", "azurerm_storage_account", "audit_logs", []string{"name", "public_network_access_enabled", "allow_nested_items_to_be_public", "shared_access_key_enabled", "network_rules"}},
+	{"public_access", "azurerm_private_endpoint", "blob", []string{"name", "subnet_id", "private_service_connection"}},
+	{"encryption", "azurerm_storage_account", "audit_logs", []string{"name", "https_traffic_only_enabled", "min_tls_version", "infrastructure_encryption_enabled"}},
+	{"encryption", "azurerm_storage_account_customer_managed_key", "audit_logs", nil},
+	{"encryption", "azurerm_key_vault_key", "audit_logs", []string{"name", "key_vault_id", "key_type", "key_size", "rotation_policy"}},
+	{"retention", "azurerm_storage_container_immutability_policy", "audit_logs", []string{"immutability_period_in_days", "locked"}},
+	{"retention", "azurerm_storage_account", "audit_logs", []string{"name", "blob_properties"}},
+	{"retention", "azurerm_storage_management_policy", "audit_logs", nil},
+	{"replication", "azurerm_storage_account", "audit_logs", []string{"name", "account_replication_type"}},
+	{"replication", "azurerm_storage_object_replication", "audit_logs", nil},
+	{"replication", "azurerm_storage_account", "replica", []string{"name", "location", "account_replication_type"}},
+	{"replication", "azurerm_storage_container_immutability_policy", "replica", nil},
+	{"logging", "azurerm_monitor_diagnostic_setting", "", nil},
+	{"logging", "azurerm_monitor_activity_log_alert", "", []string{"name", "scopes", "criteria", "action"}},
+	{"logging", "azurerm_advanced_threat_protection", "audit_logs", nil},
+}
+
+// provider identifies module instances by their primary resource
+type provider struct {
+	Name        string
+	PrimaryType string
+	NameAttr    string
+	Sources     []source
+}
+
+var providers = []provider{
+	{"aws", "aws_s3_bucket", "bucket", s3Sources},
+	{"azure", "azurerm_storage_account", "name", azureSources},
+}
+
+// ResourceEvidence is the recorded configuration of one resource
+type ResourceEvidence struct {
+	Address string                 `json:"address"`
+	Values  map[string]interface{} `json:"values"`
+}
+
+// Item is the evidence for one category of one target
+type Item struct {
+	Target      string             `json:"target"`
+	Provider    string             `json:"provider"`
+	Category    string             `json:"category"`
+	Description string             `json:"description"`
+	Status      string             `json:"status"`
+	Controls    []ControlRef       `json:"controls"`
+	Resources   []ResourceEvidence `json:"resources"`
+}
+
+// File is the bundle path of the item
+func (i Item) File() string {
+	return fmt.Sprintf("evidence/%s/%s.json", i.Target, i.Category)
+}
+
+// Target is a bucket or storage account created by an AuditLedger module
+type Target struct {
+	Provider      string `json:"provider"`
+	Name          string `json:"name"`
+	Module        string `json:"module"`
+	ComplianceTag string `json:"compliance_tag,omitempty"`
+	Items         []Item `json:"-"`
+}
+
+// Collect finds every AuditLedger module instance in the state and gathers its evidence
+func Collect(show *tfstate.Show, mapping *Mapping) ([]Target, error) {
+	byModule := show.ByModule()
+
+	var targets []Target
+	for module, resources := range byModule {
+		for _, p := range providers {
+			primary := tfstate.Find(resources, p.PrimaryType, "audit_logs")
+			if len(primary) == 0 {
+				continue
+			}
+			targets = append(targets, collectTarget(p, module, primary[0], resources, mapping))
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no auditledger-s3 or auditledger-azure-blob resources found in the state")
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Name < targets[j].Name })
+	return targets, nil
+}
+
+func collectTarget(p provider, module string, primary tfstate.Resource, resources []tfstate.Resource, mapping *Mapping) Target {
+	target := Target{Provider: p.Name, Name: primary.String(p.NameAttr), Module: module}
+	if tags, ok := primary.Values["tags"].(map[string]interface{}); ok {
+		target.ComplianceTag, _ = tags["Compliance"].(string)
+	}
+
+	for _, category := range mapping.CategoryNames() {
+		item := Item{
+			Target:      target.Name,
+			Provider:    p.Name,
+			Category:    category,
+			Description: mapping.Categories[category],
+			Status:      NotConfigured,
+			Controls:    mapping.ControlsFor(category),
+			Resources:   []ResourceEvidence{},
+		}
+
+		for _, s := range p.Sources {
+			if s.Category != category {
+				continue
+			}
+			for _, resource := range tfstate.Find(resources, s.Type, s.Name) {
+				item.Resources = append(item.Resources, ResourceEvidence{
+					Address: resource.Address,
+					Values:  selectValues(resource, s.Attributes),
+				})
+			}
+		}
+		if len(item.Resources) > 0 {
+			item.Status = Configured
+		}
+		target.Items = append(target.Items, item)
+	}
+	return target
+}
+
+// find returns the module's resources of a type (and name, unless empty), including every count or for_each instance
+// selectValues keeps the listed attributes, redacts sensitive ones and renders JSON documents
+func selectValues(resource tfstate.Resource, attributes []string) map[string]interface{} {
+	sensitive, _ := resource.Sensitive.(map[string]interface{})
+
+	selected := map[string]interface{}{}
+	for name, value := range resource.Values {
+		if attributes != nil && !containsString(attributes, name) {
+			continue
+		}
+		if flag, ok := sensitive[name].(bool); ok && flag {
+			selected[name] = "(sensitive)"
+			continue
+		}
+		selected[name] = renderDocument(value)
+	}
+	return selected
+}
+
+// renderDocument decodes JSON-encoded policies and request bodies so they are readable in the bundle
+func renderDocument(value interface{}) interface{} {
+	text, ok := value.(string)
+	if !ok || !strings.HasPrefix(strings.TrimSpace(text), "{") {
+		return value
+	}
+
+	var document interface{}
+	if err := json.Unmarshal([]byte(text), &document); err != nil {
+		return value
+	}
+	return document
+}
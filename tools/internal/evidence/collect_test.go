@@ -0,0 +1,100 @@
+package evidence
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/tfstate"
+)
+
+func loadFixture(t *testing.T) []Target {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/show.json")
+	require.NoError(t, err)
+	show, err := tfstate.Parse(data)
+	require.NoError(t, err)
+
+	targets, err := Collect(show, DefaultMapping())
+	require.NoError(t, err)
+	return targets
+}
+
+func itemOf(t *testing.T, target Target, category string) Item {
+	t.Helper()
+	for _, item := range target.Items {
+		if item.Category == category {
+			return item
+		}
+	}
+	t.Fatalf("%s has no %s evidence", target.Name, category)
+	return Item{}
+}
+
+func TestCollectFindsModuleTargets(t *testing.T) {
+	targets := loadFixture(t)
+	require.Len(t, targets, 2)
+
+	s3, azure := targets[0], targets[1]
+	assert.Equal(t, "acme-audit-logs-prod", s3.Name)
+	assert.Equal(t, "aws", s3.Provider)
+	assert.Equal(t, "module.audit_storage", s3.Module)
+	assert.Equal(t, "SOC2", s3.ComplianceTag)
+
+	assert.Equal(t, "acmeauditlogs", azure.Name)
+	assert.Equal(t, "azure", azure.Provider)
+	assert.Equal(t, "HIPAA", azure.ComplianceTag)
+}
+
+func TestCollectS3Evidence(t *testing.T) {
+	s3 := loadFixture(t)[0]
+
+	policy := itemOf(t, s3, "access_policy")
+	assert.Equal(t, Configured, policy.Status)
+	require.Len(t, policy.Resources, 1)
+	rendered, ok := policy.Resources[0].Values["policy"].(map[string]interface{})
+	require.True(t, ok, "The bucket policy is rendered as a JSON document")
+	assert.Equal(t, "2012-10-17", rendered["Version"])
+
+	lock := itemOf(t, s3, "lock_configuration")
+	assert.Len(t, lock.Resources, 2)
+	assert.Equal(t, "(sensitive)", lock.Resources[1].Values["token"])
+
+	logging := itemOf(t, s3, "logging")
+	assert.Equal(t, "module.audit_storage.aws_s3_bucket_logging.audit_logs[0]", logging.Resources[0].Address)
+
+	replication := itemOf(t, s3, "replication")
+	assert.Equal(t, NotConfigured, replication.Status)
+	assert.Empty(t, replication.Resources)
+
+	var controls []string
+	for _, control := range itemOf(t, s3, "retention").Controls {
+		controls = append(controls, control.Framework+" "+control.ID)
+	}
+	assert.Contains(t, controls, "pci_dss 10.5.1")
+	assert.Contains(t, controls, "hipaa 164.316(b)(2)(i)")
+}
+
+func TestCollectAzureEvidence(t *testing.T) {
+	azure := loadFixture(t)[1]
+
+	public := itemOf(t, azure, "public_access")
+	require.Len(t, public.Resources, 1)
+	assert.NotContains(t, public.Resources[0].Values, "primary_access_key", "Only the listed attributes are kept")
+	assert.Equal(t, false, public.Resources[0].Values["shared_access_key_enabled"])
+
+	assert.Equal(t, Configured, itemOf(t, azure, "lock_configuration").Status)
+	assert.Equal(t, Configured, itemOf(t, azure, "logging").Status)
+	assert.Equal(t, NotConfigured, itemOf(t, azure, "access_policy").Status)
+}
+
+func TestCollectWithoutModules(t *testing.T) {
+	show, err := tfstate.Parse([]byte(`{"format_version": "1.0", "values": {"root_module": {}}}`))
+	require.NoError(t, err)
+
+	_, err = Collect(show, DefaultMapping())
+	assert.Error(t, err)
+}
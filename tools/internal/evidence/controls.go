@@ -0,0 +1,195 @@
+package evidence
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+//go:embed controls.json
+var defaultControls []byte
+
+// Control is a framework requirement and the evidence categories that support it
+type Control struct {
+	ID       string   `json:"id"`
+	Title    string   `json:"title"`
+	Evidence []string `json:"evidence"`
+}
+
+// Framework is a compliance framework such as SOC 2 or PCI DSS
+type Framework struct {
+	ID       string    `json:"id"`
+	Name     string    `json:"name"`
+	Controls []Control `json:"controls"`
+}
+
+// Mapping maps evidence categories to framework controls. It is data, not code:
+// new frameworks are added to controls.json or passed with -controls
+type Mapping struct {
+	Categories map[string]string `json:"categories"`
+	Frameworks []Framework       `json:"frameworks"`
+}
+
+// ControlRef is a control that an evidence item supports
+type ControlRef struct {
+	Framework string `json:"framework"`
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+}
+
+// DefaultMapping returns the built-in mapping for SOC 2, HIPAA and PCI DSS
+func DefaultMapping() *Mapping {
+	mapping, err := ParseMapping(defaultControls)
+	if err == nil {
+		err = mapping.validate()
+	}
+	if err != nil {
+		panic(err)
+	}
+	return mapping
+}
+
+// LoadMapping reads a mapping file
+func LoadMapping(path string) (*Mapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	mapping, err := ParseMapping(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return mapping, nil
+}
+
+// ParseMapping decodes a mapping. A custom mapping may reference the built-in
+// categories, so it is only validated once merged with DefaultMapping
+func ParseMapping(data []byte) (*Mapping, error) {
+	var mapping Mapping
+	if err := json.Unmarshal(data, &mapping); err != nil {
+		return nil, err
+	}
+	return &mapping, nil
+}
+
+func (m *Mapping) validate() error {
+	seen := map[string]bool{}
+	for _, framework := range m.Frameworks {
+		if framework.ID == "" {
+			return fmt.Errorf("framework %q has no id", framework.Name)
+		}
+		if seen[framework.ID] {
+			return fmt.Errorf("framework %s is defined twice", framework.ID)
+		}
+		seen[framework.ID] = true
+
+		for _, control := range framework.Controls {
+			for _, category := range control.Evidence {
+				if _, ok := m.Categories[category]; !ok {
+					return fmt.Errorf("%s %s references unknown evidence category %q", framework.ID, control.ID, category)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Merge adds the frameworks and categories of other, replacing frameworks with the same ID
+func (m *Mapping) Merge(other *Mapping) error {
+	merged := &Mapping{Categories: map[string]string{}}
+	for name, description := range m.Categories {
+		merged.Categories[name] = description
+	}
+	for name, description := range other.Categories {
+		merged.Categories[name] = description
+	}
+
+	replaced := map[string]Framework{}
+	for _, framework := range other.Frameworks {
+		replaced[framework.ID] = framework
+	}
+	for _, framework := range m.Frameworks {
+		if replacement, ok := replaced[framework.ID]; ok {
+			framework = replacement
+			delete(replaced, framework.ID)
+		}
+		merged.Frameworks = append(merged.Frameworks, framework)
+	}
+	for _, framework := range other.Frameworks {
+		if _, ok := replaced[framework.ID]; ok {
+			merged.Frameworks = append(merged.Frameworks, framework)
+		}
+	}
+
+	if err := merged.validate(); err != nil {
+		return err
+	}
+	*m = *merged
+	return nil
+}
+
+// Select keeps only the given frameworks (all if ids is empty)
+func (m *Mapping) Select(ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	byID := map[string]Framework{}
+	for _, framework := range m.Frameworks {
+		byID[framework.ID] = framework
+	}
+
+	var selected []Framework
+	for _, id := range ids {
+		framework, ok := byID[id]
+		if !ok {
+			return fmt.Errorf("unknown framework %q (known: %v)", id, m.FrameworkIDs())
+		}
+		selected = append(selected, framework)
+	}
+	m.Frameworks = selected
+	return nil
+}
+
+// FrameworkIDs returns the framework IDs in mapping order
+func (m *Mapping) FrameworkIDs() []string {
+	ids := make([]string, 0, len(m.Frameworks))
+	for _, framework := range m.Frameworks {
+		ids = append(ids, framework.ID)
+	}
+	return ids
+}
+
+// ControlsFor returns the controls that an evidence category supports, by framework order
+func (m *Mapping) ControlsFor(category string) []ControlRef {
+	var refs []ControlRef
+	for _, framework := range m.Frameworks {
+		for _, control := range framework.Controls {
+			if containsString(control.Evidence, category) {
+				refs = append(refs, ControlRef{Framework: framework.ID, ID: control.ID, Title: control.Title})
+			}
+		}
+	}
+	return refs
+}
+
+// CategoryNames returns the evidence categories in sorted order
+func (m *Mapping) CategoryNames() []string {
+	names := make([]string, 0, len(m.Categories))
+	for name := range m.Categories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
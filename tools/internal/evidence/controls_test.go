@@ -0,0 +1,65 @@
+package evidence
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultMappingCoversEveryCategory(t *testing.T) {
+	mapping := DefaultMapping()
+	assert.Equal(t, []string{"soc2", "hipaa", "pci_dss"}, mapping.FrameworkIDs())
+
+	for _, category := range mapping.CategoryNames() {
+		assert.NotEmpty(t, mapping.ControlsFor(category), "%s should support at least one control", category)
+	}
+
+	var soc2 []string
+	for _, control := range mapping.ControlsFor("logging") {
+		if control.Framework == "soc2" {
+			soc2 = append(soc2, control.ID)
+		}
+	}
+	assert.Contains(t, soc2, "CC7.2")
+}
+
+func TestMergeAddsAndReplacesFrameworks(t *testing.T) {
+	custom, err := ParseMapping([]byte(`{
+		"categories": {"logging": "Audit logging"},
+		"frameworks": [
+			{"id": "iso27001", "name": "ISO/IEC 27001:2022", "controls": [{"id": "A.8.15", "title": "Logging", "evidence": ["logging", "lock_configuration"]}]},
+			{"id": "soc2", "name": "SOC 2 (internal)", "controls": [{"id": "CC7.2", "title": "Monitoring", "evidence": ["logging"]}]}
+		]
+	}`))
+	require.NoError(t, err)
+
+	mapping := DefaultMapping()
+	require.NoError(t, mapping.Merge(custom))
+
+	assert.Equal(t, []string{"soc2", "hipaa", "pci_dss", "iso27001"}, mapping.FrameworkIDs())
+	assert.Equal(t, "SOC 2 (internal)", mapping.Frameworks[0].Name)
+	assert.Equal(t, "Audit logging", mapping.Categories["logging"])
+	assert.Contains(t, mapping.CategoryNames(), "encryption", "Built-in categories are kept")
+}
+
+func TestMergeRejectsUnknownCategories(t *testing.T) {
+	custom, err := ParseMapping([]byte(`{"frameworks": [{"id": "nist", "controls": [{"id": "AU-9", "evidence": ["backups"]}]}]}`))
+	require.NoError(t, err)
+
+	mapping := DefaultMapping()
+	assert.ErrorContains(t, mapping.Merge(custom), `unknown evidence category "backups"`)
+	assert.Equal(t, []string{"soc2", "hipaa", "pci_dss"}, mapping.FrameworkIDs(), "A failed merge leaves the mapping unchanged")
+}
+
+func TestSelectFrameworks(t *testing.T) {
+	mapping := DefaultMapping()
+	require.NoError(t, mapping.Select([]string{"pci_dss"}))
+	assert.Equal(t, []string{"pci_dss"}, mapping.FrameworkIDs())
+
+	for _, control := range mapping.ControlsFor("retention") {
+		assert.Equal(t, "pci_dss", control.Framework)
+	}
+
+	assert.Error(t, DefaultMapping().Select([]string{"nist"}))
+}
@@ -0,0 +1,210 @@
+package evidence
+
+import (
+	"bytes"
+	"encoding/json"
+	htmltemplate "html/template"
+	"strings"
+	"text/template"
+)
+
+// Control coverage values in the summary
+const (
+	Covered = "covered"
+	Partial = "partial"
+	Gap     = "gap"
+)
+
+type coverageRow struct {
+	Framework string
+	ID        string
+	Title     string
+	Evidence  string
+	Status    string
+}
+
+type itemView struct {
+	Item
+	File string
+	JSON string
+}
+
+type targetView struct {
+	Target
+	Configured int
+	Coverage   []coverageRow
+	Items      []itemView
+}
+
+type summaryView struct {
+	*Pack
+	Frameworks []Framework
+	Targets    []targetView
+}
+
+func newSummaryView(pack *Pack) (*summaryView, error) {
+	view := &summaryView{Pack: pack, Frameworks: pack.Mapping.Frameworks}
+
+	for _, target := range pack.Targets {
+		tv := targetView{Target: target}
+		status := map[string]string{}
+
+		for _, item := range target.Items {
+			status[item.Category] = item.Status
+			if item.Status == Configured {
+				tv.Configured++
+			}
+
+			data, err := json.MarshalIndent(item.Resources, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			tv.Items = append(tv.Items, itemView{Item: item, File: item.File(), JSON: string(data)})
+		}
+
+		for _, framework := range pack.Mapping.Frameworks {
+			for _, control := range framework.Controls {
+				configured := 0
+				for _, category := range control.Evidence {
+					if status[category] == Configured {
+						configured++
+					}
+				}
+
+				row := coverageRow{Framework: framework.Name, ID: control.ID, Title: control.Title, Evidence: strings.Join(control.Evidence, ", "), Status: Partial}
+				switch configured {
+				case len(control.Evidence):
+					row.Status = Covered
+				case 0:
+					row.Status = Gap
+				}
+				tv.Coverage = append(tv.Coverage, row)
+			}
+		}
+		view.Targets = append(view.Targets, tv)
+	}
+	return view, nil
+}
+
+var markdownTemplate = template.Must(template.New("summary.md").Parse(`# AuditLedger Compliance Evidence
+
+| | |
+|---|---|
+| Generated | {{.GeneratedAt.UTC.Format "2006-01-02 15:04:05 UTC"}} |
+| Source | ` + "`{{.Source.Path}}`" + ` (SHA-256 ` + "`{{.Source.SHA256}}`" + `) |
+| Terraform | {{.Source.TerraformVersion}} |
+| Frameworks | {{range $i, $f := .Frameworks}}{{if $i}}, {{end}}{{$f.Name}}{{end}} |
+
+Every file in this bundle is listed with its SHA-256 digest in ` + "`manifest.json`" + `, which is
+signed with Ed25519 (` + "`manifest.json.sig`" + `). Verify the bundle before relying on it:
+` + "`auditledger-evidence verify -bundle <bundle.zip> -public-key <key.pem>`" + `.
+
+## Targets
+
+| Name | Provider | Module | Compliance tag | Evidence |
+|------|----------|--------|----------------|----------|
+{{range .Targets}}| {{.Name}} | {{.Provider}} | ` + "`{{if .Module}}{{.Module}}{{else}}(root){{end}}`" + ` | {{with .ComplianceTag}}{{.}}{{else}}-{{end}} | {{.Configured}}/{{len .Items}} categories configured |
+{{end}}{{range .Targets}}
+## {{.Name}}
+
+### Control Coverage
+
+| Framework | Control | Requirement | Evidence | Status |
+|-----------|---------|-------------|----------|--------|
+{{range .Coverage}}| {{.Framework}} | {{.ID}} | {{.Title}} | {{.Evidence}} | {{.Status}} |
+{{end}}
+### Evidence
+{{range .Items}}
+#### {{.Category}}
+
+{{.Description}}. Status: **{{.Status}}**. File: ` + "`{{.File}}`" + `
+{{if .Resources}}
+{{range .Resources}}- ` + "`{{.Address}}`" + `
+{{end}}
+<details><summary>Configuration</summary>
+
+` + "```json" + `
+{{.JSON}}
+` + "```" + `
+
+</details>
+{{else}}
+No resources in the state provide this evidence.
+{{end}}{{end}}{{end}}`))
+
+var htmlTemplate = htmltemplate.Must(htmltemplate.New("summary.html").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>AuditLedger Compliance Evidence</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem auto; max-width: 72rem; color: #1f2328; }
+table { border-collapse: collapse; margin: 1rem 0; width: 100%; }
+th, td { border: 1px solid #d0d7de; padding: 0.4rem 0.6rem; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+code, pre { font-family: SFMono-Regular, Consolas, monospace; font-size: 0.85rem; }
+pre { background: #f6f8fa; padding: 0.8rem; overflow-x: auto; }
+.covered, .configured { color: #1a7f37; font-weight: 600; }
+.partial { color: #9a6700; font-weight: 600; }
+.gap, .not_configured { color: #cf222e; font-weight: 600; }
+</style>
+</head>
+<body>
+<h1>AuditLedger Compliance Evidence</h1>
+<table>
+<tr><th>Generated</th><td>{{.GeneratedAt.UTC.Format "2006-01-02 15:04:05 UTC"}}</td></tr>
+<tr><th>Source</th><td><code>{{.Source.Path}}</code> (SHA-256 <code>{{.Source.SHA256}}</code>)</td></tr>
+<tr><th>Terraform</th><td>{{.Source.TerraformVersion}}</td></tr>
+<tr><th>Frameworks</th><td>{{range $i, $f := .Frameworks}}{{if $i}}, {{end}}{{$f.Name}}{{end}}</td></tr>
+</table>
+<p>Every file in this bundle is listed with its SHA-256 digest in <code>manifest.json</code>, which is signed with
+Ed25519 (<code>manifest.json.sig</code>). Verify the bundle with
+<code>auditledger-evidence verify -bundle &lt;bundle.zip&gt; -public-key &lt;key.pem&gt;</code>.</p>
+
+<h2>Targets</h2>
+<table>
+<tr><th>Name</th><th>Provider</th><th>Module</th><th>Compliance tag</th><th>Evidence</th></tr>
+{{range .Targets}}<tr><td>{{.Name}}</td><td>{{.Provider}}</td><td><code>{{if .Module}}{{.Module}}{{else}}(root){{end}}</code></td><td>{{with .ComplianceTag}}{{.}}{{else}}-{{end}}</td><td>{{.Configured}}/{{len .Items}} categories configured</td></tr>
+{{end}}</table>
+{{range .Targets}}
+<h2>{{.Name}}</h2>
+<h3>Control Coverage</h3>
+<table>
+<tr><th>Framework</th><th>Control</th><th>Requirement</th><th>Evidence</th><th>Status</th></tr>
+{{range .Coverage}}<tr><td>{{.Framework}}</td><td>{{.ID}}</td><td>{{.Title}}</td><td>{{.Evidence}}</td><td class="{{.Status}}">{{.Status}}</td></tr>
+{{end}}</table>
+<h3>Evidence</h3>
+{{range .Items}}
+<h4>{{.Category}}</h4>
+<p>{{.Description}}. Status: <span class="{{.Status}}">{{.Status}}</span>. File: <code>{{.File}}</code></p>
+{{if .Resources}}<ul>
+{{range .Resources}}<li><code>{{.Address}}</code></li>
+{{end}}</ul>
+<details><summary>Configuration</summary><pre>{{.JSON}}</pre></details>
+{{else}}<p>No resources in the state provide this evidence.</p>
+{{end}}{{end}}{{end}}
+</body>
+</html>
+`))
+
+// RenderMarkdown renders the human-readable summary as Markdown
+func RenderMarkdown(pack *Pack) ([]byte, error) {
+	view, err := newSummaryView(pack)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	err = markdownTemplate.Execute(&out, view)
+	return out.Bytes(), err
+}
+
+// RenderHTML renders the human-readable summary as a standalone HTML page
+func RenderHTML(pack *Pack) ([]byte, error) {
+	view, err := newSummaryView(pack)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	err = htmlTemplate.Execute(&out, view)
+	return out.Bytes(), err
+}
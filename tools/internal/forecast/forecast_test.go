@@ -0,0 +1,165 @@
+package forecast
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/tfstate"
+)
+
+func loadLifecycles(t *testing.T) []Lifecycle {
+	t.Helper()
+	show, _, err := tfstate.Load("testdata/plan.json")
+	require.NoError(t, err)
+	lifecycles, err := FromPlan(show)
+	require.NoError(t, err)
+	return lifecycles
+}
+
+// testPrices makes the arithmetic easy to follow: Hot costs 1 per GB-month, Cool 0.5 with a 90-day minimum
+var testPrices = &PriceTable{Currency: "EUR", Providers: map[string]map[string]Price{
+	"azure": {"Hot": {GBMonth: 1}, "Cool": {GBMonth: 0.5, MinimumDays: 90}},
+}}
+
+func TestFromPlan(t *testing.T) {
+	lifecycles := loadLifecycles(t)
+	require.Len(t, lifecycles, 2)
+
+	s3 := lifecycles[0]
+	assert.Equal(t, "module.audit_logs", s3.Module)
+	assert.Equal(t, "acme-audit-logs", s3.Target)
+	assert.Equal(t, []string{"STANDARD", "STANDARD_IA", "GLACIER_IR", "GLACIER"}, s3.Tiers())
+	assert.Equal(t, 2555, s3.RetentionDays)
+	assert.Zero(t, s3.ExpiryDays(), "Only noncurrent versions expire")
+	assert.Len(t, s3.Notes, 1)
+	assert.Equal(t, "STANDARD, STANDARD_IA after 90 days, GLACIER_IR after 180 days, GLACIER after 365 days, kept after retention (retention 2555 days)", s3.String())
+
+	azure := lifecycles[1]
+	assert.Equal(t, "acmeauditlogs", azure.Target)
+	assert.Equal(t, []Transition{{90, "Cool"}, {180, "Archive"}}, azure.Transitions, "Unset tiers are -1")
+	assert.Equal(t, 365, azure.RetentionDays)
+	assert.Equal(t, 365, azure.ExpiryDays())
+}
+
+func TestExpiryRespectsRetention(t *testing.T) {
+	l := Lifecycle{RetentionDays: 365, DeleteAfterDays: 30}
+	assert.Equal(t, 365, l.ExpiryDays(), "Locked objects cannot be deleted before the retention period ends")
+}
+
+func TestSimulate(t *testing.T) {
+	l := Lifecycle{Provider: "azure", InitialTier: "Hot", Transitions: []Transition{{30, "Cool"}}, RetentionDays: 365}
+
+	f, err := Simulate(l, testPrices, 1, 90)
+	require.NoError(t, err)
+	require.Len(t, f.Months, 3)
+
+	// Month 1 fills Hot with 1..30 GB; from month 2 the oldest day moves to Cool every day
+	assert.InDelta(t, 15.5, f.Months[0].Tiers[0].GB, 1e-9)
+	assert.InDelta(t, 0, f.Months[0].Tiers[1].GB, 1e-9)
+	assert.InDelta(t, 30, f.Months[1].Tiers[0].GB, 1e-9)
+	assert.InDelta(t, 15.5, f.Months[1].Tiers[1].GB, 1e-9)
+	assert.InDelta(t, 45.5, f.Months[2].Tiers[1].GB, 1e-9)
+
+	assert.InDelta(t, 15.5, f.Months[0].Cost, 1e-9)
+	assert.InDelta(t, 30+15.5*0.5, f.Months[1].Cost, 1e-9)
+	assert.Zero(t, f.MinimumDurationCost, "Nothing leaves Cool")
+	assert.Equal(t, 3, f.Peak().Month)
+}
+
+func TestSimulateMinimumDuration(t *testing.T) {
+	// Cool has a 90-day minimum but objects are deleted 30 days after they reach it; the
+	// lifecycle asks for deletion at 20 days, which Object Lock defers to 40
+	l := Lifecycle{Provider: "azure", InitialTier: "Hot", Transitions: []Transition{{10, "Cool"}}, RetentionDays: 40, DeleteAfterDays: 20}
+
+	f, err := Simulate(l, testPrices, 1, 60)
+	require.NoError(t, err)
+	// Cool fills from 21 to 30 GB on days 30-39 and holds 30 GB once deletion starts on day 40
+	assert.InDelta(t, (21+22+23+24+25+26+27+28+29+30*21)/30.0, f.Months[1].Tiers[1].GB, 1e-9)
+	// Days 40-59 each delete one GB that is charged for 60 more days at 0.5
+	assert.InDelta(t, 20*60*0.5/DaysPerMonth, f.MinimumDurationCost, 1e-9)
+	assert.InDelta(t, f.StorageCost+f.MinimumDurationCost, f.Cost, 1e-9)
+}
+
+func TestSimulateSkipsTiersAfterExpiry(t *testing.T) {
+	l := Lifecycle{Provider: "azure", InitialTier: "Hot", Transitions: []Transition{{400, "Cool"}}, RetentionDays: 365, DeleteAfterDays: 365}
+
+	f, err := Simulate(l, testPrices, 1, 30)
+	require.NoError(t, err)
+	require.Len(t, f.Months[0].Tiers, 1)
+	assert.Equal(t, "Hot", f.Months[0].Tiers[0].Tier)
+}
+
+func TestSimulateErrors(t *testing.T) {
+	l := Lifecycle{Provider: "azure", InitialTier: "Hot", Transitions: []Transition{{30, "Archive"}}}
+
+	_, err := Simulate(l, testPrices, 1, 30)
+	assert.ErrorContains(t, err, `no azure price for tier "Archive" (known: [Hot Cool])`)
+
+	_, err = Simulate(Lifecycle{Provider: "azure", InitialTier: "Hot"}, testPrices, 0, 30)
+	assert.ErrorContains(t, err, "daily ingest")
+}
+
+func TestParseTransitions(t *testing.T) {
+	transitions, err := ParseTransitions("GLACIER=365, STANDARD_IA=30")
+	require.NoError(t, err)
+	assert.Equal(t, []Transition{{365, "GLACIER"}, {30, "STANDARD_IA"}}, transitions)
+
+	var l Lifecycle
+	l.SetTransitions(transitions)
+	assert.Equal(t, []Transition{{30, "STANDARD_IA"}, {365, "GLACIER"}}, l.Transitions)
+
+	transitions, err = ParseTransitions("")
+	require.NoError(t, err)
+	assert.Empty(t, transitions, "An empty list keeps objects in the initial tier")
+
+	for _, value := range []string{"GLACIER", "GLACIER=soon", "=90", "GLACIER=-1"} {
+		_, err := ParseTransitions(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestPrices(t *testing.T) {
+	prices := DefaultPrices()
+	for _, l := range loadLifecycles(t) {
+		for _, tier := range l.Tiers() {
+			_, err := prices.Price(l.Provider, tier)
+			assert.NoError(t, err, "The module's tiers have built-in prices")
+		}
+	}
+
+	custom, err := ParsePrices([]byte(`{"currency": "EUR", "providers": {"aws": {"GLACIER": {"gb_month": 0.003, "minimum_days": 90}}}}`))
+	require.NoError(t, err)
+	merged := prices.Merge(custom)
+	assert.Equal(t, "EUR", merged.Currency)
+	assert.Equal(t, Price{GBMonth: 0.003, MinimumDays: 90}, merged.Providers["aws"]["GLACIER"])
+	assert.Equal(t, prices.Providers["aws"]["STANDARD"], merged.Providers["aws"]["STANDARD"])
+	assert.Equal(t, 0.0036, prices.Providers["aws"]["GLACIER"].GBMonth, "Merging does not change the defaults")
+	assert.Equal(t, "STANDARD", merged.Tiers("aws")[0])
+
+	_, err = ParsePrices([]byte(`{"providers": {"aws": {"GLACIER": {"gb_month": -1}}}}`))
+	assert.Error(t, err)
+}
+
+func TestWrite(t *testing.T) {
+	l := Lifecycle{Provider: "azure", Target: "acmeauditlogs", Module: "module.azure_logs", InitialTier: "Hot", Transitions: []Transition{{30, "Cool"}}, RetentionDays: 365}
+	f, err := Simulate(l, testPrices, 1, 60)
+	require.NoError(t, err)
+
+	var text bytes.Buffer
+	require.NoError(t, Write(&text, []*Forecast{f}, "text"))
+	assert.Contains(t, text.String(), "module.azure_logs acmeauditlogs (azure): 1 GB/day for 60 days")
+	assert.Contains(t, text.String(), "Month     Hot GB    Cool GB     Cost EUR")
+	assert.Contains(t, text.String(), "    2       30.0       15.5        37.75")
+
+	var csv bytes.Buffer
+	require.NoError(t, Write(&csv, []*Forecast{f}, "csv"))
+	lines := strings.Split(strings.TrimSpace(csv.String()), "\n")
+	assert.Len(t, lines, 5, "A header and one row per month and tier")
+	assert.Equal(t, "module.azure_logs,acmeauditlogs,azure,2,Cool,15.500,7.7500,0.0000,EUR", lines[4])
+
+	assert.Error(t, Write(&csv, []*Forecast{f}, "xlsx"))
+}
@@ -0,0 +1,226 @@
+// Package forecast simulates how much audit data the AuditLedger storage modules hold in each
+// storage tier over the retention period, and what that costs. The tiering comes from the
+// lifecycle configuration in a `terraform show -json` plan, so the effect of changing a
+// transition can be priced before it is applied
+package forecast
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/tfstate"
+)
+
+// Transition moves objects to a storage tier once they are Days old
+type Transition struct {
+	Days int    `json:"days"`
+	Tier string `json:"tier"`
+}
+
+// Lifecycle is how long objects of one module instance stay in each tier
+type Lifecycle struct {
+	Provider    string       `json:"provider"`
+	Target      string       `json:"target"`
+	Module      string       `json:"module"`
+	InitialTier string       `json:"initial_tier"`
+	Transitions []Transition `json:"transitions"`
+	// RetentionDays is the Object Lock or immutability period; no object is deleted sooner
+	RetentionDays int `json:"retention_days"`
+	// DeleteAfterDays is the age at which the lifecycle deletes objects, 0 if they are kept
+	DeleteAfterDays int `json:"delete_after_days"`
+	// Notes explain assumptions made while reading the plan
+	Notes []string `json:"notes,omitempty"`
+}
+
+// ExpiryDays is the age at which objects are deleted, never before the retention period ends (0 if never)
+func (l Lifecycle) ExpiryDays() int {
+	if l.DeleteAfterDays == 0 {
+		return 0
+	}
+	if l.DeleteAfterDays < l.RetentionDays {
+		return l.RetentionDays
+	}
+	return l.DeleteAfterDays
+}
+
+// Tiers returns the tiers objects pass through, in order
+func (l Lifecycle) Tiers() []string {
+	tiers := []string{l.InitialTier}
+	for _, transition := range l.Transitions {
+		tiers = append(tiers, transition.Tier)
+	}
+	return tiers
+}
+
+// String describes the lifecycle on one line, e.g. STANDARD, STANDARD_IA after 90 days, kept after retention
+func (l Lifecycle) String() string {
+	parts := []string{l.InitialTier}
+	for _, transition := range l.Transitions {
+		parts = append(parts, fmt.Sprintf("%s after %d days", transition.Tier, transition.Days))
+	}
+	if expiry := l.ExpiryDays(); expiry > 0 {
+		parts = append(parts, fmt.Sprintf("deleted after %d days", expiry))
+	} else {
+		parts = append(parts, "kept after retention")
+	}
+	return fmt.Sprintf("%s (retention %d days)", strings.Join(parts, ", "), l.RetentionDays)
+}
+
+// SetTransitions replaces the transitions, sorted by age
+func (l *Lifecycle) SetTransitions(transitions []Transition) {
+	l.Transitions = append([]Transition(nil), transitions...)
+	sort.SliceStable(l.Transitions, func(i, j int) bool { return l.Transitions[i].Days < l.Transitions[j].Days })
+}
+
+// ParseTransitions reads a comma-separated list of TIER=DAYS, e.g. STANDARD_IA=90,GLACIER=365
+func ParseTransitions(value string) ([]Transition, error) {
+	transitions := []Transition{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		tier, days, _ := strings.Cut(item, "=")
+		n, err := strconv.Atoi(strings.TrimSpace(days))
+		if err != nil || n < 0 || strings.TrimSpace(tier) == "" {
+			return nil, fmt.Errorf("invalid transition %q (use TIER=DAYS)", item)
+		}
+		transitions = append(transitions, Transition{Days: n, Tier: strings.TrimSpace(tier)})
+	}
+	return transitions, nil
+}
+
+// FromPlan reads the lifecycle of every auditledger-s3 and auditledger-azure-blob instance, sorted by module
+func FromPlan(show *tfstate.Show) ([]Lifecycle, error) {
+	byModule := show.ByModule()
+
+	var lifecycles []Lifecycle
+	for module, resources := range byModule {
+		for _, resource := range resources {
+			if resource.Name != "audit_logs" {
+				continue
+			}
+			switch resource.Type {
+			case "aws_s3_bucket":
+				lifecycles = append(lifecycles, s3Lifecycle(module, resource, resources))
+			case "azurerm_storage_account":
+				lifecycles = append(lifecycles, azureLifecycle(module, resource, resources))
+			}
+		}
+	}
+	if len(lifecycles) == 0 {
+		return nil, fmt.Errorf("no auditledger-s3 or auditledger-azure-blob resources found in the plan")
+	}
+
+	sort.Slice(lifecycles, func(i, j int) bool { return lifecycles[i].Module < lifecycles[j].Module })
+	return lifecycles, nil
+}
+
+func s3Lifecycle(module string, bucket tfstate.Resource, resources []tfstate.Resource) Lifecycle {
+	l := Lifecycle{Provider: "aws", Target: name(bucket, "bucket"), Module: module, InitialTier: "STANDARD"}
+
+	for _, lock := range tfstate.Find(resources, "aws_s3_bucket_object_lock_configuration", "audit_logs") {
+		retention := tfstate.Lookup(lock.Values, "rule", 0, "default_retention", 0)
+		if days, ok := tfstate.Number(tfstate.Lookup(retention, "days")); ok {
+			l.RetentionDays = days
+		} else if years, ok := tfstate.Number(tfstate.Lookup(retention, "years")); ok {
+			l.RetentionDays = years * 365
+		}
+	}
+
+	var transitions []Transition
+	var currentExpiry, noncurrentExpiry int
+	for _, configuration := range tfstate.Find(resources, "aws_s3_bucket_lifecycle_configuration", "audit_logs") {
+		rules, _ := configuration.Values["rule"].([]interface{})
+		for _, rule := range rules {
+			if status, _ := tfstate.Lookup(rule, "status").(string); status != "Enabled" {
+				continue
+			}
+			id, _ := tfstate.Lookup(rule, "id").(string)
+			if prefix, _ := tfstate.Lookup(rule, "filter", 0, "prefix").(string); prefix != "" || tfstate.Lookup(rule, "filter", 0, "tag", 0) != nil || tfstate.Lookup(rule, "filter", 0, "and", 0) != nil {
+				l.Notes = append(l.Notes, fmt.Sprintf("rule %s has a filter; the forecast applies it to every object", id))
+			}
+
+			items, _ := tfstate.Lookup(rule, "transition").([]interface{})
+			for _, item := range items {
+				class, _ := tfstate.Lookup(item, "storage_class").(string)
+				if days, ok := tfstate.Number(tfstate.Lookup(item, "days")); ok && class != "" {
+					transitions = append(transitions, Transition{Days: days, Tier: class})
+				} else if class != "" {
+					l.Notes = append(l.Notes, fmt.Sprintf("rule %s transitions to %s on a date; the forecast ignores it", id, class))
+				}
+			}
+			if days, ok := tfstate.Number(tfstate.Lookup(rule, "expiration", 0, "days")); ok && days > 0 {
+				currentExpiry = days
+			}
+			if days, ok := tfstate.Number(tfstate.Lookup(rule, "noncurrent_version_expiration", 0, "noncurrent_days")); ok && days > 0 {
+				noncurrentExpiry = days
+			}
+		}
+	}
+	l.SetTransitions(transitions)
+
+	// Expiring the current version only adds a delete marker; the data is removed once the
+	// noncurrent version expires as well
+	switch {
+	case currentExpiry > 0 && noncurrentExpiry > 0:
+		l.DeleteAfterDays = currentExpiry + noncurrentExpiry
+	case noncurrentExpiry > 0:
+		l.Notes = append(l.Notes, "only noncurrent versions expire; audit objects are never overwritten, so they are kept after retention")
+	}
+	return l
+}
+
+func azureLifecycle(module string, account tfstate.Resource, resources []tfstate.Resource) Lifecycle {
+	l := Lifecycle{Provider: "azure", Target: name(account, "name"), Module: module, InitialTier: "Hot"}
+	if tier, _ := account.Values["access_tier"].(string); tier != "" {
+		l.InitialTier = tier
+	}
+
+	if days, ok := tfstate.Number(tfstate.Lookup(account.Values, "immutability_policy", 0, "period_since_creation_in_days")); ok {
+		l.RetentionDays = days
+	}
+	for _, policy := range tfstate.Find(resources, "azurerm_storage_container_immutability_policy", "audit_logs") {
+		if days, ok := tfstate.Number(policy.Values["immutability_period_in_days"]); ok && days > l.RetentionDays {
+			l.RetentionDays = days
+		}
+	}
+
+	tiers := []struct{ tier, attribute string }{
+		{"Cool", "tier_to_cool_after_days_since_%s_greater_than"},
+		{"Cold", "tier_to_cold_after_days_since_%s_greater_than"},
+		{"Archive", "tier_to_archive_after_days_since_%s_greater_than"},
+	}
+	var transitions []Transition
+	for _, policy := range tfstate.Find(resources, "azurerm_storage_management_policy", "audit_logs") {
+		rules, _ := policy.Values["rule"].([]interface{})
+		for _, rule := range rules {
+			if enabled, ok := tfstate.Lookup(rule, "enabled").(bool); ok && !enabled {
+				continue
+			}
+			blob := tfstate.Lookup(rule, "actions", 0, "base_blob", 0)
+			// Unset day counts are -1 in the azurerm provider
+			for _, t := range tiers {
+				if days, ok := tfstate.Number(tfstate.Lookup(blob, fmt.Sprintf(t.attribute, "modification"))); ok && days >= 0 {
+					transitions = append(transitions, Transition{Days: days, Tier: t.tier})
+				} else if days, ok := tfstate.Number(tfstate.Lookup(blob, fmt.Sprintf(t.attribute, "last_access_time"))); ok && days >= 0 {
+					transitions = append(transitions, Transition{Days: days, Tier: t.tier})
+					l.Notes = append(l.Notes, fmt.Sprintf("%s tiering counts days since last access; the forecast assumes blobs are not read", t.tier))
+				}
+			}
+			if days, ok := tfstate.Number(tfstate.Lookup(blob, "delete_after_days_since_modification_greater_than")); ok && days >= 0 {
+				l.DeleteAfterDays = days
+			}
+		}
+	}
+	l.SetTransitions(transitions)
+	return l
+}
+
+func name(resource tfstate.Resource, attribute string) string {
+	if value := resource.String(attribute); value != "" {
+		return value
+	}
+	return resource.Address
+}
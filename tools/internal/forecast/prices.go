@@ -0,0 +1,113 @@
+package forecast
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+//go:embed prices.json
+var defaultPrices []byte
+
+// Price is the storage price of a tier and how long an object is billed for at least
+type Price struct {
+	GBMonth float64 `json:"gb_month"`
+	// MinimumDays is the minimum storage duration; objects that leave the tier sooner
+	// (by transition or deletion) are charged for the remaining days
+	MinimumDays int `json:"minimum_days,omitempty"`
+}
+
+// PriceTable holds the price of each storage tier by provider. It is data, not code: negotiated
+// or regional prices are passed with -prices and override the built-in entries
+type PriceTable struct {
+	Currency  string                      `json:"currency"`
+	Source    string                      `json:"source,omitempty"`
+	Providers map[string]map[string]Price `json:"providers"`
+}
+
+// DefaultPrices returns the built-in list prices
+func DefaultPrices() *PriceTable {
+	prices, err := ParsePrices(defaultPrices)
+	if err != nil {
+		panic(err)
+	}
+	return prices
+}
+
+// LoadPrices reads a price table file
+func LoadPrices(path string) (*PriceTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	prices, err := ParsePrices(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return prices, nil
+}
+
+// ParsePrices decodes a price table
+func ParsePrices(data []byte) (*PriceTable, error) {
+	var prices PriceTable
+	if err := json.Unmarshal(data, &prices); err != nil {
+		return nil, err
+	}
+	for provider, tiers := range prices.Providers {
+		for tier, price := range tiers {
+			if price.GBMonth < 0 || price.MinimumDays < 0 {
+				return nil, fmt.Errorf("%s %s: prices and minimum days cannot be negative", provider, tier)
+			}
+		}
+	}
+	return &prices, nil
+}
+
+// Merge returns the table with the tiers of other added or replaced
+func (p *PriceTable) Merge(other *PriceTable) *PriceTable {
+	merged := &PriceTable{Currency: p.Currency, Source: p.Source, Providers: map[string]map[string]Price{}}
+	for _, table := range []*PriceTable{p, other} {
+		for provider, tiers := range table.Providers {
+			if merged.Providers[provider] == nil {
+				merged.Providers[provider] = map[string]Price{}
+			}
+			for tier, price := range tiers {
+				merged.Providers[provider][tier] = price
+			}
+		}
+	}
+	if other.Currency != "" {
+		merged.Currency = other.Currency
+	}
+	if other.Source != "" {
+		merged.Source = other.Source
+	}
+	return merged
+}
+
+// Price returns the price of a provider's tier
+func (p *PriceTable) Price(provider, tier string) (Price, error) {
+	price, ok := p.Providers[provider][tier]
+	if !ok {
+		return Price{}, fmt.Errorf("no %s price for tier %q (known: %v)", provider, tier, p.Tiers(provider))
+	}
+	return price, nil
+}
+
+// Tiers lists a provider's priced tiers, cheapest last
+func (p *PriceTable) Tiers(provider string) []string {
+	tiers := make([]string, 0, len(p.Providers[provider]))
+	for tier := range p.Providers[provider] {
+		tiers = append(tiers, tier)
+	}
+	sort.Slice(tiers, func(i, j int) bool {
+		a, b := p.Providers[provider][tiers[i]], p.Providers[provider][tiers[j]]
+		if a.GBMonth != b.GBMonth {
+			return a.GBMonth > b.GBMonth
+		}
+		return tiers[i] < tiers[j]
+	})
+	return tiers
+}
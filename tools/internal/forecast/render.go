@@ -0,0 +1,108 @@
+package forecast
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Formats lists the supported output formats
+var Formats = []string{"text", "json", "csv"}
+
+// Write renders forecasts in the given format
+func Write(w io.Writer, forecasts []*Forecast, format string) error {
+	switch format {
+	case "text":
+		return WriteText(w, forecasts)
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(forecasts)
+	case "csv":
+		return WriteCSV(w, forecasts)
+	default:
+		return fmt.Errorf("unsupported format %q (use text, json or csv)", format)
+	}
+}
+
+// WriteText renders a table of the average GB per tier and the cost of each month
+func WriteText(w io.Writer, forecasts []*Forecast) error {
+	var b strings.Builder
+	for i, f := range forecasts {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		l := f.Lifecycle
+		fmt.Fprintf(&b, "%s %s (%s): %g GB/day for %d days\n", l.Module, l.Target, l.Provider, f.DailyGB, f.Days)
+		fmt.Fprintf(&b, "lifecycle: %s\n", l)
+		for _, note := range l.Notes {
+			fmt.Fprintf(&b, "note: %s\n", note)
+		}
+		b.WriteString("\n")
+
+		var tiers []string
+		if len(f.Months) > 0 {
+			for _, tier := range f.Months[0].Tiers {
+				tiers = append(tiers, tier.Tier)
+			}
+		}
+		fmt.Fprintf(&b, "%5s", "Month")
+		for _, tier := range tiers {
+			fmt.Fprintf(&b, " %*s", width(tier), tier+" GB")
+		}
+		fmt.Fprintf(&b, " %12s\n", "Cost "+f.Currency)
+		for _, month := range f.Months {
+			fmt.Fprintf(&b, "%5d", month.Month)
+			for _, tier := range month.Tiers {
+				fmt.Fprintf(&b, " %*.1f", width(tier.Tier), tier.GB)
+			}
+			fmt.Fprintf(&b, " %12.2f\n", month.Cost)
+		}
+
+		peak := f.Peak()
+		var peakGB float64
+		for _, tier := range peak.Tiers {
+			peakGB += tier.GB
+		}
+		fmt.Fprintf(&b, "\nstorage %.2f + minimum duration charges %.2f = %.2f %s over %d months (peak %.1f GB in month %d)\n",
+			f.StorageCost, f.MinimumDurationCost, f.Cost, f.Currency, len(f.Months), peakGB, peak.Month)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func width(tier string) int {
+	if n := len(tier) + 3; n > 10 {
+		return n
+	}
+	return 10
+}
+
+// WriteCSV renders one row per module instance, month and tier for spreadsheets
+func WriteCSV(w io.Writer, forecasts []*Forecast) error {
+	writer := csv.NewWriter(w)
+	_ = writer.Write([]string{"module", "target", "provider", "month", "tier", "gb", "storage_cost", "minimum_duration_cost", "currency"})
+	for _, f := range forecasts {
+		for _, month := range f.Months {
+			for _, tier := range month.Tiers {
+				_ = writer.Write([]string{
+					f.Lifecycle.Module,
+					f.Lifecycle.Target,
+					f.Lifecycle.Provider,
+					strconv.Itoa(month.Month),
+					tier.Tier,
+					strconv.FormatFloat(tier.GB, 'f', 3, 64),
+					strconv.FormatFloat(tier.StorageCost, 'f', 4, 64),
+					strconv.FormatFloat(tier.MinimumDurationCost, 'f', 4, 64),
+					f.Currency,
+				})
+			}
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,153 @@
+package forecast
+
+import (
+	"fmt"
+)
+
+// DaysPerMonth is the length of a month in the simulation and of a GB-month in the price table
+const DaysPerMonth = 30
+
+// TierMonth is the data held in one tier during one month and what it costs
+type TierMonth struct {
+	Tier string `json:"tier"`
+	// GB is the average amount stored during the month
+	GB          float64 `json:"gb"`
+	StorageCost float64 `json:"storage_cost"`
+	// MinimumDurationCost is charged for objects that left the tier before its minimum storage duration
+	MinimumDurationCost float64 `json:"minimum_duration_cost"`
+}
+
+// Month is the occupancy of every tier in one month of the simulation, numbered from 1
+type Month struct {
+	Month int         `json:"month"`
+	Tiers []TierMonth `json:"tiers"`
+	Cost  float64     `json:"cost"`
+}
+
+// Forecast is the simulated occupancy and cost of one module instance
+type Forecast struct {
+	Lifecycle           Lifecycle `json:"lifecycle"`
+	DailyGB             float64   `json:"daily_gb"`
+	Days                int       `json:"days"`
+	Currency            string    `json:"currency"`
+	Months              []Month   `json:"months"`
+	StorageCost         float64   `json:"storage_cost"`
+	MinimumDurationCost float64   `json:"minimum_duration_cost"`
+	Cost                float64   `json:"cost"`
+}
+
+// segment is the range of ages [start, end) during which an object is in one tier; end is 0 if it stays
+type segment struct {
+	tier       string
+	price      Price
+	start, end int
+}
+
+func segments(l Lifecycle, prices *PriceTable) ([]segment, error) {
+	expiry := l.ExpiryDays()
+	var result []segment
+	for i, tier := range l.Tiers() {
+		price, err := prices.Price(l.Provider, tier)
+		if err != nil {
+			return nil, err
+		}
+		s := segment{tier: tier, price: price, end: expiry}
+		if i > 0 {
+			s.start = l.Transitions[i-1].Days
+		}
+		if i < len(l.Transitions) {
+			s.end = l.Transitions[i].Days
+			if expiry > 0 && expiry < s.end {
+				s.end = expiry
+			}
+		}
+		if expiry > 0 && s.start >= expiry {
+			break
+		}
+		if s.end == 0 || s.end > s.start {
+			result = append(result, s)
+		}
+	}
+	return result, nil
+}
+
+// Simulate ingests dailyGB every day for the given number of days, moves each day's data through
+// the lifecycle by age and prices the tiers month by month
+func Simulate(l Lifecycle, prices *PriceTable, dailyGB float64, days int) (*Forecast, error) {
+	if dailyGB <= 0 {
+		return nil, fmt.Errorf("the daily ingest volume must be positive")
+	}
+	if days <= 0 {
+		return nil, fmt.Errorf("the forecast must cover at least one day")
+	}
+	segs, err := segments(l, prices)
+	if err != nil {
+		return nil, err
+	}
+
+	var tiers []string
+	index := map[string]int{}
+	for _, s := range segs {
+		if _, ok := index[s.tier]; !ok {
+			index[s.tier] = len(tiers)
+			tiers = append(tiers, s.tier)
+		}
+	}
+
+	f := &Forecast{Lifecycle: l, DailyGB: dailyGB, Days: days, Currency: prices.Currency}
+	for first := 0; first < days; first += DaysPerMonth {
+		last := first + DaysPerMonth
+		if last > days {
+			last = days
+		}
+		month := Month{Month: first/DaysPerMonth + 1, Tiers: make([]TierMonth, len(tiers))}
+		for i, tier := range tiers {
+			month.Tiers[i].Tier = tier
+		}
+
+		for day := first; day < last; day++ {
+			for _, s := range segs {
+				// Data ingested on days 0..day has ages 0..day
+				held := day + 1
+				if s.end > 0 && s.end < held {
+					held = s.end
+				}
+				tier := &month.Tiers[index[s.tier]]
+				if held > s.start {
+					gb := dailyGB * float64(held-s.start)
+					tier.GB += gb / float64(last-first)
+					tier.StorageCost += gb * s.price.GBMonth / DaysPerMonth
+				}
+				// The data ingested s.end days ago leaves the tier today
+				if s.end > 0 && day >= s.end && s.end-s.start < s.price.MinimumDays {
+					tier.MinimumDurationCost += dailyGB * float64(s.price.MinimumDays-(s.end-s.start)) * s.price.GBMonth / DaysPerMonth
+				}
+			}
+		}
+
+		for _, tier := range month.Tiers {
+			month.Cost += tier.StorageCost + tier.MinimumDurationCost
+			f.StorageCost += tier.StorageCost
+			f.MinimumDurationCost += tier.MinimumDurationCost
+		}
+		f.Months = append(f.Months, month)
+	}
+	f.Cost = f.StorageCost + f.MinimumDurationCost
+	return f, nil
+}
+
+// Peak returns the month with the most data stored
+func (f *Forecast) Peak() Month {
+	var peak Month
+	var peakGB float64
+	for _, month := range f.Months {
+		var gb float64
+		for _, tier := range month.Tiers {
+			gb += tier.GB
+		}
+		if gb >= peakGB {
+			peak, peakGB = month, gb
+		}
+	}
+	return peak
+}
@@ -0,0 +1,174 @@
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/arm"
+)
+
+// azureStorageVersion is the Blob service REST API version; listing versions needs 2019-12-12 or later
+const azureStorageVersion = "2021-12-02"
+
+// AzureBlobStore reads and writes the audit container of an auditledger-azure-blob storage account.
+// The container immutability policy locks manifests like every other blob
+type AzureBlobStore struct {
+	Endpoint   string // primary blob endpoint, e.g. https://acmeauditlogs.blob.core.windows.net/
+	Container  string
+	Token      arm.TokenSource // audience arm.StorageResource
+	HTTPClient *http.Client
+}
+
+// URI implements Store
+func (s *AzureBlobStore) URI() string {
+	return strings.TrimRight(s.Endpoint, "/") + "/" + s.Container
+}
+
+func (s *AzureBlobStore) blobURL(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return s.URI() + "/" + strings.Join(segments, "/")
+}
+
+type blobList struct {
+	Blobs []struct {
+		Name             string `xml:"Name"`
+		VersionID        string `xml:"VersionId"`
+		IsCurrentVersion bool   `xml:"IsCurrentVersion"`
+		Properties       struct {
+			LastModified  string `xml:"Last-Modified"`
+			ContentLength int64  `xml:"Content-Length"`
+		} `xml:"Properties"`
+	} `xml:"Blobs>Blob"`
+	NextMarker string `xml:"NextMarker"`
+}
+
+// ListVersions implements Store
+func (s *AzureBlobStore) ListVersions(ctx context.Context, prefix string, fn func(Version) error) error {
+	marker := ""
+	for {
+		query := url.Values{
+			"restype": {"container"},
+			"comp":    {"list"},
+			"include": {"versions"},
+			"prefix":  {prefix},
+		}
+		if marker != "" {
+			query.Set("marker", marker)
+		}
+
+		resp, err := s.do(ctx, http.MethodGet, s.URI()+"?"+query.Encode(), nil, nil)
+		if err != nil {
+			return fmt.Errorf("listing versions in %s: %w", s.URI(), err)
+		}
+		var page blobList
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("listing versions in %s: %w", s.URI(), err)
+		}
+
+		for _, blob := range page.Blobs {
+			modified, err := time.Parse(time.RFC1123, blob.Properties.LastModified)
+			if err != nil {
+				return fmt.Errorf("%s: last modified time: %w", blob.Name, err)
+			}
+			version := Version{
+				Key:          blob.Name,
+				VersionID:    blob.VersionID,
+				LastModified: modified.UTC(),
+				Size:         blob.Properties.ContentLength,
+				IsLatest:     blob.IsCurrentVersion,
+			}
+			if err := fn(version); err != nil {
+				return err
+			}
+		}
+
+		if page.NextMarker == "" {
+			return nil
+		}
+		marker = page.NextMarker
+	}
+}
+
+// Open implements Store
+func (s *AzureBlobStore) Open(ctx context.Context, key, versionID string) (io.ReadCloser, error) {
+	target := s.blobURL(key)
+	if versionID != "" {
+		target += "?versionid=" + url.QueryEscape(versionID)
+	}
+	resp, err := s.do(ctx, http.MethodGet, target, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s version %s: %w", key, versionID, err)
+	}
+	return resp.Body, nil
+}
+
+// Put implements Store
+func (s *AzureBlobStore) Put(ctx context.Context, key string, body []byte, contentType string) (string, error) {
+	sum := md5.Sum(body)
+	resp, err := s.do(ctx, http.MethodPut, s.blobURL(key), body, map[string]string{
+		"x-ms-blob-type": "BlockBlob",
+		"Content-Type":   contentType,
+		"Content-MD5":    base64.StdEncoding.EncodeToString(sum[:]),
+	})
+	if err != nil {
+		return "", fmt.Errorf("writing %s: %w", key, err)
+	}
+	resp.Body.Close()
+	return resp.Header.Get("x-ms-version-id"), nil
+}
+
+type storageError struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+func (s *AzureBlobStore) do(ctx context.Context, method, target string, body []byte, headers map[string]string) (*http.Response, error) {
+	token, err := s.Token.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("x-ms-version", azureStorageVersion)
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	client := s.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		var failure storageError
+		data, _ := io.ReadAll(resp.Body)
+		if xml.Unmarshal(data, &failure) == nil && failure.Code != "" {
+			return nil, fmt.Errorf("%s: %s", failure.Code, strings.SplitN(failure.Message, "\n", 2)[0])
+		}
+		return nil, fmt.Errorf("%s %s: %s", method, req.URL.Path, resp.Status)
+	}
+	return resp, nil
+}
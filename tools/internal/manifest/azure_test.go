@@ -0,0 +1,92 @@
+package manifest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/arm"
+)
+
+// fakeBlobService serves two pages of versions and records uploads
+func fakeBlobService(t *testing.T, uploads map[string][]byte) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer storage-token", r.Header.Get("Authorization"))
+		assert.Equal(t, azureStorageVersion, r.Header.Get("x-ms-version"))
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/audit-logs" && r.URL.Query().Get("comp") == "list":
+			assert.Equal(t, "versions", r.URL.Query().Get("include"))
+			if r.URL.Query().Get("marker") == "" {
+				fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><EnumerationResults><Blobs>
+					<Blob><Name>logs/a b.json</Name><VersionId>2026-01-15T09:00:00.0000001Z</VersionId><IsCurrentVersion>true</IsCurrentVersion>
+					<Properties><Last-Modified>Thu, 15 Jan 2026 09:00:00 GMT</Last-Modified><Content-Length>5</Content-Length></Properties></Blob>
+					</Blobs><NextMarker>page2</NextMarker></EnumerationResults>`)
+				return
+			}
+			fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><EnumerationResults><Blobs>
+				<Blob><Name>logs/b.json</Name><VersionId>2026-01-15T09:01:00.0000001Z</VersionId>
+				<Properties><Last-Modified>Thu, 15 Jan 2026 09:01:00 GMT</Last-Modified><Content-Length>7</Content-Length></Properties></Blob>
+				</Blobs><NextMarker /></EnumerationResults>`)
+		case r.Method == http.MethodGet && r.URL.Path == "/audit-logs/logs/a b.json":
+			assert.Equal(t, "2026-01-15T09:00:00.0000001Z", r.URL.Query().Get("versionid"))
+			fmt.Fprint(w, "hello")
+		case r.Method == http.MethodPut:
+			assert.Equal(t, "BlockBlob", r.Header.Get("x-ms-blob-type"))
+			assert.NotEmpty(t, r.Header.Get("Content-MD5"))
+			uploads[r.URL.Path], _ = io.ReadAll(r.Body)
+			w.Header().Set("x-ms-version-id", "2026-01-15T10:00:00.0000001Z")
+			w.WriteHeader(http.StatusCreated)
+		default:
+			w.WriteHeader(http.StatusForbidden)
+			fmt.Fprint(w, `<?xml version="1.0" encoding="utf-8"?><Error><Code>AuthorizationPermissionMismatch</Code><Message>This request is not authorized to perform this operation using this permission.
+RequestId:1</Message></Error>`)
+		}
+	}))
+}
+
+func TestAzureBlobStore(t *testing.T) {
+	uploads := map[string][]byte{}
+	server := fakeBlobService(t, uploads)
+	defer server.Close()
+
+	ctx := context.Background()
+	store := &AzureBlobStore{Endpoint: server.URL + "/", Container: "audit-logs", Token: arm.StaticToken("storage-token")}
+	assert.Equal(t, server.URL+"/audit-logs", store.URI())
+
+	var versions []Version
+	require.NoError(t, store.ListVersions(ctx, "", func(v Version) error {
+		versions = append(versions, v)
+		return nil
+	}))
+	require.Len(t, versions, 2)
+	assert.Equal(t, Version{
+		Key:          "logs/a b.json",
+		VersionID:    "2026-01-15T09:00:00.0000001Z",
+		LastModified: time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC),
+		Size:         5,
+		IsLatest:     true,
+	}, versions[0])
+	assert.False(t, versions[1].IsLatest)
+
+	record, err := DigestVersion(ctx, store, versions[0])
+	require.NoError(t, err)
+	assert.Equal(t, sha("hello"), record.SHA256)
+
+	versionID, err := store.Put(ctx, "_manifests/1.json", []byte("{}"), "application/json")
+	require.NoError(t, err)
+	assert.Equal(t, "2026-01-15T10:00:00.0000001Z", versionID)
+	assert.Equal(t, []byte("{}"), uploads["/audit-logs/_manifests/1.json"])
+
+	_, err = store.Open(ctx, "logs/missing.json", "")
+	assert.EqualError(t, err, "reading logs/missing.json version : AuthorizationPermissionMismatch: This request is not authorized to perform this operation using this permission.")
+}
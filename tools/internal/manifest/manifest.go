@@ -0,0 +1,187 @@
+// Package manifest writes tamper-evident digest manifests for the audit bucket or container.
+//
+// Object Lock stops deletion but not a privileged writer adding forged records. Each manifest
+// lists the object versions written in a time window with their SHA-256 digests, commits to
+// them with a Merkle root, links to the previous manifest by digest and is signed. Manifests
+// are stored under a reserved prefix in the same bucket or container, so they are locked
+// like the logs they describe, and a missing, inserted or altered record breaks the chain
+package manifest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/signing"
+)
+
+const (
+	// DefaultPrefix is the prefix that the module policies reserve for the manifest writer role
+	DefaultPrefix = "_manifests/"
+	// PayloadType identifies the signed payload of an Envelope
+	PayloadType = "application/vnd.auditledger.manifest+json"
+	// FormatVersion is the version of the Manifest document
+	FormatVersion = 1
+)
+
+// Record is one object version (or S3 delete marker) covered by a manifest
+type Record struct {
+	Key          string    `json:"key"`
+	VersionID    string    `json:"version_id"`
+	LastModified time.Time `json:"last_modified"`
+	Size         int64     `json:"size"`
+	SHA256       string    `json:"sha256,omitempty"`
+	DeleteMarker bool      `json:"delete_marker,omitempty"`
+}
+
+// Leaf is the Merkle leaf of the record: key, version ID, last modified time (RFC 3339, UTC),
+// size, hex SHA-256 and "object" or "delete-marker", separated by NUL bytes
+func (r Record) Leaf() []byte {
+	kind := "object"
+	if r.DeleteMarker {
+		kind = "delete-marker"
+	}
+	return []byte(strings.Join([]string{
+		r.Key,
+		r.VersionID,
+		r.LastModified.UTC().Format(time.RFC3339Nano),
+		strconv.FormatInt(r.Size, 10),
+		r.SHA256,
+		kind,
+	}, "\x00"))
+}
+
+// Window is the half-open interval (From, To] of last modified times a manifest covers
+type Window struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// Contains reports whether t falls in the window
+func (w Window) Contains(t time.Time) bool {
+	return t.After(w.From) && !t.After(w.To)
+}
+
+// Link points at the previous manifest in the chain
+type Link struct {
+	Key        string `json:"key"`
+	VersionID  string `json:"version_id"`
+	SHA256     string `json:"sha256"` // digest of the previous manifest object as stored
+	MerkleRoot string `json:"merkle_root"`
+}
+
+// Manifest commits to the records written in one window and to the previous manifest
+type Manifest struct {
+	Version     int       `json:"version"`
+	Store       string    `json:"store"`
+	Sequence    uint64    `json:"sequence"`
+	Previous    *Link     `json:"previous"` // nil for the first manifest
+	Window      Window    `json:"window"`
+	CreatedAt   time.Time `json:"created_at"`
+	RecordCount int       `json:"record_count"`
+	MerkleRoot  string    `json:"merkle_root"`
+	Records     []Record  `json:"records"`
+}
+
+// ComputeRoot returns the Merkle root of the records in manifest order
+func (m *Manifest) ComputeRoot() string {
+	leaves := make([][]byte, 0, len(m.Records))
+	for _, record := range m.Records {
+		leaves = append(leaves, record.Leaf())
+	}
+	return MerkleRoot(leaves)
+}
+
+// Signature is a signature over the envelope payload bytes
+type Signature struct {
+	KeyID     string `json:"key_id"`
+	Algorithm string `json:"algorithm"`
+	Signature string `json:"signature"` // base64
+}
+
+// Envelope is the stored form of a manifest. The signature covers the exact payload bytes,
+// so verifiers never need to re-serialise the manifest
+type Envelope struct {
+	PayloadType string      `json:"payload_type"`
+	Payload     string      `json:"payload"` // base64 of the manifest JSON
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Seal signs the manifest and returns the envelope to store
+func Seal(ctx context.Context, m *Manifest, signer signing.Signer) ([]byte, error) {
+	payload, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signer.Sign(ctx, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.MarshalIndent(Envelope{
+		PayloadType: PayloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures: []Signature{{
+			KeyID:     signer.KeyID(),
+			Algorithm: signer.Algorithm(),
+			Signature: base64.StdEncoding.EncodeToString(signature),
+		}},
+	}, "", "  ")
+}
+
+// Decode parses a stored envelope and its manifest without checking the signature
+func Decode(data []byte) (*Envelope, *Manifest, []byte, error) {
+	var envelope Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, nil, nil, fmt.Errorf("not a manifest envelope: %w", err)
+	}
+	if envelope.PayloadType != PayloadType {
+		return nil, nil, nil, fmt.Errorf("unexpected payload type %q", envelope.PayloadType)
+	}
+
+	payload, err := base64.StdEncoding.DecodeString(envelope.Payload)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("manifest payload: %w", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(payload, &m); err != nil {
+		return nil, nil, nil, fmt.Errorf("manifest payload: %w", err)
+	}
+	if m.Version != FormatVersion {
+		return nil, nil, nil, fmt.Errorf("unsupported manifest version %d", m.Version)
+	}
+	return &envelope, &m, payload, nil
+}
+
+// Digest is the hex SHA-256 of a stored manifest, as recorded in the next manifest's Link
+func Digest(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// ObjectKey names a manifest so that keys sort by sequence number
+func ObjectKey(prefix string, sequence uint64, windowEnd time.Time) string {
+	return fmt.Sprintf("%s%020d-%s.json", prefix, sequence, windowEnd.UTC().Format("20060102T150405Z"))
+}
+
+// ParseObjectKey returns the sequence number of a manifest key, or false for other keys under the prefix
+func ParseObjectKey(prefix, key string) (uint64, bool) {
+	if !strings.HasPrefix(key, prefix) {
+		return 0, false
+	}
+	name := key[len(prefix):]
+	if len(name) != len("00000000000000000000-20060102T150405Z.json") || !strings.HasSuffix(name, ".json") {
+		return 0, false
+	}
+	sequence, err := strconv.ParseUint(name[:20], 10, 64)
+	if err != nil || name[20] != '-' {
+		return 0, false
+	}
+	return sequence, true
+}
@@ -0,0 +1,98 @@
+package manifest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/signing"
+)
+
+// TestMerkleRootVectors uses the Certificate Transparency reference vectors
+func TestMerkleRootVectors(t *testing.T) {
+	leaves := [][]byte{
+		{},
+		{0x00},
+		{0x10},
+		{0x20, 0x21},
+		{0x30, 0x31},
+		{0x40, 0x41, 0x42, 0x43},
+		{0x50, 0x51, 0x52, 0x53, 0x54, 0x55, 0x56, 0x57},
+		{0x60, 0x61, 0x62, 0x63, 0x64, 0x65, 0x66, 0x67, 0x68, 0x69, 0x6a, 0x6b, 0x6c, 0x6d, 0x6e, 0x6f},
+	}
+	roots := []string{
+		"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		"6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d",
+		"fac54203e7cc696cf0dfcb42c92a1d9dbaf70ad9e621f4bd8d98662f00e3c125",
+		"aeb6bcfe274b70a14fb067a5e5578264db0fa9b51af5e0ba159158f329e06e77",
+		"d37ee418976dd95753c1c73862b9398fa2a2cf9b4ff0fdfe8b30cd95209614b7",
+		"4e3bbb1f7b478dcfe71fb631631519a3bca12c9aefca1612bfce4c13a86264d4",
+		"76e67dadbcdf1e10e1b74ddc608abd2f98dfb16fbce75277b5232a127f2087ef",
+		"ddb89be403809e325750d3d263cd78929c2942b7942a34b77e122c9594a74c8c",
+		"5dc9da79a70659a9ad559cb701ded9a2ab9d823aad2f4960cfe370eff4604328",
+	}
+	for n, root := range roots {
+		assert.Equal(t, root, MerkleRoot(leaves[:n]), "%d leaves", n)
+	}
+}
+
+func TestObjectKeys(t *testing.T) {
+	key := ObjectKey(DefaultPrefix, 42, time.Date(2026, 1, 15, 9, 30, 0, 0, time.UTC))
+	assert.Equal(t, "_manifests/00000000000000000042-20260115T093000Z.json", key)
+
+	sequence, ok := ParseObjectKey(DefaultPrefix, key)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(42), sequence)
+
+	for _, other := range []string{"_manifests/README.txt", "logs/00000000000000000042-20260115T093000Z.json", "_manifests/0000000000000000004x-20260115T093000Z.json"} {
+		_, ok := ParseObjectKey(DefaultPrefix, other)
+		assert.False(t, ok, other)
+	}
+}
+
+func TestSealAndDecode(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	m := &Manifest{
+		Version:  FormatVersion,
+		Store:    "s3://audit",
+		Sequence: 1,
+		Records:  []Record{{Key: "a.json", VersionID: "v1", Size: 2, SHA256: "00"}},
+	}
+	m.MerkleRoot = m.ComputeRoot()
+
+	data, err := Seal(context.Background(), m, signing.Ed25519Signer{Key: private})
+	require.NoError(t, err)
+
+	envelope, decoded, payload, err := Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, m.MerkleRoot, decoded.MerkleRoot)
+	assert.Equal(t, signing.Fingerprint(public), envelope.Signatures[0].KeyID)
+	assert.Equal(t, signing.AlgorithmEd25519, envelope.Signatures[0].Algorithm)
+
+	signature, err := base64.StdEncoding.DecodeString(envelope.Signatures[0].Signature)
+	require.NoError(t, err)
+	assert.True(t, ed25519.Verify(public, payload, signature), "The signature covers the payload bytes")
+
+	_, _, _, err = Decode([]byte(`{"payload_type": "application/json"}`))
+	assert.Error(t, err)
+}
+
+func TestRecordLeafBindsEveryField(t *testing.T) {
+	record := Record{Key: "logs/a.json", VersionID: "v1", LastModified: time.Unix(0, 0), Size: 10, SHA256: "ab"}
+	marker := record
+	marker.DeleteMarker = true
+	moved := record
+	moved.Key = "logs/b.json"
+
+	assert.Equal(t, "logs/a.json\x00v1\x001970-01-01T00:00:00Z\x0010\x00ab\x00object", string(record.Leaf()))
+	assert.NotEqual(t, record.Leaf(), marker.Leaf())
+	assert.NotEqual(t, record.Leaf(), moved.Leaf())
+}
@@ -0,0 +1,36 @@
+package manifest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// MerkleRoot computes the RFC 6962 Merkle tree hash of the leaves: leaf hashes are
+// SHA-256(0x00 || leaf) and node hashes SHA-256(0x01 || left || right), with the left
+// subtree holding the largest power of two smaller than the number of leaves. Any
+// Certificate Transparency library can recompute it
+func MerkleRoot(leaves [][]byte) string {
+	sum := treeHash(leaves)
+	return hex.EncodeToString(sum[:])
+}
+
+func treeHash(leaves [][]byte) [sha256.Size]byte {
+	switch len(leaves) {
+	case 0:
+		return sha256.Sum256(nil)
+	case 1:
+		return sha256.Sum256(append([]byte{0x00}, leaves[0]...))
+	}
+
+	split := 1
+	for split*2 < len(leaves) {
+		split *= 2
+	}
+	left, right := treeHash(leaves[:split]), treeHash(leaves[split:])
+
+	node := make([]byte, 0, 1+2*sha256.Size)
+	node = append(node, 0x01)
+	node = append(node, left[:]...)
+	node = append(node, right[:]...)
+	return sha256.Sum256(node)
+}
@@ -0,0 +1,116 @@
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+)
+
+// S3Store reads and writes an auditledger-s3 bucket (or a LocalStack or MinIO bucket with Object Lock)
+type S3Store struct {
+	API    s3iface.S3API
+	Bucket string
+
+	// The bucket policy only accepts writes that name the bucket's Object Lock mode and
+	// default encryption, so manifests are written with the same settings as audit logs
+	LockMode      string
+	RetentionDays int
+	SSEAlgorithm  string // AES256 or aws:kms ("" to rely on default encryption)
+	KMSKeyID      string
+}
+
+// URI implements Store
+func (s *S3Store) URI() string {
+	return "s3://" + s.Bucket
+}
+
+// ListVersions implements Store
+func (s *S3Store) ListVersions(ctx context.Context, prefix string, fn func(Version) error) error {
+	var callbackErr error
+	err := s.API.ListObjectVersionsPagesWithContext(ctx, &s3.ListObjectVersionsInput{
+		Bucket: aws.String(s.Bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectVersionsOutput, _ bool) bool {
+		for _, version := range page.Versions {
+			callbackErr = fn(Version{
+				Key:          aws.StringValue(version.Key),
+				VersionID:    aws.StringValue(version.VersionId),
+				LastModified: aws.TimeValue(version.LastModified),
+				Size:         aws.Int64Value(version.Size),
+				IsLatest:     aws.BoolValue(version.IsLatest),
+			})
+			if callbackErr != nil {
+				return false
+			}
+		}
+		for _, marker := range page.DeleteMarkers {
+			callbackErr = fn(Version{
+				Key:          aws.StringValue(marker.Key),
+				VersionID:    aws.StringValue(marker.VersionId),
+				LastModified: aws.TimeValue(marker.LastModified),
+				IsLatest:     aws.BoolValue(marker.IsLatest),
+				DeleteMarker: true,
+			})
+			if callbackErr != nil {
+				return false
+			}
+		}
+		return true
+	})
+	if callbackErr != nil {
+		return callbackErr
+	}
+	if err != nil {
+		return fmt.Errorf("listing versions in %s: %w", s.URI(), err)
+	}
+	return nil
+}
+
+// Open implements Store
+func (s *S3Store) Open(ctx context.Context, key, versionID string) (io.ReadCloser, error) {
+	out, err := s.API.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket:    aws.String(s.Bucket),
+		Key:       aws.String(key),
+		VersionId: aws.String(versionID),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading %s version %s: %w", key, versionID, err)
+	}
+	return out.Body, nil
+}
+
+// Put implements Store. Object Lock buckets require Content-MD5 on every write
+func (s *S3Store) Put(ctx context.Context, key string, body []byte, contentType string) (string, error) {
+	sum := md5.Sum(body)
+	input := &s3.PutObjectInput{
+		Bucket:      aws.String(s.Bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(body),
+		ContentType: aws.String(contentType),
+		ContentMD5:  aws.String(base64.StdEncoding.EncodeToString(sum[:])),
+	}
+	if s.LockMode != "" && s.RetentionDays > 0 {
+		input.ObjectLockMode = aws.String(s.LockMode)
+		input.ObjectLockRetainUntilDate = aws.Time(time.Now().UTC().AddDate(0, 0, s.RetentionDays))
+	}
+	if s.SSEAlgorithm != "" {
+		input.ServerSideEncryption = aws.String(s.SSEAlgorithm)
+	}
+	if s.KMSKeyID != "" {
+		input.SSEKMSKeyId = aws.String(s.KMSKeyID)
+	}
+
+	out, err := s.API.PutObjectWithContext(ctx, input)
+	if err != nil {
+		return "", fmt.Errorf("writing %s: %w", key, err)
+	}
+	return aws.StringValue(out.VersionId), nil
+}
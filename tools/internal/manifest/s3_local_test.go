@@ -0,0 +1,122 @@
+package manifest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/signing"
+)
+
+// localS3 returns a client for LocalStack or the MinIO container, or skips the test
+func localS3(t *testing.T) *s3.S3 {
+	t.Helper()
+
+	var config *aws.Config
+	switch {
+	case os.Getenv("USE_LOCALSTACK") == "true":
+		config = &aws.Config{
+			Endpoint:    aws.String(envOrDefault("AWS_ENDPOINT_URL", "http://localhost:4566")),
+			Credentials: credentials.NewStaticCredentials("test", "test", ""),
+		}
+	case os.Getenv("USE_MINIO") == "true":
+		scheme := "http"
+		if os.Getenv("MINIO_ENABLE_HTTPS") == "true" {
+			scheme = "https"
+		}
+		config = &aws.Config{
+			Endpoint:    aws.String(fmt.Sprintf("%s://%s", scheme, envOrDefault("MINIO_ENDPOINT", "localhost:9000"))),
+			Credentials: credentials.NewStaticCredentials(envOrDefault("MINIO_USER", "minioadmin"), envOrDefault("MINIO_PASSWORD", "minioadmin"), ""),
+		}
+	default:
+		t.Skip("Skipping local S3 test - set USE_LOCALSTACK=true or USE_MINIO=true to run")
+	}
+
+	config.Region = aws.String("us-east-1")
+	config.S3ForcePathStyle = aws.Bool(true)
+	sess, err := session.NewSession(config)
+	require.NoError(t, err)
+	return s3.New(sess)
+}
+
+func envOrDefault(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// TestS3StoreLocal seals two manifests into a GOVERNANCE-mode Object Lock bucket
+// Run with: USE_LOCALSTACK=true go test -v -run Local ./internal/manifest/
+func TestS3StoreLocal(t *testing.T) {
+	api := localS3(t)
+	ctx := context.Background()
+	bucket := fmt.Sprintf("test-manifest-%d", time.Now().UnixNano())
+
+	_, err := api.CreateBucketWithContext(ctx, &s3.CreateBucketInput{Bucket: aws.String(bucket), ObjectLockEnabledForBucket: aws.Bool(true)})
+	require.NoError(t, err)
+	defer emptyBucket(t, api, bucket)
+
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	store := &S3Store{API: api, Bucket: bucket, LockMode: s3.ObjectLockModeGovernance, RetentionDays: 1, SSEAlgorithm: s3.ServerSideEncryptionAes256}
+	writer := &Writer{Store: store, Signer: signing.Ed25519Signer{Key: private}}
+	for _, key := range []string{"logs/a.json", "logs/b.json", "logs/a.json"} {
+		_, err := store.Put(ctx, key, []byte(`{"event": "`+key+`"}`), "application/json")
+		require.NoError(t, err)
+	}
+
+	// Local emulators stamp whole seconds; wait so the window closes after the uploads
+	time.Sleep(1100 * time.Millisecond)
+	first, err := writer.Write(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, 3, first.Manifest.RecordCount, "Every version is recorded, including overwrites")
+
+	retention, err := api.GetObjectRetentionWithContext(ctx, &s3.GetObjectRetentionInput{Bucket: aws.String(bucket), Key: aws.String(first.Key), VersionId: aws.String(first.VersionID)})
+	require.NoError(t, err)
+	assert.Equal(t, s3.ObjectLockModeGovernance, aws.StringValue(retention.Retention.Mode))
+
+	// Without a settle delay the next upload must land after the window end
+	time.Sleep(1100 * time.Millisecond)
+	_, err = store.Put(ctx, "logs/c.json", []byte(`{}`), "application/json")
+	require.NoError(t, err)
+	time.Sleep(1100 * time.Millisecond)
+
+	second, err := writer.Write(ctx)
+	require.NoError(t, err)
+	require.NotNil(t, second.Manifest.Previous)
+	assert.Equal(t, first.Key, second.Manifest.Previous.Key)
+	assert.Equal(t, first.Manifest.MerkleRoot, second.Manifest.Previous.MerkleRoot)
+	require.Len(t, second.Manifest.Records, 1)
+	assert.Equal(t, "logs/c.json", second.Manifest.Records[0].Key)
+	assert.False(t, strings.HasPrefix(second.Manifest.Records[0].Key, DefaultPrefix))
+}
+
+func emptyBucket(t *testing.T, api *s3.S3, bucket string) {
+	err := api.ListObjectVersionsPages(&s3.ListObjectVersionsInput{Bucket: aws.String(bucket)}, func(page *s3.ListObjectVersionsOutput, _ bool) bool {
+		for _, version := range page.Versions {
+			_, _ = api.DeleteObject(&s3.DeleteObjectInput{Bucket: aws.String(bucket), Key: version.Key, VersionId: version.VersionId, BypassGovernanceRetention: aws.Bool(true)})
+		}
+		for _, marker := range page.DeleteMarkers {
+			_, _ = api.DeleteObject(&s3.DeleteObjectInput{Bucket: aws.String(bucket), Key: marker.Key, VersionId: marker.VersionId})
+		}
+		return true
+	})
+	if err != nil {
+		t.Logf("Warning: Could not empty %s: %v", bucket, err)
+	}
+	_, _ = api.DeleteBucket(&s3.DeleteBucketInput{Bucket: aws.String(bucket)})
+}
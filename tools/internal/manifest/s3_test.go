@@ -0,0 +1,85 @@
+package manifest
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeS3 struct {
+	s3iface.S3API
+	pages []*s3.ListObjectVersionsOutput
+	put   *s3.PutObjectInput
+	body  []byte
+}
+
+func (f *fakeS3) ListObjectVersionsPagesWithContext(_ aws.Context, _ *s3.ListObjectVersionsInput, fn func(*s3.ListObjectVersionsOutput, bool) bool, _ ...request.Option) error {
+	for i, page := range f.pages {
+		if !fn(page, i == len(f.pages)-1) {
+			break
+		}
+	}
+	return nil
+}
+
+func (f *fakeS3) GetObjectWithContext(_ aws.Context, input *s3.GetObjectInput, _ ...request.Option) (*s3.GetObjectOutput, error) {
+	return &s3.GetObjectOutput{Body: io.NopCloser(strings.NewReader(aws.StringValue(input.Key) + "@" + aws.StringValue(input.VersionId)))}, nil
+}
+
+func (f *fakeS3) PutObjectWithContext(_ aws.Context, input *s3.PutObjectInput, _ ...request.Option) (*s3.PutObjectOutput, error) {
+	f.put = input
+	f.body, _ = io.ReadAll(input.Body)
+	return &s3.PutObjectOutput{VersionId: aws.String("manifest-v1")}, nil
+}
+
+func TestS3StoreListsVersionsAndDeleteMarkers(t *testing.T) {
+	modified := time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC)
+	api := &fakeS3{pages: []*s3.ListObjectVersionsOutput{
+		{Versions: []*s3.ObjectVersion{{Key: aws.String("a.json"), VersionId: aws.String("1"), LastModified: aws.Time(modified), Size: aws.Int64(3), IsLatest: aws.Bool(true)}}},
+		{DeleteMarkers: []*s3.DeleteMarkerEntry{{Key: aws.String("b.json"), VersionId: aws.String("2"), LastModified: aws.Time(modified), IsLatest: aws.Bool(true)}}},
+	}}
+	store := &S3Store{API: api, Bucket: "audit"}
+
+	var versions []Version
+	require.NoError(t, store.ListVersions(context.Background(), "", func(v Version) error {
+		versions = append(versions, v)
+		return nil
+	}))
+	assert.Equal(t, []Version{
+		{Key: "a.json", VersionID: "1", LastModified: modified, Size: 3, IsLatest: true},
+		{Key: "b.json", VersionID: "2", LastModified: modified, IsLatest: true, DeleteMarker: true},
+	}, versions)
+	assert.Equal(t, "s3://audit", store.URI())
+
+	record, err := DigestVersion(context.Background(), store, versions[0])
+	require.NoError(t, err)
+	assert.Equal(t, sha("a.json@1"), record.SHA256, "Digests read the exact version")
+}
+
+func TestS3StorePutMatchesBucketPolicy(t *testing.T) {
+	api := &fakeS3{}
+	store := &S3Store{API: api, Bucket: "audit", LockMode: "COMPLIANCE", RetentionDays: 2555, SSEAlgorithm: "aws:kms", KMSKeyID: "alias/audit"}
+
+	versionID, err := store.Put(context.Background(), "_manifests/1.json", []byte("{}"), "application/json")
+	require.NoError(t, err)
+	assert.Equal(t, "manifest-v1", versionID)
+
+	sum := md5.Sum([]byte("{}"))
+	assert.Equal(t, base64.StdEncoding.EncodeToString(sum[:]), aws.StringValue(api.put.ContentMD5), "Object Lock buckets require Content-MD5")
+	assert.Equal(t, "COMPLIANCE", aws.StringValue(api.put.ObjectLockMode), "AllowAuditLedgerWrite requires the lock mode header")
+	assert.WithinDuration(t, time.Now().AddDate(0, 0, 2555), aws.TimeValue(api.put.ObjectLockRetainUntilDate), time.Minute)
+	assert.Equal(t, "aws:kms", aws.StringValue(api.put.ServerSideEncryption), "DenyUnencryptedObjectUploads requires the encryption header")
+	assert.Equal(t, "alias/audit", aws.StringValue(api.put.SSEKMSKeyId))
+	assert.Equal(t, []byte("{}"), api.body)
+}
@@ -0,0 +1,29 @@
+package manifest
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+// Version is one object version, or an S3 delete marker, in the bucket or container
+type Version struct {
+	Key          string
+	VersionID    string
+	LastModified time.Time
+	Size         int64
+	IsLatest     bool
+	DeleteMarker bool
+}
+
+// Store is the versioned bucket or container that holds the audit logs and their manifests
+type Store interface {
+	// URI identifies the bucket or container in manifests, e.g. s3://acme-audit-logs
+	URI() string
+	// ListVersions calls fn for every version whose key starts with prefix
+	ListVersions(ctx context.Context, prefix string, fn func(Version) error) error
+	// Open reads one version of an object
+	Open(ctx context.Context, key, versionID string) (io.ReadCloser, error)
+	// Put writes a new locked object and returns its version ID
+	Put(ctx context.Context, key string, body []byte, contentType string) (string, error)
+}
@@ -0,0 +1,430 @@
+package manifest
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/signing"
+)
+
+// Finding kinds, in the order a report lists them
+const (
+	FindingManifestUnexpected  = "manifest.unexpected_object"
+	FindingManifestInvalid     = "manifest.invalid"
+	FindingManifestSignature   = "manifest.signature"
+	FindingManifestMissing     = "manifest.missing"
+	FindingManifestLink        = "manifest.link"
+	FindingManifestOverwritten = "manifest.overwritten"
+	FindingRecordOutOfOrder    = "record.out_of_order"
+	FindingRecordDuplicate     = "record.duplicate"
+	FindingRecordMissing       = "record.missing"
+	FindingRecordAltered       = "record.altered"
+	FindingRecordUnrecorded    = "record.unrecorded"
+)
+
+// FindingKinds lists every finding kind
+var FindingKinds = []string{
+	FindingManifestUnexpected,
+	FindingManifestInvalid,
+	FindingManifestSignature,
+	FindingManifestMissing,
+	FindingManifestLink,
+	FindingManifestOverwritten,
+	FindingRecordOutOfOrder,
+	FindingRecordDuplicate,
+	FindingRecordMissing,
+	FindingRecordAltered,
+	FindingRecordUnrecorded,
+}
+
+// Finding is one problem with the chain or the objects it covers
+type Finding struct {
+	Kind      string `json:"kind"`
+	Manifest  string `json:"manifest,omitempty"` // key of the manifest the finding comes from
+	Key       string `json:"key,omitempty"`
+	VersionID string `json:"version_id,omitempty"`
+	Detail    string `json:"detail"`
+}
+
+func (f Finding) String() string {
+	var b strings.Builder
+	if f.Key != "" {
+		fmt.Fprintf(&b, "%s version %s: ", f.Key, f.VersionID)
+	}
+	b.WriteString(f.Detail)
+	if f.Manifest != "" && f.Manifest != f.Key {
+		fmt.Fprintf(&b, " (manifest %s)", f.Manifest)
+	}
+	return b.String()
+}
+
+// ChainVerifier replays a manifest chain against the store. It needs only read access
+// and the signer's public key, so auditors can run it with their own credentials
+type ChainVerifier struct {
+	Store    Store
+	Verifier signing.Verifier
+	Prefix   string // DefaultPrefix if empty
+
+	// From and To limit the object versions that are re-digested and checked for
+	// missing manifest entries to (From, To]; zero values leave that side open.
+	// Signatures and links are checked for the whole chain either way
+	From, To time.Time
+	// Parallelism is the number of objects read at once (default 1)
+	Parallelism int
+}
+
+// ChainReport summarises a verification run
+type ChainReport struct {
+	Manifests     int       `json:"manifests"`
+	FirstSequence uint64    `json:"first_sequence"`
+	LastSequence  uint64    `json:"last_sequence"`
+	SealedUntil   time.Time `json:"sealed_until"` // window end of the last manifest
+	Records       int       `json:"records"`      // records in range that were re-digested
+	Pending       int       `json:"pending"`      // versions newer than the last manifest
+	Findings      []Finding `json:"findings"`
+}
+
+// entry is the version of a manifest key that the chain uses
+type entry struct {
+	Version
+	sequence uint64
+	data     []byte
+	manifest *Manifest
+	err      error
+}
+
+// Verify walks the chain and returns every finding; the error is for failures to read the store
+func (v *ChainVerifier) Verify(ctx context.Context) (*ChainReport, error) {
+	prefix := v.Prefix
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	report := &ChainReport{Findings: []Finding{}}
+
+	entries, err := v.manifests(ctx, prefix, report)
+	if err != nil {
+		return nil, err
+	}
+	sealed := v.checkChain(entries, report)
+
+	var versions []Version
+	err = v.Store.ListVersions(ctx, "", func(version Version) error {
+		if !strings.HasPrefix(version.Key, prefix) {
+			versions = append(versions, version)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := v.checkRecords(ctx, sealed, versions, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// manifests lists and reads the manifests under the prefix in sequence order. Object Lock
+// keeps the first version of each key, so later versions are reported and ignored
+func (v *ChainVerifier) manifests(ctx context.Context, prefix string, report *ChainReport) ([]*entry, error) {
+	byKey := map[string][]Version{}
+	err := v.Store.ListVersions(ctx, prefix, func(version Version) error {
+		if _, ok := ParseObjectKey(prefix, version.Key); !ok {
+			report.add(Finding{Kind: FindingManifestUnexpected, Key: version.Key, VersionID: version.VersionID, Detail: "only manifests may be written under " + prefix})
+			return nil
+		}
+		byKey[version.Key] = append(byKey[version.Key], version)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]string, 0, len(byKey))
+	for key := range byKey {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var entries []*entry
+	for _, key := range keys {
+		versions := byKey[key]
+		sort.SliceStable(versions, func(i, j int) bool { return versions[i].LastModified.Before(versions[j].LastModified) })
+		first := -1
+		for i, version := range versions {
+			switch {
+			case version.DeleteMarker:
+				report.add(Finding{Kind: FindingManifestOverwritten, Manifest: key, Key: key, VersionID: version.VersionID, Detail: "the manifest was hidden behind a delete marker"})
+			case first >= 0:
+				report.add(Finding{Kind: FindingManifestOverwritten, Manifest: key, Key: key, VersionID: version.VersionID, Detail: "a later version was written over the manifest; the chain uses version " + versions[first].VersionID})
+			default:
+				first = i
+			}
+		}
+		if first >= 0 {
+			sequence, _ := ParseObjectKey(prefix, key)
+			entries = append(entries, &entry{Version: versions[first], sequence: sequence})
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].sequence != entries[j].sequence {
+			return entries[i].sequence < entries[j].sequence
+		}
+		return entries[i].Key < entries[j].Key
+	})
+
+	errs := make([]error, len(entries))
+	parallel(len(entries), v.Parallelism, func(index int) {
+		e := entries[index]
+		if e.data, errs[index] = readAll(ctx, v.Store, e.Key, e.VersionID); errs[index] == nil {
+			e.err, errs[index] = v.checkManifest(ctx, e)
+		}
+	})
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// checkManifest decodes a manifest and checks its signature and Merkle root. It returns
+// the problem with the manifest, or an error if the verifier itself failed
+func (v *ChainVerifier) checkManifest(ctx context.Context, e *entry) (problem, err error) {
+	envelope, m, payload, err := Decode(e.data)
+	if err != nil {
+		return err, nil
+	}
+	e.manifest = m
+	if m.Sequence != e.sequence {
+		return fmt.Errorf("the manifest records sequence %d but its key has %d", m.Sequence, e.sequence), nil
+	}
+	if m.RecordCount != len(m.Records) || m.ComputeRoot() != m.MerkleRoot {
+		return fmt.Errorf("the Merkle root or record count does not match the records"), nil
+	}
+
+	var failures []string
+	for _, signature := range envelope.Signatures {
+		if signature.Algorithm != v.Verifier.Algorithm() {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(signature.Signature)
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", signature.KeyID, err))
+			continue
+		}
+		err = v.Verifier.Verify(ctx, payload, raw)
+		if err == nil {
+			return nil, nil
+		}
+		if !errors.Is(err, signing.ErrBadSignature) {
+			return nil, err
+		}
+		failures = append(failures, fmt.Sprintf("%s: %v", signature.KeyID, err))
+	}
+	if len(failures) == 0 {
+		return signatureError(fmt.Sprintf("no %s signature", v.Verifier.Algorithm())), nil
+	}
+	return signatureError(fmt.Sprintf("no signature verifies with key %s (%s)", v.Verifier.KeyID(), strings.Join(failures, "; "))), nil
+}
+
+type signatureError string
+
+func (e signatureError) Error() string { return string(e) }
+
+// checkChain checks sequence numbers, links and windows, and returns the manifests whose
+// records can be trusted
+func (v *ChainVerifier) checkChain(entries []*entry, report *ChainReport) []*entry {
+	var sealed []*entry
+	var previous *entry
+	var expected uint64 = 1
+	for _, e := range entries {
+		if e.err != nil {
+			kind := FindingManifestInvalid
+			if _, ok := e.err.(signatureError); ok {
+				kind = FindingManifestSignature
+			}
+			report.add(Finding{Kind: kind, Manifest: e.Key, Key: e.Key, VersionID: e.VersionID, Detail: e.err.Error()})
+			// Count the sequence from its key so the next manifest is not reported as a gap
+			if e.sequence >= expected {
+				previous, expected = nil, e.sequence+1
+			}
+			continue
+		}
+
+		m := e.manifest
+		if m.Sequence < expected {
+			report.add(Finding{Kind: FindingManifestLink, Manifest: e.Key, Key: e.Key, VersionID: e.VersionID, Detail: fmt.Sprintf("sequence %d appears more than once", m.Sequence)})
+			continue
+		}
+		if m.Sequence > expected {
+			report.add(Finding{Kind: FindingManifestMissing, Manifest: e.Key, Detail: fmt.Sprintf("manifests %s are missing before sequence %d", sequenceRange(expected, m.Sequence-1), m.Sequence)})
+		}
+		v.checkLink(e, previous, report)
+
+		if report.Manifests == 0 {
+			report.FirstSequence = m.Sequence
+		}
+		report.Manifests++
+		report.LastSequence = m.Sequence
+		report.SealedUntil = m.Window.To
+		sealed = append(sealed, e)
+		previous, expected = e, m.Sequence+1
+	}
+	return sealed
+}
+
+func (v *ChainVerifier) checkLink(e, previous *entry, report *ChainReport) {
+	m := e.manifest
+	broken := func(format string, args ...interface{}) {
+		report.add(Finding{Kind: FindingManifestLink, Manifest: e.Key, Key: e.Key, VersionID: e.VersionID, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	if previous == nil {
+		if m.Sequence == 1 && m.Previous != nil {
+			broken("the first manifest links to %s", m.Previous.Key)
+		}
+		return
+	}
+	if previous.manifest.Sequence+1 != m.Sequence {
+		// The gap is already reported; links across it cannot be checked
+		return
+	}
+
+	link, want := m.Previous, previous.manifest
+	switch {
+	case link == nil:
+		broken("sequence %d does not link to the previous manifest", m.Sequence)
+	case link.Key != previous.Key || link.VersionID != previous.VersionID:
+		broken("links to %s version %s instead of %s version %s", link.Key, link.VersionID, previous.Key, previous.VersionID)
+	case link.SHA256 != Digest(previous.data):
+		broken("the previous manifest %s was altered: its SHA-256 is %s, the link records %s", previous.Key, Digest(previous.data), link.SHA256)
+	case link.MerkleRoot != want.MerkleRoot:
+		broken("the link records Merkle root %s but %s has %s", link.MerkleRoot, previous.Key, want.MerkleRoot)
+	case !m.Window.From.Equal(want.Window.To):
+		broken("the window starts at %s but the previous manifest ends at %s", m.Window.From.Format(time.RFC3339), want.Window.To.Format(time.RFC3339))
+	}
+}
+
+// checkRecords compares the records of the sealed manifests with the object versions in the store
+func (v *ChainVerifier) checkRecords(ctx context.Context, sealed []*entry, versions []Version, report *ChainReport) error {
+	inRange := func(t time.Time) bool {
+		return (v.From.IsZero() || t.After(v.From)) && (v.To.IsZero() || !t.After(v.To))
+	}
+
+	type recorded struct {
+		Record
+		manifest string
+	}
+	var records []recorded
+	seen := map[string]string{}
+	for _, e := range sealed {
+		m := e.manifest
+		for i, record := range m.Records {
+			if !m.Window.Contains(record.LastModified) {
+				report.add(Finding{Kind: FindingRecordOutOfOrder, Manifest: e.Key, Key: record.Key, VersionID: record.VersionID,
+					Detail: fmt.Sprintf("last modified %s is outside the manifest window", record.LastModified.Format(time.RFC3339))})
+			} else if i > 0 && recordLess(record, m.Records[i-1]) {
+				report.add(Finding{Kind: FindingRecordOutOfOrder, Manifest: e.Key, Key: record.Key, VersionID: record.VersionID,
+					Detail: fmt.Sprintf("listed after %s version %s, which was written later", m.Records[i-1].Key, m.Records[i-1].VersionID)})
+			}
+
+			id := record.Key + "\x00" + record.VersionID
+			if other, ok := seen[id]; ok {
+				report.add(Finding{Kind: FindingRecordDuplicate, Manifest: e.Key, Key: record.Key, VersionID: record.VersionID, Detail: "already recorded in " + other})
+				continue
+			}
+			seen[id] = e.Key
+			if inRange(record.LastModified) {
+				records = append(records, recorded{record, e.Key})
+			}
+		}
+	}
+
+	stored := map[string]Version{}
+	for _, version := range versions {
+		id := version.Key + "\x00" + version.VersionID
+		stored[id] = version
+		if _, ok := seen[id]; ok || !inRange(version.LastModified) {
+			continue
+		}
+		if len(sealed) > 0 && !version.LastModified.After(report.SealedUntil) {
+			report.add(Finding{Kind: FindingRecordUnrecorded, Key: version.Key, VersionID: version.VersionID,
+				Detail: fmt.Sprintf("written at %s, a time covered by the chain, but in no manifest", version.LastModified.UTC().Format(time.RFC3339))})
+		} else {
+			report.Pending++
+		}
+	}
+
+	findings := make([]*Finding, len(records))
+	errs := make([]error, len(records))
+	parallel(len(records), v.Parallelism, func(index int) {
+		record := records[index]
+		version, ok := stored[record.Key+"\x00"+record.VersionID]
+		if !ok {
+			findings[index] = &Finding{Kind: FindingRecordMissing, Manifest: record.manifest, Key: record.Key, VersionID: record.VersionID, Detail: "the version no longer exists"}
+			return
+		}
+		current, err := DigestVersion(ctx, v.Store, version)
+		if err != nil {
+			errs[index] = err
+			return
+		}
+		if detail := compareRecords(record.Record, current); detail != "" {
+			findings[index] = &Finding{Kind: FindingRecordAltered, Manifest: record.manifest, Key: record.Key, VersionID: record.VersionID, Detail: detail}
+		}
+	})
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	for _, finding := range findings {
+		if finding != nil {
+			report.add(*finding)
+		}
+	}
+	report.Records = len(records)
+	return nil
+}
+
+// compareRecords describes how the stored version differs from its record, or returns ""
+func compareRecords(recorded, current Record) string {
+	switch {
+	case recorded.DeleteMarker != current.DeleteMarker:
+		return fmt.Sprintf("recorded as delete marker %t, stored as delete marker %t", recorded.DeleteMarker, current.DeleteMarker)
+	case recorded.SHA256 != current.SHA256:
+		return fmt.Sprintf("SHA-256 is %s, the manifest records %s", current.SHA256, recorded.SHA256)
+	case recorded.Size != current.Size:
+		return fmt.Sprintf("size is %d bytes, the manifest records %d", current.Size, recorded.Size)
+	case !recorded.LastModified.Equal(current.LastModified):
+		return fmt.Sprintf("last modified %s, the manifest records %s", current.LastModified.Format(time.RFC3339), recorded.LastModified.Format(time.RFC3339))
+	}
+	return ""
+}
+
+// recordLess is the order SortRecords produces
+func recordLess(a, b Record) bool {
+	if !a.LastModified.Equal(b.LastModified) {
+		return a.LastModified.Before(b.LastModified)
+	}
+	if a.Key != b.Key {
+		return a.Key < b.Key
+	}
+	return a.VersionID < b.VersionID
+}
+
+func sequenceRange(from, to uint64) string {
+	if from == to {
+		return fmt.Sprint(from)
+	}
+	return fmt.Sprintf("%d-%d", from, to)
+}
+
+func (r *ChainReport) add(f Finding) {
+	r.Findings = append(r.Findings, f)
+}
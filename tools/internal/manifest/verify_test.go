@@ -0,0 +1,206 @@
+package manifest
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/signing"
+)
+
+// sealedStore returns a store with three chained manifests over five objects and one pending upload
+func sealedStore(t *testing.T) (*memStore, *Writer, *ChainVerifier) {
+	t.Helper()
+	store := &memStore{}
+	writer, public := newWriter(t, store)
+
+	for hour := 0; hour < 3; hour++ {
+		base := epoch.Add(time.Duration(hour) * time.Hour)
+		store.add("logs/a.json", []byte("a"+base.String()), base.Add(10*time.Minute))
+		if hour == 1 {
+			store.add("logs/b.json", []byte("b"), base.Add(20*time.Minute))
+			store.deleteMarker("logs/b.json", base.Add(30*time.Minute))
+		}
+		store.now = base.Add(time.Hour)
+		_, err := writer.Write(context.Background())
+		require.NoError(t, err)
+	}
+	store.add("logs/pending.json", []byte("p"), store.now.Add(-time.Minute))
+
+	return store, writer, &ChainVerifier{Store: store, Verifier: signing.Ed25519Verifier{Key: public}, Parallelism: 4}
+}
+
+func (s *memStore) find(key string, index int) *memObject {
+	var matches []*memObject
+	for _, object := range s.objects {
+		if object.Key == key {
+			matches = append(matches, object)
+		}
+	}
+	return matches[index]
+}
+
+func (s *memStore) manifestKey(sequence uint64) string {
+	for _, object := range s.objects {
+		if seq, ok := ParseObjectKey(DefaultPrefix, object.Key); ok && seq == sequence {
+			return object.Key
+		}
+	}
+	return ""
+}
+
+func (s *memStore) remove(object *memObject) {
+	for i, candidate := range s.objects {
+		if candidate == object {
+			s.objects = append(s.objects[:i], s.objects[i+1:]...)
+			return
+		}
+	}
+}
+
+func kinds(report *ChainReport) []string {
+	var found []string
+	for _, finding := range report.Findings {
+		found = append(found, finding.Kind)
+	}
+	return found
+}
+
+func TestVerifyChainIntact(t *testing.T) {
+	_, _, verifier := sealedStore(t)
+
+	report, err := verifier.Verify(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, report.Findings)
+	assert.Equal(t, 3, report.Manifests)
+	assert.Equal(t, uint64(1), report.FirstSequence)
+	assert.Equal(t, uint64(3), report.LastSequence)
+	assert.Equal(t, 5, report.Records)
+	assert.Equal(t, 1, report.Pending, "Uploads after the last window are pending, not unrecorded")
+}
+
+func TestVerifyChainDetectsRecordTampering(t *testing.T) {
+	store, _, verifier := sealedStore(t)
+
+	store.find("logs/a.json", 0).body = []byte("rewritten")
+	store.remove(store.find("logs/a.json", 1))
+	forged := store.add("logs/forged.json", []byte("x"), epoch.Add(90*time.Minute))
+
+	report, err := verifier.Verify(context.Background())
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{FindingRecordAltered, FindingRecordMissing, FindingRecordUnrecorded}, kinds(report))
+
+	for _, finding := range report.Findings {
+		switch finding.Kind {
+		case FindingRecordAltered:
+			assert.Equal(t, "logs/a.json", finding.Key)
+			assert.Equal(t, store.manifestKey(1), finding.Manifest)
+			assert.Contains(t, finding.Detail, sha("rewritten"))
+		case FindingRecordMissing:
+			assert.Equal(t, store.manifestKey(2), finding.Manifest)
+		case FindingRecordUnrecorded:
+			assert.Equal(t, forged.VersionID, finding.VersionID)
+			assert.Equal(t, "logs/forged.json version "+forged.VersionID+": written at 2026-01-15T10:30:00Z, a time covered by the chain, but in no manifest", finding.String())
+		}
+	}
+}
+
+func TestVerifyChainTimeRange(t *testing.T) {
+	store, _, verifier := sealedStore(t)
+	store.find("logs/a.json", 0).body = []byte("rewritten")
+
+	verifier.From = epoch.Add(time.Hour)
+	report, err := verifier.Verify(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, report.Findings, "Records before the range are not re-digested")
+	assert.Equal(t, 4, report.Records)
+}
+
+func TestVerifyChainDetectsManifestTampering(t *testing.T) {
+	ctx := context.Background()
+	store, _, verifier := sealedStore(t)
+
+	// A later version over manifest 1 is ignored; the chain keeps using the locked original
+	store.add(store.manifestKey(1), []byte("{}"), store.now)
+	// Only manifests belong under the prefix
+	store.add(DefaultPrefix+"notes.txt", []byte("hello"), store.now)
+	// Manifest 2 is re-signed by another key
+	_, other, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	original := store.find(store.manifestKey(2), 0)
+	_, m, _, err := Decode(original.body)
+	require.NoError(t, err)
+	original.body, err = Seal(ctx, m, signing.Ed25519Signer{Key: other})
+	require.NoError(t, err)
+
+	report, err := verifier.Verify(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{FindingManifestOverwritten, FindingManifestUnexpected, FindingManifestSignature, FindingRecordUnrecorded, FindingRecordUnrecorded, FindingRecordUnrecorded}, kinds(report),
+		"Records of the untrusted manifest are reported as unrecorded")
+	assert.Equal(t, 2, report.Manifests)
+
+	// Removing manifest 2 entirely (GOVERNANCE bypass) leaves a gap in the sequence
+	store.remove(original)
+
+	report, err = verifier.Verify(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, kinds(report), FindingManifestMissing)
+	assert.NotContains(t, kinds(report), FindingManifestLink, "Links across a gap are not checked")
+}
+
+func TestVerifyChainDetectsBrokenLinks(t *testing.T) {
+	ctx := context.Background()
+	store, writer, verifier := sealedStore(t)
+
+	// Manifest 1 is rewritten in place and re-signed with the right key, e.g. by someone
+	// holding the signing key - the digest in manifest 2's link no longer matches
+	first := store.find(store.manifestKey(1), 0)
+	_, m, _, err := Decode(first.body)
+	require.NoError(t, err)
+	m.Records = m.Records[:0]
+	m.RecordCount = 0
+	m.MerkleRoot = m.ComputeRoot()
+	first.body, err = Seal(ctx, m, writer.Signer)
+	require.NoError(t, err)
+
+	report, err := verifier.Verify(ctx)
+	require.NoError(t, err)
+	assert.Contains(t, kinds(report), FindingManifestLink)
+	assert.Contains(t, kinds(report), FindingRecordUnrecorded, "The record dropped from manifest 1 is no longer covered")
+}
+
+func TestVerifyChainDetectsOutOfOrderRecords(t *testing.T) {
+	ctx := context.Background()
+	store := &memStore{now: epoch.Add(time.Hour)}
+	writer, public := newWriter(t, store)
+
+	late := store.add("logs/late.json", []byte("late"), epoch.Add(20*time.Minute))
+	early := store.add("logs/early.json", []byte("early"), epoch.Add(10*time.Minute))
+	outside := store.add("logs/outside.json", []byte("outside"), epoch.Add(2*time.Hour))
+
+	var records []Record
+	for _, version := range []Version{late, early, outside} {
+		record, err := DigestVersion(ctx, store, version)
+		require.NoError(t, err)
+		records = append(records, record)
+	}
+	m := &Manifest{Version: FormatVersion, Store: store.URI(), Sequence: 1, Window: Window{To: epoch.Add(time.Hour)}, Records: records, RecordCount: 3}
+	m.MerkleRoot = m.ComputeRoot()
+	data, err := Seal(ctx, m, writer.Signer)
+	require.NoError(t, err)
+	_, err = store.Put(ctx, ObjectKey(DefaultPrefix, 1, m.Window.To), data, "application/json")
+	require.NoError(t, err)
+
+	verifier := &ChainVerifier{Store: store, Verifier: signing.Ed25519Verifier{Key: public}}
+	report, err := verifier.Verify(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{FindingRecordOutOfOrder, FindingRecordOutOfOrder}, kinds(report))
+	assert.Equal(t, "logs/early.json", report.Findings[0].Key)
+	assert.Equal(t, "logs/outside.json", report.Findings[1].Key)
+	assert.Contains(t, report.Findings[1].Detail, "outside the manifest window")
+}
@@ -0,0 +1,223 @@
+package manifest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/signing"
+)
+
+// ErrNothingToSeal is returned when the next window has not ended yet
+var ErrNothingToSeal = errors.New("the previous manifest already covers everything up to the settle delay")
+
+// Writer seals the object versions written since the previous manifest into a new one
+type Writer struct {
+	Store  Store
+	Signer signing.Signer
+	Prefix string // DefaultPrefix if empty
+
+	// SettleDelay keeps the window end behind the clock, so uploads still in flight when
+	// the window closes are not missed by this manifest or the next
+	SettleDelay time.Duration
+	// Parallelism is the number of objects digested at once (default 1)
+	Parallelism int
+	// Now returns the current time (default time.Now)
+	Now func() time.Time
+}
+
+// Result is a manifest that was written
+type Result struct {
+	Key       string
+	VersionID string
+	Manifest  *Manifest
+}
+
+func (w *Writer) prefix() string {
+	if w.Prefix == "" {
+		return DefaultPrefix
+	}
+	return w.Prefix
+}
+
+// Write creates the next manifest in the chain. Windows without new versions still get an
+// (empty) manifest, which proves nothing was written rather than that the writer stopped
+func (w *Writer) Write(ctx context.Context) (*Result, error) {
+	now := time.Now
+	if w.Now != nil {
+		now = w.Now
+	}
+
+	previous, err := w.latest(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{
+		Version:   FormatVersion,
+		Store:     w.Store.URI(),
+		Sequence:  1,
+		CreatedAt: now().UTC(),
+		Window:    Window{To: now().Add(-w.SettleDelay).UTC().Truncate(time.Second)},
+	}
+	if previous != nil {
+		m.Sequence = previous.manifest.Sequence + 1
+		m.Previous = &previous.link
+		m.Window.From = previous.manifest.Window.To
+		if !m.Window.To.After(m.Window.From) {
+			return nil, ErrNothingToSeal
+		}
+	}
+
+	var versions []Version
+	err = w.Store.ListVersions(ctx, "", func(v Version) error {
+		if !strings.HasPrefix(v.Key, w.prefix()) && m.Window.Contains(v.LastModified) {
+			versions = append(versions, v)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Records, err = w.digest(ctx, versions); err != nil {
+		return nil, err
+	}
+	SortRecords(m.Records)
+	m.RecordCount = len(m.Records)
+	m.MerkleRoot = m.ComputeRoot()
+
+	envelope, err := Seal(ctx, m, w.Signer)
+	if err != nil {
+		return nil, err
+	}
+	key := ObjectKey(w.prefix(), m.Sequence, m.Window.To)
+	versionID, err := w.Store.Put(ctx, key, envelope, "application/json")
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Key: key, VersionID: versionID, Manifest: m}, nil
+}
+
+// SortRecords orders records by last modified time, then key and version ID
+func SortRecords(records []Record) {
+	sort.Slice(records, func(i, j int) bool { return recordLess(records[i], records[j]) })
+}
+
+type stored struct {
+	manifest *Manifest
+	link     Link
+}
+
+// latest reads the manifest with the highest sequence number, or returns nil before the first one
+func (w *Writer) latest(ctx context.Context) (*stored, error) {
+	var head *Version
+	var headSequence uint64
+	err := w.Store.ListVersions(ctx, w.prefix(), func(v Version) error {
+		sequence, ok := ParseObjectKey(w.prefix(), v.Key)
+		if !ok || v.DeleteMarker || !v.IsLatest {
+			return nil
+		}
+		if head == nil || sequence > headSequence {
+			version := v
+			head, headSequence = &version, sequence
+		}
+		return nil
+	})
+	if err != nil || head == nil {
+		return nil, err
+	}
+
+	data, err := readAll(ctx, w.Store, head.Key, head.VersionID)
+	if err != nil {
+		return nil, err
+	}
+	_, m, _, err := Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", head.Key, err)
+	}
+	if m.Sequence != headSequence {
+		return nil, fmt.Errorf("%s: manifest records sequence %d", head.Key, m.Sequence)
+	}
+
+	return &stored{
+		manifest: m,
+		link:     Link{Key: head.Key, VersionID: head.VersionID, SHA256: Digest(data), MerkleRoot: m.MerkleRoot},
+	}, nil
+}
+
+// digest hashes object versions with up to Parallelism requests in flight
+func (w *Writer) digest(ctx context.Context, versions []Version) ([]Record, error) {
+	records := make([]Record, len(versions))
+	errs := make([]error, len(versions))
+	parallel(len(versions), w.Parallelism, func(index int) {
+		records[index], errs[index] = DigestVersion(ctx, w.Store, versions[index])
+	})
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return records, nil
+}
+
+// parallel calls fn for 0..n-1 from up to workers goroutines (at least one)
+func parallel(n, workers int, fn func(index int)) {
+	if workers < 1 {
+		workers = 1
+	}
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for index := range jobs {
+				fn(index)
+			}
+		}()
+	}
+	for index := 0; index < n; index++ {
+		jobs <- index
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// DigestVersion reads one version and returns its record; delete markers have no content
+func DigestVersion(ctx context.Context, store Store, v Version) (Record, error) {
+	record := Record{Key: v.Key, VersionID: v.VersionID, LastModified: v.LastModified.UTC(), Size: v.Size, DeleteMarker: v.DeleteMarker}
+	if v.DeleteMarker {
+		return record, nil
+	}
+
+	body, err := store.Open(ctx, v.Key, v.VersionID)
+	if err != nil {
+		return record, err
+	}
+	defer body.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, body); err != nil {
+		return record, fmt.Errorf("reading %s version %s: %w", v.Key, v.VersionID, err)
+	}
+	record.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	return record, nil
+}
+
+func readAll(ctx context.Context, store Store, key, versionID string) ([]byte, error) {
+	body, err := store.Open(ctx, key, versionID)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+	return io.ReadAll(body)
+}
@@ -0,0 +1,210 @@
+package manifest
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/signing"
+)
+
+var epoch = time.Date(2026, 1, 15, 9, 0, 0, 0, time.UTC)
+
+type memObject struct {
+	Version
+	body []byte
+}
+
+// memStore is an in-memory versioned bucket; Put stamps objects with the store clock
+type memStore struct {
+	mu      sync.Mutex
+	objects []*memObject
+	now     time.Time
+	next    int
+}
+
+func (s *memStore) URI() string { return "mem://audit" }
+
+func (s *memStore) add(key string, body []byte, modified time.Time) Version {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, object := range s.objects {
+		if object.Key == key {
+			object.IsLatest = false
+		}
+	}
+	s.next++
+	object := &memObject{
+		Version: Version{Key: key, VersionID: fmt.Sprintf("v%d", s.next), LastModified: modified, Size: int64(len(body)), IsLatest: true},
+		body:    body,
+	}
+	s.objects = append(s.objects, object)
+	return object.Version
+}
+
+func (s *memStore) deleteMarker(key string, modified time.Time) {
+	s.add(key, nil, modified)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[len(s.objects)-1].DeleteMarker = true
+}
+
+func (s *memStore) ListVersions(_ context.Context, prefix string, fn func(Version) error) error {
+	s.mu.Lock()
+	var versions []Version
+	for _, object := range s.objects {
+		if len(object.Key) >= len(prefix) && object.Key[:len(prefix)] == prefix {
+			versions = append(versions, object.Version)
+		}
+	}
+	s.mu.Unlock()
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Key < versions[j].Key })
+	for _, version := range versions {
+		if err := fn(version); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *memStore) Open(_ context.Context, key, versionID string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, object := range s.objects {
+		if object.Key == key && object.VersionID == versionID && !object.DeleteMarker {
+			return io.NopCloser(bytes.NewReader(object.body)), nil
+		}
+	}
+	return nil, fmt.Errorf("%s version %s not found", key, versionID)
+}
+
+func (s *memStore) Put(_ context.Context, key string, body []byte, _ string) (string, error) {
+	return s.add(key, body, s.now).VersionID, nil
+}
+
+func (s *memStore) body(key string) []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, object := range s.objects {
+		if object.Key == key {
+			return object.body
+		}
+	}
+	return nil
+}
+
+func sha(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return hex.EncodeToString(sum[:])
+}
+
+func newWriter(t *testing.T, store *memStore) (*Writer, ed25519.PublicKey) {
+	t.Helper()
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	return &Writer{
+		Store:       store,
+		Signer:      signing.Ed25519Signer{Key: private},
+		SettleDelay: 5 * time.Minute,
+		Now:         func() time.Time { return store.now },
+	}, public
+}
+
+func TestWriterChainsManifests(t *testing.T) {
+	ctx := context.Background()
+	store := &memStore{}
+	writer, _ := newWriter(t, store)
+
+	store.add("logs/b.json", []byte("b"), epoch.Add(2*time.Minute))
+	store.add("logs/a.json", []byte("a"), epoch.Add(1*time.Minute))
+	store.deleteMarker("logs/c.json", epoch.Add(3*time.Minute))
+	store.add("logs/late.json", []byte("late"), epoch.Add(58*time.Minute))
+
+	store.now = epoch.Add(time.Hour)
+	first, err := writer.Write(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, "_manifests/00000000000000000001-20260115T095500Z.json", first.Key)
+	assert.Equal(t, uint64(1), first.Manifest.Sequence)
+	assert.Nil(t, first.Manifest.Previous)
+	assert.Equal(t, epoch.Add(55*time.Minute), first.Manifest.Window.To, "The settle delay holds back recent uploads")
+	require.Len(t, first.Manifest.Records, 3)
+	assert.Equal(t, "logs/a.json", first.Manifest.Records[0].Key, "Records are ordered by last modified time")
+	assert.Equal(t, sha("a"), first.Manifest.Records[0].SHA256)
+	assert.True(t, first.Manifest.Records[2].DeleteMarker)
+	assert.Empty(t, first.Manifest.Records[2].SHA256)
+	assert.Equal(t, first.Manifest.ComputeRoot(), first.Manifest.MerkleRoot)
+
+	store.add("logs/d.json", []byte("d"), epoch.Add(70*time.Minute))
+	store.now = epoch.Add(2 * time.Hour)
+	second, err := writer.Write(ctx)
+	require.NoError(t, err)
+
+	assert.Equal(t, uint64(2), second.Manifest.Sequence)
+	require.NotNil(t, second.Manifest.Previous)
+	assert.Equal(t, first.Key, second.Manifest.Previous.Key)
+	assert.Equal(t, first.VersionID, second.Manifest.Previous.VersionID)
+	assert.Equal(t, Digest(store.body(first.Key)), second.Manifest.Previous.SHA256)
+	assert.Equal(t, first.Manifest.MerkleRoot, second.Manifest.Previous.MerkleRoot)
+	assert.Equal(t, first.Manifest.Window.To, second.Manifest.Window.From)
+
+	var keys []string
+	for _, record := range second.Manifest.Records {
+		keys = append(keys, record.Key)
+	}
+	assert.Equal(t, []string{"logs/late.json", "logs/d.json"}, keys, "Manifests never cover themselves")
+
+	_, err = writer.Write(ctx)
+	assert.ErrorIs(t, err, ErrNothingToSeal)
+}
+
+func TestWriterWritesEmptyManifests(t *testing.T) {
+	store := &memStore{now: epoch}
+	writer, _ := newWriter(t, store)
+
+	result, err := writer.Write(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, result.Manifest.RecordCount)
+	assert.Equal(t, MerkleRoot(nil), result.Manifest.MerkleRoot)
+}
+
+func TestWriterDigestsInParallel(t *testing.T) {
+	store := &memStore{now: epoch.Add(time.Hour)}
+	writer, _ := newWriter(t, store)
+	writer.Parallelism = 8
+
+	for i := 0; i < 50; i++ {
+		store.add(fmt.Sprintf("logs/%02d.json", i), []byte(fmt.Sprint(i)), epoch.Add(time.Duration(i)*time.Second))
+	}
+
+	result, err := writer.Write(context.Background())
+	require.NoError(t, err)
+	require.Len(t, result.Manifest.Records, 50)
+	for i, record := range result.Manifest.Records {
+		assert.Equal(t, sha(fmt.Sprint(i)), record.SHA256, record.Key)
+	}
+}
+
+func TestWriterRejectsForeignManifest(t *testing.T) {
+	store := &memStore{now: epoch}
+	writer, _ := newWriter(t, store)
+
+	store.add("_manifests/00000000000000000007-20260115T000000Z.json", []byte(`{"payload_type": "text/plain"}`), epoch)
+	_, err := writer.Write(context.Background())
+	assert.ErrorContains(t, err, "unexpected payload type")
+}
@@ -0,0 +1,253 @@
+// Package policy evaluates audit-specific rules against `terraform show -json` plans that use the
+// AuditLedger storage modules. Generic scanners check each resource in isolation; these rules
+// know what the modules are for, e.g. that GOVERNANCE mode is acceptable in a sandbox but not
+// for production audit logs
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/tfstate"
+)
+
+// Severity ranks how unsafe a finding is
+type Severity string
+
+const (
+	Low    Severity = "low"
+	Medium Severity = "medium"
+	High   Severity = "high"
+)
+
+var severityRank = map[Severity]int{Low: 1, Medium: 2, High: 3}
+
+// ParseSeverity accepts low, medium or high
+func ParseSeverity(value string) (Severity, error) {
+	severity := Severity(strings.ToLower(value))
+	if _, ok := severityRank[severity]; !ok {
+		return "", fmt.Errorf("unknown severity %q (use low, medium or high)", value)
+	}
+	return severity, nil
+}
+
+// AtLeast reports whether s is as severe as threshold
+func (s Severity) AtLeast(threshold Severity) bool {
+	return severityRank[s] >= severityRank[threshold]
+}
+
+// Status is the outcome of one rule for one module instance
+type Status string
+
+const (
+	Pass   Status = "PASS"
+	Fail   Status = "FAIL"
+	Waived Status = "WAIVED"
+)
+
+// SkipTag is the bucket or storage account tag that waives rules for one module instance,
+// e.g. AuditLedgerPolicySkip = "AL004" - the waiver stays in code review next to the module call
+const SkipTag = "AuditLedgerPolicySkip"
+
+// Config holds the organisation's expectations that a plan cannot express on its own
+type Config struct {
+	// Profile is a compliance profile every module instance must meet, even if its module call
+	// sets a weaker compliance_profile or none ("" to rely on each instance's Compliance tag)
+	Profile string
+	// EnvironmentTag is the tag that names the environment, e.g. Environment
+	EnvironmentTag string
+	// ProductionValues are the EnvironmentTag values treated as production (case-insensitive)
+	ProductionValues []string
+	// Skip lists rule IDs that are not evaluated at all
+	Skip []string
+}
+
+// DefaultConfig returns the configuration used by the CLI when no flags are given
+func DefaultConfig() Config {
+	return Config{
+		EnvironmentTag:   "Environment",
+		ProductionValues: []string{"prod", "production"},
+	}
+}
+
+// Finding is one unsafe setting on one resource
+type Finding struct {
+	Address string `json:"address"`
+	Message string `json:"message"`
+}
+
+// Result is the outcome of one rule for one module instance
+type Result struct {
+	Rule        string    `json:"rule"`
+	Name        string    `json:"name"`
+	Severity    Severity  `json:"severity"`
+	Target      string    `json:"target"`
+	Module      string    `json:"module"`
+	Status      Status    `json:"status"`
+	Findings    []Finding `json:"findings,omitempty"`
+	Remediation string    `json:"remediation,omitempty"`
+}
+
+// Evaluation is the result of every applicable rule for every module instance in a plan
+type Evaluation struct {
+	Plan    string   `json:"plan"`
+	Targets int      `json:"targets"`
+	Results []Result `json:"results"`
+}
+
+// Violations returns the failed results at or above the threshold
+func (e *Evaluation) Violations(threshold Severity) []Result {
+	var violations []Result
+	for _, result := range e.Results {
+		if result.Status == Fail && result.Severity.AtLeast(threshold) {
+			violations = append(violations, result)
+		}
+	}
+	return violations
+}
+
+// Counts returns the number of passed, failed and waived results
+func (e *Evaluation) Counts() (passed, failed, waived int) {
+	for _, result := range e.Results {
+		switch result.Status {
+		case Pass:
+			passed++
+		case Fail:
+			failed++
+		case Waived:
+			waived++
+		}
+	}
+	return passed, failed, waived
+}
+
+// target is one instance of auditledger-s3 or auditledger-azure-blob
+type target struct {
+	Provider  string
+	Name      string
+	Module    string
+	Primary   tfstate.Resource
+	Resources []tfstate.Resource
+}
+
+// find returns the module's resources of a type and name (any count/for_each index)
+func (t *target) find(resourceType, name string) []tfstate.Resource {
+	return tfstate.Find(t.Resources, resourceType, name)
+}
+
+// tags returns the effective tags of the primary resource, including provider default tags
+func (t *target) tags() map[string]string {
+	raw, ok := t.Primary.Values["tags_all"].(map[string]interface{})
+	if !ok || len(raw) == 0 {
+		raw, _ = t.Primary.Values["tags"].(map[string]interface{})
+	}
+	tags := map[string]string{}
+	for key, value := range raw {
+		if s, ok := value.(string); ok {
+			tags[key] = s
+		}
+	}
+	return tags
+}
+
+// primaryTypes identifies module instances by their primary resource
+var primaryTypes = []struct {
+	Provider string
+	Type     string
+	NameAttr string
+}{
+	{"aws", "aws_s3_bucket", "bucket"},
+	{"azure", "azurerm_storage_account", "name"},
+}
+
+func findTargets(show *tfstate.Show) []*target {
+	byModule := show.ByModule()
+
+	var targets []*target
+	for module, resources := range byModule {
+		for _, primary := range primaryTypes {
+			t := &target{Provider: primary.Provider, Module: module, Resources: resources}
+			found := t.find(primary.Type, "audit_logs")
+			if len(found) == 0 {
+				continue
+			}
+			t.Primary = found[0]
+			t.Name = found[0].String(primary.NameAttr)
+			if t.Name == "" {
+				t.Name = found[0].Address
+			}
+			targets = append(targets, t)
+		}
+	}
+
+	sort.Slice(targets, func(i, j int) bool { return targets[i].Module < targets[j].Module })
+	return targets
+}
+
+// Evaluate runs every rule against every AuditLedger module instance in the plan
+func Evaluate(show *tfstate.Show, config Config) (*Evaluation, error) {
+	if config.Profile != "" {
+		if _, ok := profiles[config.Profile]; !ok {
+			return nil, fmt.Errorf("unknown compliance profile %q (known: %s)", config.Profile, strings.Join(ProfileNames(), ", "))
+		}
+	}
+	skip := map[string]bool{}
+	for _, id := range config.Skip {
+		if RuleByID(id) == nil {
+			return nil, fmt.Errorf("unknown rule %q", id)
+		}
+		skip[strings.ToUpper(id)] = true
+	}
+
+	targets := findTargets(show)
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("no auditledger-s3 or auditledger-azure-blob resources found in the plan")
+	}
+
+	evaluation := &Evaluation{Targets: len(targets), Results: []Result{}}
+	for _, t := range targets {
+		waived := map[string]bool{}
+		for _, id := range splitIDs(t.tags()[SkipTag]) {
+			waived[id] = true
+		}
+
+		for _, rule := range Rules {
+			if skip[rule.ID] || !rule.appliesTo(t.Provider) {
+				continue
+			}
+
+			result := Result{
+				Rule:     rule.ID,
+				Name:     rule.Name,
+				Severity: rule.Severity,
+				Target:   t.Name,
+				Module:   t.Module,
+				Status:   Pass,
+				Findings: rule.check(t, config),
+			}
+			if len(result.Findings) > 0 {
+				result.Status = Fail
+				result.Remediation = rule.Remediation
+				if waived[rule.ID] {
+					result.Status = Waived
+				}
+			}
+			evaluation.Results = append(evaluation.Results, result)
+		}
+	}
+	return evaluation, nil
+}
+
+// splitIDs parses a waiver tag. S3 tag values cannot contain commas, so spaces, colons and
+// slashes also separate rule IDs
+func splitIDs(value string) []string {
+	fields := strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == ':' || r == '/' || r == '+'
+	})
+	ids := make([]string, 0, len(fields))
+	for _, field := range fields {
+		ids = append(ids, strings.ToUpper(field))
+	}
+	return ids
+}
@@ -0,0 +1,231 @@
+package policy
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/tfstate"
+)
+
+func loadPlan(t *testing.T) *tfstate.Show {
+	t.Helper()
+
+	data, err := os.ReadFile("testdata/plan.json")
+	require.NoError(t, err)
+	show, err := tfstate.Parse(data)
+	require.NoError(t, err)
+	return show
+}
+
+// outcomes maps "module rule" to the result status
+func outcomes(evaluation *Evaluation) map[string]Status {
+	statuses := map[string]Status{}
+	for _, result := range evaluation.Results {
+		statuses[result.Module+" "+result.Rule] = result.Status
+	}
+	return statuses
+}
+
+func TestEvaluateFixture(t *testing.T) {
+	evaluation, err := Evaluate(loadPlan(t), DefaultConfig())
+	require.NoError(t, err)
+	assert.Equal(t, 3, evaluation.Targets)
+
+	tests := map[string]Status{
+		"module.prod_logs AL001":  Fail,
+		"module.prod_logs AL002":  Pass,
+		"module.prod_logs AL005":  Fail,
+		"module.prod_logs AL006":  Fail,
+		"module.dev_logs AL001":   Pass,
+		"module.dev_logs AL005":   Pass,
+		"module.dev_logs AL006":   Pass,
+		"module.azure_logs AL002": Fail,
+		"module.azure_logs AL003": Fail,
+		"module.azure_logs AL004": Waived,
+		"module.azure_logs AL005": Pass,
+		"module.azure_logs AL007": Fail,
+	}
+	statuses := outcomes(evaluation)
+	for key, expected := range tests {
+		assert.Equal(t, expected, statuses[key], key)
+	}
+	assert.NotContains(t, statuses, "module.prod_logs AL003", "Azure rules do not apply to S3 buckets")
+}
+
+func TestRetentionUsesStricterProfile(t *testing.T) {
+	config := DefaultConfig()
+	config.Profile = "hipaa"
+
+	evaluation, err := Evaluate(loadPlan(t), config)
+	require.NoError(t, err)
+
+	statuses := outcomes(evaluation)
+	assert.Equal(t, Fail, statuses["module.prod_logs AL002"])
+	assert.Equal(t, Pass, statuses["module.dev_logs AL002"], "2555 days meets the HIPAA minimum")
+
+	for _, result := range evaluation.Results {
+		if result.Module == "module.prod_logs" && result.Rule == "AL002" {
+			assert.Equal(t, "retention is 365 days, below the 2190-day minimum of the hipaa profile", result.Findings[0].Message)
+		}
+	}
+}
+
+func TestProfileRequiresComplianceModeOutsideProduction(t *testing.T) {
+	config := DefaultConfig()
+	config.ProductionValues = nil
+
+	evaluation, err := Evaluate(loadPlan(t), config)
+	require.NoError(t, err)
+	statuses := outcomes(evaluation)
+	assert.Equal(t, Pass, statuses["module.prod_logs AL001"], "No profile and no production tag")
+	assert.Equal(t, Fail, statuses["module.azure_logs AL007"], "The Compliance tag claims HIPAA")
+
+	config.Profile = "soc2"
+	evaluation, err = Evaluate(loadPlan(t), config)
+	require.NoError(t, err)
+	for _, result := range evaluation.Results {
+		if result.Module == "module.prod_logs" && result.Rule == "AL001" {
+			require.Equal(t, Fail, result.Status)
+			assert.Contains(t, result.Findings[0].Message, "the soc2 profile requires COMPLIANCE")
+		}
+	}
+}
+
+func TestSkipAndThreshold(t *testing.T) {
+	config := DefaultConfig()
+	config.Skip = []string{"al005"}
+
+	evaluation, err := Evaluate(loadPlan(t), config)
+	require.NoError(t, err)
+	assert.NotContains(t, outcomes(evaluation), "module.prod_logs AL005")
+	assert.Len(t, evaluation.Violations(High), 5)
+	assert.Len(t, evaluation.Violations(Low), 5)
+
+	config.Skip = []string{"AL999"}
+	_, err = Evaluate(loadPlan(t), config)
+	assert.ErrorContains(t, err, `unknown rule "AL999"`)
+
+	config = DefaultConfig()
+	config.Profile = "iso27001"
+	_, err = Evaluate(loadPlan(t), config)
+	assert.ErrorContains(t, err, "unknown compliance profile")
+}
+
+func TestSplitIDs(t *testing.T) {
+	assert.Equal(t, []string{"AL004", "AL005"}, splitIDs("al004 AL005"))
+	assert.Equal(t, []string{"AL004", "AL005"}, splitIDs("AL004,AL005"))
+	assert.Empty(t, splitIDs(""))
+}
+
+func TestEvaluateWithoutModules(t *testing.T) {
+	show, err := tfstate.Parse([]byte(`{"format_version": "1.2", "planned_values": {"root_module": {}}}`))
+	require.NoError(t, err)
+
+	_, err = Evaluate(show, DefaultConfig())
+	assert.Error(t, err)
+}
+
+func TestWriteFormats(t *testing.T) {
+	evaluation, err := Evaluate(loadPlan(t), DefaultConfig())
+	require.NoError(t, err)
+	evaluation.Plan = "plan.json"
+
+	var text bytes.Buffer
+	require.NoError(t, evaluation.Write(&text, "text", High))
+	assert.Contains(t, text.String(), "[WARN] AL005 missing_access_logging (medium) acme-audit-logs-prod")
+	assert.Contains(t, text.String(), "[WAIVED] AL004 shared_key_access (high) acmeauditlogs")
+	assert.Contains(t, text.String(), "FAILED: 5 violations at or above high severity, 6 passed, 1 waived")
+
+	var out bytes.Buffer
+	require.NoError(t, evaluation.Write(&out, "json", Medium))
+	var decoded struct {
+		Passed  bool           `json:"passed"`
+		Summary map[string]int `json:"summary"`
+		Results []Result       `json:"results"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.False(t, decoded.Passed)
+	assert.Equal(t, 6, decoded.Summary["violations"])
+	assert.Len(t, decoded.Results, 13)
+
+	out.Reset()
+	require.NoError(t, evaluation.Write(&out, "junit", High))
+	assert.Contains(t, out.String(), `<testsuite name="auditledger-policy" tests="13" failures="5" skipped="1">`)
+	assert.Contains(t, out.String(), `<failure message="rule replicate-all does not replicate SSE-KMS encrypted objects" type="high">`)
+
+	assert.Error(t, evaluation.Write(&out, "sarif", High))
+}
+
+// profileLocals reads the profiles and no_profile objects from the locals of the compliance
+// profile module. It only understands the flat objects of string, number and bool attributes
+// that the module uses
+func profileLocals(t *testing.T) map[string]map[string]string {
+	t.Helper()
+
+	file, err := os.Open("../../../modules/auditledger-compliance-profile/main.tf")
+	require.NoError(t, err)
+	defer file.Close()
+
+	open := regexp.MustCompile(`^(\w+)\s*(?:=\s*)?\{$`)
+	attribute := regexp.MustCompile(`^(\w+)\s*=\s*(.+)$`)
+
+	objects := map[string]map[string]string{}
+	var path []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case line == "}":
+			path = path[:len(path)-1]
+		case open.MatchString(line):
+			path = append(path, open.FindStringSubmatch(line)[1])
+		case strings.HasSuffix(line, "{"):
+			path = append(path, "")
+		case attribute.MatchString(line) && len(path) > 0:
+			name := path[len(path)-1]
+			parent := strings.Join(path[:len(path)-1], ".")
+			if parent == "locals.profiles" || (parent == "locals" && name == "no_profile") {
+				if objects[name] == nil {
+					objects[name] = map[string]string{}
+				}
+				match := attribute.FindStringSubmatch(line)
+				objects[name][match[1]] = strings.Trim(match[2], `"`)
+			}
+		}
+	}
+	require.NoError(t, scanner.Err())
+	return objects
+}
+
+func TestProfilesMatchComplianceProfileModule(t *testing.T) {
+	locals := profileLocals(t)
+	require.Contains(t, locals, "no_profile")
+	assert.Equal(t, strconv.Itoa(moduleFloor), locals["no_profile"]["minimum_retention_days"], "moduleFloor is the minimum without a profile")
+	delete(locals, "no_profile")
+
+	names := make([]string, 0, len(locals))
+	for name := range locals {
+		names = append(names, name)
+	}
+	assert.ElementsMatch(t, ProfileNames(), names)
+
+	for name, module := range locals {
+		minimum, err := strconv.Atoi(module["minimum_retention_days"])
+		require.NoError(t, err, name)
+		assert.Equal(t, profile{
+			Tag:                   module["tag"],
+			MinimumRetentionDays:  minimum,
+			RequireComplianceMode: module["require_compliance_mode"] == "true",
+		}, profiles[name], name)
+	}
+}
@@ -0,0 +1,163 @@
+package policy
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Formats lists the supported output formats
+var Formats = []string{"text", "json", "junit"}
+
+// Write renders the evaluation in the given format. Failed results below the threshold are
+// reported as warnings and do not count as violations
+func (e *Evaluation) Write(w io.Writer, format string, threshold Severity) error {
+	switch format {
+	case "text":
+		return e.WriteText(w, threshold)
+	case "json":
+		return e.WriteJSON(w, threshold)
+	case "junit":
+		return e.WriteJUnit(w, threshold)
+	default:
+		return fmt.Errorf("unsupported format %q (use text, json or junit)", format)
+	}
+}
+
+func (r Result) label(threshold Severity) string {
+	if r.Status == Fail && !r.Severity.AtLeast(threshold) {
+		return "WARN"
+	}
+	return string(r.Status)
+}
+
+// WriteText renders the failed and waived results; passing results are only counted
+func (e *Evaluation) WriteText(w io.Writer, threshold Severity) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "auditledger-policy: %s (%d module instances, %d rule evaluations)\n\n", e.Plan, e.Targets, len(e.Results))
+	for _, result := range e.Results {
+		if result.Status == Pass {
+			continue
+		}
+		fmt.Fprintf(&b, "[%s] %s %s (%s) %s\n", result.label(threshold), result.Rule, result.Name, result.Severity, result.Target)
+		if result.Status == Waived {
+			fmt.Fprintf(&b, "       waived by the %s tag\n", SkipTag)
+			continue
+		}
+		for _, finding := range result.Findings {
+			fmt.Fprintf(&b, "       %s: %s\n", finding.Address, finding.Message)
+		}
+		fmt.Fprintf(&b, "       fix: %s\n", result.Remediation)
+	}
+
+	passed, _, waived := e.Counts()
+	violations := len(e.Violations(threshold))
+	outcome := "PASSED"
+	if violations > 0 {
+		outcome = "FAILED"
+	}
+	fmt.Fprintf(&b, "\n%s: %d violations at or above %s severity, %d passed, %d waived\n", outcome, violations, threshold, passed, waived)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON renders every result with a summary for machine consumption
+func (e *Evaluation) WriteJSON(w io.Writer, threshold Severity) error {
+	passed, failed, waived := e.Counts()
+	violations := len(e.Violations(threshold))
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		*Evaluation
+		FailOn  Severity       `json:"fail_on"`
+		Passed  bool           `json:"passed"`
+		Summary map[string]int `json:"summary"`
+	}{
+		Evaluation: e,
+		FailOn:     threshold,
+		Passed:     violations == 0,
+		Summary: map[string]int{
+			"passed":     passed,
+			"failed":     failed,
+			"waived":     waived,
+			"violations": violations,
+		},
+	})
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Body    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit renders one test case per rule and module instance so CI systems can display
+// violations next to unit test results
+func (e *Evaluation) WriteJUnit(w io.Writer, threshold Severity) error {
+	suite := junitTestSuite{Name: "auditledger-policy", Tests: len(e.Results)}
+
+	for _, result := range e.Results {
+		testCase := junitTestCase{Name: fmt.Sprintf("%s %s", result.Rule, result.Name), ClassName: result.Module}
+
+		var details []string
+		for _, finding := range result.Findings {
+			details = append(details, fmt.Sprintf("%s: %s", finding.Address, finding.Message))
+		}
+		if result.Remediation != "" {
+			details = append(details, "fix: "+result.Remediation)
+		}
+
+		switch {
+		case result.Status == Waived:
+			suite.Skipped++
+			testCase.Skipped = &junitSkipped{Message: fmt.Sprintf("waived by the %s tag", SkipTag)}
+		case result.Status == Fail && result.Severity.AtLeast(threshold):
+			suite.Failures++
+			testCase.Failure = &junitFailure{Message: result.Findings[0].Message, Type: string(result.Severity), Body: strings.Join(details, "\n")}
+		case result.Status == Fail:
+			testCase.SystemOut = fmt.Sprintf("warning (%s severity, below -fail-on %s)\n%s", result.Severity, threshold, strings.Join(details, "\n"))
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
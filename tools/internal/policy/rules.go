@@ -0,0 +1,328 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/tfstate"
+)
+
+// profile is the part of a compliance profile that the rules enforce
+type profile struct {
+	Tag                   string
+	MinimumRetentionDays  int
+	RequireComplianceMode bool // COMPLIANCE Object Lock mode on S3, a locked immutability policy on Azure
+}
+
+// profiles mirrors locals.profiles in modules/auditledger-compliance-profile;
+// TestProfilesMatchComplianceProfileModule fails when they drift apart
+var profiles = map[string]profile{
+	"soc2":         {"SOC2", 365, true},
+	"hipaa":        {"HIPAA", 2190, true},
+	"pci_dss":      {"PCI-DSS", 365, true},
+	"sox":          {"SOX", 2555, true},
+	"gdpr_minimal": {"GDPR", 365, false},
+	"finra_17a4":   {"FINRA-17a-4", 2190, true},
+}
+
+// moduleFloor is the retention minimum the modules enforce without a profile
+const moduleFloor = 365
+
+// ProfileNames returns the known compliance profiles in sorted order
+func ProfileNames() []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// requirement is the strictest of the instance's own profile (from its Compliance tag) and the
+// profile required by Config
+type requirement struct {
+	MinimumRetentionDays int
+	Source               string // profile the minimum comes from, "" for the module floor
+	ComplianceModeSource string // profile that requires COMPLIANCE mode, "" if none does
+}
+
+func requirements(t *target, config Config) requirement {
+	req := requirement{MinimumRetentionDays: moduleFloor}
+
+	var names []string
+	tag := t.tags()["Compliance"]
+	for _, name := range ProfileNames() {
+		if tag != "" && strings.EqualFold(profiles[name].Tag, tag) {
+			names = append(names, name)
+		}
+	}
+	if config.Profile != "" {
+		names = append(names, config.Profile)
+	}
+
+	for _, name := range names {
+		p := profiles[name]
+		if p.MinimumRetentionDays > req.MinimumRetentionDays || (p.MinimumRetentionDays == req.MinimumRetentionDays && req.Source == "") {
+			req.MinimumRetentionDays = p.MinimumRetentionDays
+			req.Source = name
+		}
+		if p.RequireComplianceMode && req.ComplianceModeSource == "" {
+			req.ComplianceModeSource = name
+		}
+	}
+	return req
+}
+
+// production reports whether the instance is tagged as a production environment
+func production(t *target, config Config) (string, bool) {
+	if config.EnvironmentTag == "" {
+		return "", false
+	}
+	value := t.tags()[config.EnvironmentTag]
+	for _, candidate := range config.ProductionValues {
+		if value != "" && strings.EqualFold(value, candidate) {
+			return fmt.Sprintf("%s=%s", config.EnvironmentTag, value), true
+		}
+	}
+	return "", false
+}
+
+// Rule is one audit-specific check of a module instance
+type Rule struct {
+	ID          string   `json:"id"`
+	Name        string   `json:"name"`
+	Severity    Severity `json:"severity"`
+	Providers   []string `json:"providers"`
+	Description string   `json:"description"`
+	Remediation string   `json:"remediation"`
+	check       func(t *target, config Config) []Finding
+}
+
+func (r Rule) appliesTo(provider string) bool {
+	for _, p := range r.Providers {
+		if p == provider {
+			return true
+		}
+	}
+	return false
+}
+
+// RuleByID returns the rule with the given ID (case-insensitive), or nil
+func RuleByID(id string) *Rule {
+	for i := range Rules {
+		if strings.EqualFold(Rules[i].ID, id) {
+			return &Rules[i]
+		}
+	}
+	return nil
+}
+
+// Rules is the rule pack, in ID order
+var Rules = []Rule{
+	{
+		ID:          "AL001",
+		Name:        "governance_mode_in_production",
+		Severity:    High,
+		Providers:   []string{"aws"},
+		Description: "Object Lock uses GOVERNANCE mode for production audit logs, or where the compliance profile requires COMPLIANCE mode. Any principal with s3:BypassGovernanceRetention can delete locked versions.",
+		Remediation: `Set object_lock_mode = "COMPLIANCE", or set compliance_profile so the module enforces it`,
+		check:       checkGovernanceMode,
+	},
+	{
+		ID:          "AL002",
+		Name:        "retention_below_profile",
+		Severity:    High,
+		Providers:   []string{"aws", "azure"},
+		Description: "Default retention is shorter than the minimum of the compliance profile claimed by the Compliance tag or required by -profile.",
+		Remediation: "Raise retention_days, or set compliance_profile so the module applies the profile default",
+		check:       checkRetention,
+	},
+	{
+		ID:          "AL003",
+		Name:        "network_default_allow",
+		Severity:    High,
+		Providers:   []string{"azure"},
+		Description: "The storage account accepts traffic from any network.",
+		Remediation: `Set network_default_action = "Deny" and list callers in allowed_ip_ranges or allowed_subnet_ids, or use a private endpoint`,
+		check:       checkNetworkDefaultAction,
+	},
+	{
+		ID:          "AL004",
+		Name:        "shared_key_access",
+		Severity:    High,
+		Providers:   []string{"azure"},
+		Description: "Shared key access is enabled. Account keys and SAS tokens signed with them bypass Azure AD, so writes and deletes cannot be attributed to an identity.",
+		Remediation: "Set enable_shared_key_access = false and grant the AuditLedger roles to managed identities",
+		check:       checkSharedKeyAccess,
+	},
+	{
+		ID:          "AL005",
+		Name:        "missing_access_logging",
+		Severity:    Medium,
+		Providers:   []string{"aws", "azure"},
+		Description: "Reads and writes of the audit logs are not logged, so access to the evidence itself cannot be audited.",
+		Remediation: "Set access_log_bucket (S3), or log_analytics_workspace_id, diagnostic_storage_account_id or an Event Hub (Azure)",
+		check:       checkAccessLogging,
+	},
+	{
+		ID:          "AL006",
+		Name:        "replication_without_replica_kms_key",
+		Severity:    High,
+		Providers:   []string{"aws"},
+		Description: "The bucket is encrypted with SSE-KMS and replicated, but replication does not select SSE-KMS objects or name a replica key. S3 skips those objects, so the disaster recovery copy has no audit logs.",
+		Remediation: "Set replication_kms_key_id to a KMS key in the destination region and allow the replication role to use both keys",
+		check:       checkReplicationKMS,
+	},
+	{
+		ID:          "AL007",
+		Name:        "unlocked_immutability_in_production",
+		Severity:    High,
+		Providers:   []string{"azure"},
+		Description: "The container immutability policy is unlocked for production audit logs, or where the compliance profile requires a locked policy. Unlocked policies can be shortened or deleted.",
+		Remediation: "Set lock_immutability_policy = true once the retention period is final (locking is irreversible)",
+		check:       checkImmutabilityLocked,
+	},
+}
+
+func checkGovernanceMode(t *target, config Config) []Finding {
+	env, isProduction := production(t, config)
+	req := requirements(t, config)
+	if !isProduction && req.ComplianceModeSource == "" {
+		return nil
+	}
+
+	var findings []Finding
+	for _, lock := range t.find("aws_s3_bucket_object_lock_configuration", "audit_logs") {
+		mode, _ := tfstate.Lookup(lock.Values, "rule", 0, "default_retention", 0, "mode").(string)
+		if mode != "GOVERNANCE" {
+			continue
+		}
+		reason := fmt.Sprintf("the %s profile requires COMPLIANCE", req.ComplianceModeSource)
+		if isProduction {
+			reason = fmt.Sprintf("the bucket is tagged %s", env)
+		}
+		findings = append(findings, Finding{lock.Address, fmt.Sprintf("Object Lock mode is GOVERNANCE but %s", reason)})
+	}
+	return findings
+}
+
+func checkRetention(t *target, config Config) []Finding {
+	req := requirements(t, config)
+	source := "module"
+	if req.Source != "" {
+		source = req.Source + " profile"
+	}
+
+	var findings []Finding
+	report := func(resource tfstate.Resource, days int) {
+		if days < req.MinimumRetentionDays {
+			findings = append(findings, Finding{resource.Address, fmt.Sprintf("retention is %d days, below the %d-day minimum of the %s", days, req.MinimumRetentionDays, source)})
+		}
+	}
+
+	for _, lock := range t.find("aws_s3_bucket_object_lock_configuration", "audit_logs") {
+		retention := tfstate.Lookup(lock.Values, "rule", 0, "default_retention", 0)
+		if days, ok := tfstate.Number(tfstate.Lookup(retention, "days")); ok {
+			report(lock, days)
+		} else if years, ok := tfstate.Number(tfstate.Lookup(retention, "years")); ok {
+			report(lock, years*365)
+		}
+	}
+	for _, policy := range t.find("azurerm_storage_container_immutability_policy", "audit_logs") {
+		if days, ok := tfstate.Number(policy.Values["immutability_period_in_days"]); ok {
+			report(policy, days)
+		}
+	}
+	return findings
+}
+
+func checkNetworkDefaultAction(t *target, _ Config) []Finding {
+	var findings []Finding
+	for _, name := range []string{"audit_logs", "replica"} {
+		for _, account := range t.find("azurerm_storage_account", name) {
+			if action, _ := tfstate.Lookup(account.Values, "network_rules", 0, "default_action").(string); action == "Allow" {
+				findings = append(findings, Finding{account.Address, `network_rules.default_action is "Allow"`})
+			}
+		}
+	}
+	return findings
+}
+
+func checkSharedKeyAccess(t *target, _ Config) []Finding {
+	var findings []Finding
+	for _, name := range []string{"audit_logs", "replica"} {
+		for _, account := range t.find("azurerm_storage_account", name) {
+			if enabled, _ := account.Values["shared_access_key_enabled"].(bool); enabled {
+				findings = append(findings, Finding{account.Address, "shared_access_key_enabled is true"})
+			}
+		}
+	}
+	return findings
+}
+
+func checkAccessLogging(t *target, _ Config) []Finding {
+	switch t.Provider {
+	case "aws":
+		if len(t.find("aws_s3_bucket_logging", "audit_logs")) == 0 {
+			return []Finding{{t.Primary.Address, "server access logging is not configured (access_log_bucket is not set)"}}
+		}
+	case "azure":
+		if len(t.find("azurerm_monitor_diagnostic_setting", "blob")) == 0 {
+			return []Finding{{t.Primary.Address, "blob read, write and delete logs are not sent to a diagnostics sink"}}
+		}
+	}
+	return nil
+}
+
+func checkReplicationKMS(t *target, _ Config) []Finding {
+	kms := false
+	for _, sse := range t.find("aws_s3_bucket_server_side_encryption_configuration", "audit_logs") {
+		algorithm, _ := tfstate.Lookup(sse.Values, "rule", 0, "apply_server_side_encryption_by_default", 0, "sse_algorithm").(string)
+		kms = kms || strings.HasPrefix(algorithm, "aws:kms")
+	}
+	if !kms {
+		return nil
+	}
+
+	var findings []Finding
+	for _, replication := range t.find("aws_s3_bucket_replication_configuration", "audit_logs") {
+		rules, _ := replication.Values["rule"].([]interface{})
+		for i, rule := range rules {
+			id, _ := tfstate.Lookup(rule, "id").(string)
+			if id == "" {
+				id = fmt.Sprintf("#%d", i)
+			}
+			status, _ := tfstate.Lookup(rule, "source_selection_criteria", 0, "sse_kms_encrypted_objects", 0, "status").(string)
+			key, _ := tfstate.Lookup(rule, "destination", 0, "encryption_configuration", 0, "replica_kms_key_id").(string)
+			switch {
+			case status != "Enabled":
+				findings = append(findings, Finding{replication.Address, fmt.Sprintf("rule %s does not replicate SSE-KMS encrypted objects", id)})
+			case key == "":
+				findings = append(findings, Finding{replication.Address, fmt.Sprintf("rule %s has no replica_kms_key_id", id)})
+			}
+		}
+	}
+	return findings
+}
+
+func checkImmutabilityLocked(t *target, config Config) []Finding {
+	env, isProduction := production(t, config)
+	req := requirements(t, config)
+	if !isProduction && req.ComplianceModeSource == "" {
+		return nil
+	}
+
+	var findings []Finding
+	for _, policy := range t.find("azurerm_storage_container_immutability_policy", "audit_logs") {
+		if locked, ok := policy.Values["locked"].(bool); !ok || locked {
+			continue
+		}
+		reason := fmt.Sprintf("the %s profile requires a locked policy", req.ComplianceModeSource)
+		if isProduction {
+			reason = fmt.Sprintf("the account is tagged %s", env)
+		}
+		findings = append(findings, Finding{policy.Address, fmt.Sprintf("the immutability policy is unlocked but %s", reason)})
+	}
+	return findings
+}
@@ -0,0 +1,390 @@
+// Package reaper removes Object Lock buckets, IAM policies and KMS keys left behind by the
+// integration and smoke tests. Terraform cannot destroy a bucket that still holds locked
+// versions, so tests against LocalStack skip the destroy and failed runs against a sandbox
+// account leak GOVERNANCE-locked buckets. The reaper only touches resources whose names
+// start with one of the test prefixes, and reports every resource it removed or would remove
+package reaper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/report"
+)
+
+// DefaultPrefixes are the bucket name prefixes used by tests/integration and tests/smoke
+var DefaultPrefixes = []string{"test-auditledger-", "test-local-", "test-ops-", "smoke-test-"}
+
+// KeyDeletionWindowDays is the shortest waiting period KMS allows before a key is deleted
+const KeyDeletionWindowDays = 7
+
+// deleteBatch is the most versions a DeleteObjects request accepts
+const deleteBatch = 1000
+
+// Reaper deletes test resources in one account and region
+type Reaper struct {
+	S3  s3iface.S3API
+	IAM iamiface.IAMAPI
+	KMS kmsiface.KMSAPI
+	// Region is the region of the S3 client; buckets in other regions are reported and skipped
+	Region   string
+	Prefixes []string
+	// OlderThan spares resources created more recently, e.g. by test runs still in progress
+	OlderThan time.Duration
+	// DryRun reports what would be deleted without changing anything
+	DryRun bool
+	// Now returns the current time; nil means time.Now
+	Now func() time.Time
+}
+
+// Reap deletes every matching bucket, IAM policy and KMS key. Resources that could not be
+// removed are failed checks; an error means the resources could not be listed
+func (r *Reaper) Reap(ctx context.Context) (*report.Report, error) {
+	for _, prefix := range r.Prefixes {
+		if prefix == "" {
+			return nil, fmt.Errorf("an empty prefix would match every resource in the account")
+		}
+	}
+
+	rep := report.New("aws", strings.Join(r.Prefixes, ","))
+	rep.Tool = "auditledger-reaper"
+
+	if err := r.reapBuckets(ctx, rep); err != nil {
+		return nil, err
+	}
+	if err := r.reapPolicies(ctx, rep); err != nil {
+		return nil, err
+	}
+	if err := r.reapKeys(ctx, rep); err != nil {
+		return nil, err
+	}
+	return rep, nil
+}
+
+// matches reports whether a resource name starts with a test prefix and is old enough to reap
+func (r *Reaper) matches(name string, created *time.Time) bool {
+	if created != nil && r.OlderThan > 0 {
+		now := time.Now
+		if r.Now != nil {
+			now = r.Now
+		}
+		if now().Sub(*created) < r.OlderThan {
+			return false
+		}
+	}
+	for _, prefix := range r.Prefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Reaper) reapBuckets(ctx context.Context, rep *report.Report) error {
+	buckets, err := r.S3.ListBucketsWithContext(ctx, &s3.ListBucketsInput{})
+	if err != nil {
+		return fmt.Errorf("listing buckets: %w", err)
+	}
+	for _, bucket := range buckets.Buckets {
+		if name := aws.StringValue(bucket.Name); r.matches(name, bucket.CreationDate) {
+			r.reapBucket(ctx, rep, name)
+		}
+	}
+	return nil
+}
+
+// reapBucket removes the bucket policy first: the module's DenyDeleteObject statement applies
+// to every principal, including the bypass role. A bucket with versions still under COMPLIANCE
+// retention is skipped before the policy goes, because those versions cannot be deleted and
+// the bucket would be left without its policy
+func (r *Reaper) reapBucket(ctx context.Context, rep *report.Report, name string) {
+	check := "s3_bucket " + name
+	bucket := aws.String(name)
+
+	location, err := r.S3.GetBucketLocationWithContext(ctx, &s3.GetBucketLocationInput{Bucket: bucket})
+	if err != nil {
+		rep.Failf(check, "reading the bucket region: %v", err)
+		return
+	}
+	if region := bucketRegion(location.LocationConstraint); r.Region != "" && region != r.Region {
+		rep.Skipf(check, "bucket is in %s; run the reaper with -region %s", region, region)
+		return
+	}
+
+	lock := "no Object Lock"
+	config, err := r.S3.GetObjectLockConfigurationWithContext(ctx, &s3.GetObjectLockConfigurationInput{Bucket: bucket})
+	if err := absent(err, "ObjectLockConfigurationNotFoundError"); err != nil {
+		rep.Failf(check, "reading the Object Lock configuration: %v", err)
+		return
+	}
+	if config != nil && config.ObjectLockConfiguration != nil {
+		lock = "Object Lock without default retention"
+		if rule := config.ObjectLockConfiguration.Rule; rule != nil && rule.DefaultRetention != nil {
+			lock = aws.StringValue(rule.DefaultRetention.Mode)
+		}
+	}
+
+	var objects, versions []*s3.ObjectIdentifier
+	markers := 0
+	err = r.S3.ListObjectVersionsPagesWithContext(ctx, &s3.ListObjectVersionsInput{Bucket: bucket}, func(page *s3.ListObjectVersionsOutput, lastPage bool) bool {
+		for _, version := range page.Versions {
+			versions = append(versions, &s3.ObjectIdentifier{Key: version.Key, VersionId: version.VersionId})
+		}
+		for _, marker := range page.DeleteMarkers {
+			objects = append(objects, &s3.ObjectIdentifier{Key: marker.Key, VersionId: marker.VersionId})
+			markers++
+		}
+		return true
+	})
+	if err != nil {
+		rep.Failf(check, "listing object versions: %v", err)
+		return
+	}
+	objects = append(versions, objects...)
+	contents := fmt.Sprintf("%d versions and %d delete markers, %s", len(versions), markers, lock)
+
+	if config != nil && config.ObjectLockConfiguration != nil {
+		locked, until, err := r.complianceRetained(ctx, bucket, versions)
+		if err != nil {
+			rep.Failf(check, "reading object retention: %v", err)
+			return
+		}
+		if locked > 0 {
+			rep.Skipf(check, "%d of %d versions are under COMPLIANCE retention until %s at the latest, which cannot be bypassed; the bucket and its policy were left in place, run the reaper again after it expires",
+				locked, len(versions), until.UTC().Format(time.RFC3339))
+			return
+		}
+	}
+
+	if r.DryRun {
+		rep.Skipf(check, "would delete with %s (dry run)", contents)
+		return
+	}
+
+	_, err = r.S3.DeleteBucketPolicyWithContext(ctx, &s3.DeleteBucketPolicyInput{Bucket: bucket})
+	if err := absent(err, "NoSuchBucketPolicy"); err != nil {
+		rep.Failf(check, "deleting the bucket policy: %v", err)
+		return
+	}
+
+	var failed []*s3.Error
+	for start := 0; start < len(objects); start += deleteBatch {
+		end := start + deleteBatch
+		if end > len(objects) {
+			end = len(objects)
+		}
+		out, err := r.S3.DeleteObjectsWithContext(ctx, &s3.DeleteObjectsInput{
+			Bucket:                    bucket,
+			Delete:                    &s3.Delete{Objects: objects[start:end], Quiet: aws.Bool(true)},
+			BypassGovernanceRetention: aws.Bool(true),
+		})
+		if err != nil {
+			rep.Failf(check, "deleting object versions: %v", err)
+			return
+		}
+		failed = append(failed, out.Errors...)
+	}
+	if len(failed) > 0 {
+		first := failed[0]
+		rep.Failf(check, "%d of %d object versions could not be deleted (%s), first %s version %s: %s: %s",
+			len(failed), len(objects), lock, aws.StringValue(first.Key), aws.StringValue(first.VersionId), aws.StringValue(first.Code), aws.StringValue(first.Message))
+		return
+	}
+
+	if _, err := r.S3.DeleteBucketWithContext(ctx, &s3.DeleteBucketInput{Bucket: bucket}); err != nil {
+		rep.Failf(check, "deleting the emptied bucket: %v", err)
+		return
+	}
+	rep.Passf(check, "deleted with %s", contents)
+}
+
+// complianceRetained counts the versions whose COMPLIANCE retention has not expired and
+// returns the latest retain-until date among them
+func (r *Reaper) complianceRetained(ctx context.Context, bucket *string, versions []*s3.ObjectIdentifier) (int, time.Time, error) {
+	now := time.Now
+	if r.Now != nil {
+		now = r.Now
+	}
+
+	locked, latest := 0, time.Time{}
+	for _, version := range versions {
+		out, err := r.S3.GetObjectRetentionWithContext(ctx, &s3.GetObjectRetentionInput{Bucket: bucket, Key: version.Key, VersionId: version.VersionId})
+		if err := absent(err, "NoSuchObjectLockConfiguration"); err != nil {
+			return 0, time.Time{}, fmt.Errorf("%s version %s: %w", aws.StringValue(version.Key), aws.StringValue(version.VersionId), err)
+		}
+		if out == nil || out.Retention == nil || aws.StringValue(out.Retention.Mode) != s3.ObjectLockRetentionModeCompliance {
+			continue
+		}
+		if until := aws.TimeValue(out.Retention.RetainUntilDate); until.After(now()) {
+			locked++
+			if until.After(latest) {
+				latest = until
+			}
+		}
+	}
+	return locked, latest, nil
+}
+
+// reapPolicies deletes the module's writer and manifest writer policies, which are named after
+// the bucket, after detaching them
+func (r *Reaper) reapPolicies(ctx context.Context, rep *report.Report) error {
+	var policies []*iam.Policy
+	err := r.IAM.ListPoliciesPagesWithContext(ctx, &iam.ListPoliciesInput{Scope: aws.String(iam.PolicyScopeTypeLocal)}, func(page *iam.ListPoliciesOutput, lastPage bool) bool {
+		for _, policy := range page.Policies {
+			if r.matches(aws.StringValue(policy.PolicyName), policy.CreateDate) {
+				policies = append(policies, policy)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("listing IAM policies: %w", err)
+	}
+
+	for _, policy := range policies {
+		check := "iam_policy " + aws.StringValue(policy.PolicyName)
+		if r.DryRun {
+			rep.Skipf(check, "would detach from %d roles, users and groups and delete (dry run)", aws.Int64Value(policy.AttachmentCount))
+			continue
+		}
+		if err := r.deletePolicy(ctx, policy.Arn); err != nil {
+			rep.Failf(check, "%v", err)
+			continue
+		}
+		rep.Passf(check, "detached from %d roles, users and groups and deleted", aws.Int64Value(policy.AttachmentCount))
+	}
+	return nil
+}
+
+func (r *Reaper) deletePolicy(ctx context.Context, arn *string) error {
+	var detach []func() error
+	err := r.IAM.ListEntitiesForPolicyPagesWithContext(ctx, &iam.ListEntitiesForPolicyInput{PolicyArn: arn}, func(page *iam.ListEntitiesForPolicyOutput, lastPage bool) bool {
+		for _, role := range page.PolicyRoles {
+			role := role
+			detach = append(detach, func() error {
+				_, err := r.IAM.DetachRolePolicyWithContext(ctx, &iam.DetachRolePolicyInput{PolicyArn: arn, RoleName: role.RoleName})
+				return err
+			})
+		}
+		for _, user := range page.PolicyUsers {
+			user := user
+			detach = append(detach, func() error {
+				_, err := r.IAM.DetachUserPolicyWithContext(ctx, &iam.DetachUserPolicyInput{PolicyArn: arn, UserName: user.UserName})
+				return err
+			})
+		}
+		for _, group := range page.PolicyGroups {
+			group := group
+			detach = append(detach, func() error {
+				_, err := r.IAM.DetachGroupPolicyWithContext(ctx, &iam.DetachGroupPolicyInput{PolicyArn: arn, GroupName: group.GroupName})
+				return err
+			})
+		}
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("listing attachments: %w", err)
+	}
+	for _, fn := range detach {
+		if err := fn(); err != nil {
+			return fmt.Errorf("detaching: %w", err)
+		}
+	}
+
+	// A policy can only be deleted once its non-default versions are gone
+	versions, err := r.IAM.ListPolicyVersionsWithContext(ctx, &iam.ListPolicyVersionsInput{PolicyArn: arn})
+	if err != nil {
+		return fmt.Errorf("listing versions: %w", err)
+	}
+	for _, version := range versions.Versions {
+		if aws.BoolValue(version.IsDefaultVersion) {
+			continue
+		}
+		if _, err := r.IAM.DeletePolicyVersionWithContext(ctx, &iam.DeletePolicyVersionInput{PolicyArn: arn, VersionId: version.VersionId}); err != nil {
+			return fmt.Errorf("deleting version %s: %w", aws.StringValue(version.VersionId), err)
+		}
+	}
+
+	if _, err := r.IAM.DeletePolicyWithContext(ctx, &iam.DeletePolicyInput{PolicyArn: arn}); err != nil {
+		return fmt.Errorf("deleting: %w", err)
+	}
+	return nil
+}
+
+// reapKeys schedules the deletion of customer managed keys whose alias matches a test prefix
+// and removes the alias so the name can be reused right away
+func (r *Reaper) reapKeys(ctx context.Context, rep *report.Report) error {
+	var aliases []*kms.AliasListEntry
+	err := r.KMS.ListAliasesPagesWithContext(ctx, &kms.ListAliasesInput{}, func(page *kms.ListAliasesOutput, lastPage bool) bool {
+		for _, alias := range page.Aliases {
+			if alias.TargetKeyId != nil && r.matches(strings.TrimPrefix(aws.StringValue(alias.AliasName), "alias/"), nil) {
+				aliases = append(aliases, alias)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return fmt.Errorf("listing KMS aliases: %w", err)
+	}
+
+	for _, alias := range aliases {
+		check := "kms_key " + aws.StringValue(alias.AliasName)
+		key, err := r.KMS.DescribeKeyWithContext(ctx, &kms.DescribeKeyInput{KeyId: alias.TargetKeyId})
+		if err != nil {
+			rep.Failf(check, "describing key %s: %v", aws.StringValue(alias.TargetKeyId), err)
+			continue
+		}
+		metadata := key.KeyMetadata
+		if aws.StringValue(metadata.KeyManager) != kms.KeyManagerTypeCustomer || !r.matches(strings.TrimPrefix(aws.StringValue(alias.AliasName), "alias/"), metadata.CreationDate) {
+			continue
+		}
+
+		if r.DryRun {
+			rep.Skipf(check, "would schedule key %s for deletion in %d days (dry run)", aws.StringValue(metadata.KeyId), KeyDeletionWindowDays)
+			continue
+		}
+		if aws.StringValue(metadata.KeyState) != kms.KeyStatePendingDeletion {
+			if _, err := r.KMS.ScheduleKeyDeletionWithContext(ctx, &kms.ScheduleKeyDeletionInput{KeyId: metadata.KeyId, PendingWindowInDays: aws.Int64(KeyDeletionWindowDays)}); err != nil {
+				rep.Failf(check, "scheduling deletion of key %s: %v", aws.StringValue(metadata.KeyId), err)
+				continue
+			}
+		}
+		if _, err := r.KMS.DeleteAliasWithContext(ctx, &kms.DeleteAliasInput{AliasName: alias.AliasName}); err != nil {
+			rep.Failf(check, "deleting the alias: %v", err)
+			continue
+		}
+		rep.Passf(check, "key %s scheduled for deletion in %d days and alias deleted", aws.StringValue(metadata.KeyId), KeyDeletionWindowDays)
+	}
+	return nil
+}
+
+// bucketRegion maps a LocationConstraint to its region; buckets in us-east-1 have none
+func bucketRegion(constraint *string) string {
+	switch region := aws.StringValue(constraint); region {
+	case "":
+		return "us-east-1"
+	case "EU":
+		return "eu-west-1"
+	default:
+		return region
+	}
+}
+
+// absent treats the error S3 returns for an unconfigured setting as no error
+func absent(err error, code string) error {
+	if aerr, ok := err.(awserr.Error); ok && aerr.Code() == code {
+		return nil
+	}
+	return err
+}
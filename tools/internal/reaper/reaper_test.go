@@ -0,0 +1,392 @@
+package reaper
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/report"
+)
+
+var now = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+type bucket struct {
+	created  time.Time
+	region   string
+	lockMode string
+	policy   bool
+	versions []string
+	markers  []string
+	// compliance holds the COMPLIANCE retain-until date of versions; until then they cannot
+	// be deleted, not even with the bypass header
+	compliance map[string]time.Time
+}
+
+// fakeS3 keeps buckets in memory and records the mutating calls in order
+type fakeS3 struct {
+	s3iface.S3API
+	buckets map[string]*bucket
+	calls   []string
+}
+
+func (f *fakeS3) ListBucketsWithContext(aws.Context, *s3.ListBucketsInput, ...request.Option) (*s3.ListBucketsOutput, error) {
+	out := &s3.ListBucketsOutput{}
+	for _, name := range []string{"audit-logs-prod", "smoke-test-abc", "test-local-new", "test-local-old", "test-ops-west"} {
+		if b, ok := f.buckets[name]; ok {
+			out.Buckets = append(out.Buckets, &s3.Bucket{Name: aws.String(name), CreationDate: aws.Time(b.created)})
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeS3) GetBucketLocationWithContext(_ aws.Context, in *s3.GetBucketLocationInput, _ ...request.Option) (*s3.GetBucketLocationOutput, error) {
+	region := f.buckets[aws.StringValue(in.Bucket)].region
+	if region == "us-east-1" {
+		return &s3.GetBucketLocationOutput{}, nil
+	}
+	return &s3.GetBucketLocationOutput{LocationConstraint: aws.String(region)}, nil
+}
+
+func (f *fakeS3) GetObjectLockConfigurationWithContext(_ aws.Context, in *s3.GetObjectLockConfigurationInput, _ ...request.Option) (*s3.GetObjectLockConfigurationOutput, error) {
+	b := f.buckets[aws.StringValue(in.Bucket)]
+	if b.lockMode == "" {
+		return nil, awserr.New("ObjectLockConfigurationNotFoundError", "no lock", nil)
+	}
+	return &s3.GetObjectLockConfigurationOutput{ObjectLockConfiguration: &s3.ObjectLockConfiguration{
+		ObjectLockEnabled: aws.String("Enabled"),
+		Rule:              &s3.ObjectLockRule{DefaultRetention: &s3.DefaultRetention{Mode: aws.String(b.lockMode), Days: aws.Int64(365)}},
+	}}, nil
+}
+
+func (f *fakeS3) ListObjectVersionsPagesWithContext(_ aws.Context, in *s3.ListObjectVersionsInput, fn func(*s3.ListObjectVersionsOutput, bool) bool, _ ...request.Option) error {
+	b := f.buckets[aws.StringValue(in.Bucket)]
+	page := &s3.ListObjectVersionsOutput{}
+	for _, id := range b.versions {
+		page.Versions = append(page.Versions, &s3.ObjectVersion{Key: aws.String("log.json"), VersionId: aws.String(id)})
+	}
+	for _, id := range b.markers {
+		page.DeleteMarkers = append(page.DeleteMarkers, &s3.DeleteMarkerEntry{Key: aws.String("log.json"), VersionId: aws.String(id)})
+	}
+	fn(page, true)
+	return nil
+}
+
+func (f *fakeS3) GetObjectRetentionWithContext(_ aws.Context, in *s3.GetObjectRetentionInput, _ ...request.Option) (*s3.GetObjectRetentionOutput, error) {
+	b := f.buckets[aws.StringValue(in.Bucket)]
+	until, ok := b.compliance[aws.StringValue(in.VersionId)]
+	if !ok {
+		return &s3.GetObjectRetentionOutput{Retention: &s3.ObjectLockRetention{Mode: aws.String(b.lockMode), RetainUntilDate: aws.Time(now.Add(-time.Hour))}}, nil
+	}
+	return &s3.GetObjectRetentionOutput{Retention: &s3.ObjectLockRetention{Mode: aws.String("COMPLIANCE"), RetainUntilDate: aws.Time(until)}}, nil
+}
+
+func (f *fakeS3) DeleteBucketPolicyWithContext(_ aws.Context, in *s3.DeleteBucketPolicyInput, _ ...request.Option) (*s3.DeleteBucketPolicyOutput, error) {
+	b := f.buckets[aws.StringValue(in.Bucket)]
+	if !b.policy {
+		return nil, awserr.New("NoSuchBucketPolicy", "no policy", nil)
+	}
+	b.policy = false
+	f.calls = append(f.calls, "DeleteBucketPolicy "+aws.StringValue(in.Bucket))
+	return &s3.DeleteBucketPolicyOutput{}, nil
+}
+
+func (f *fakeS3) DeleteObjectsWithContext(_ aws.Context, in *s3.DeleteObjectsInput, _ ...request.Option) (*s3.DeleteObjectsOutput, error) {
+	b := f.buckets[aws.StringValue(in.Bucket)]
+	f.calls = append(f.calls, fmt.Sprintf("DeleteObjects %s %d bypass=%t", aws.StringValue(in.Bucket), len(in.Delete.Objects), aws.BoolValue(in.BypassGovernanceRetention)))
+	if b.policy {
+		return nil, awserr.New("AccessDenied", "denied by DenyDeleteObject", nil)
+	}
+
+	out := &s3.DeleteObjectsOutput{}
+	remaining := map[string]bool{}
+	for _, object := range in.Delete.Objects {
+		id := aws.StringValue(object.VersionId)
+		if b.compliance[id].After(now) {
+			remaining[id] = true
+			out.Errors = append(out.Errors, &s3.Error{Key: object.Key, VersionId: object.VersionId, Code: aws.String("AccessDenied"), Message: aws.String("Access Denied because object protected by object lock.")})
+		}
+	}
+	keep := func(ids []string) []string {
+		var kept []string
+		for _, id := range ids {
+			if remaining[id] {
+				kept = append(kept, id)
+			}
+		}
+		return kept
+	}
+	b.versions, b.markers = keep(b.versions), keep(b.markers)
+	return out, nil
+}
+
+func (f *fakeS3) DeleteBucketWithContext(_ aws.Context, in *s3.DeleteBucketInput, _ ...request.Option) (*s3.DeleteBucketOutput, error) {
+	name := aws.StringValue(in.Bucket)
+	if b := f.buckets[name]; len(b.versions)+len(b.markers) > 0 {
+		return nil, awserr.New("BucketNotEmpty", "not empty", nil)
+	}
+	delete(f.buckets, name)
+	f.calls = append(f.calls, "DeleteBucket "+name)
+	return &s3.DeleteBucketOutput{}, nil
+}
+
+type policy struct {
+	created  time.Time
+	roles    []string
+	versions []string
+}
+
+type fakeIAM struct {
+	iamiface.IAMAPI
+	policies map[string]*policy
+	calls    []string
+}
+
+func (f *fakeIAM) ListPoliciesPagesWithContext(_ aws.Context, in *iam.ListPoliciesInput, fn func(*iam.ListPoliciesOutput, bool) bool, _ ...request.Option) error {
+	if aws.StringValue(in.Scope) != iam.PolicyScopeTypeLocal {
+		return fmt.Errorf("AWS managed policies must not be listed")
+	}
+	page := &iam.ListPoliciesOutput{}
+	for _, name := range []string{"audit-logs-prod-access-policy", "test-local-old-access-policy", "test-local-old-manifest-writer-policy"} {
+		if p, ok := f.policies[name]; ok {
+			page.Policies = append(page.Policies, &iam.Policy{PolicyName: aws.String(name), Arn: aws.String("arn:aws:iam::000000000000:policy/" + name), CreateDate: aws.Time(p.created), AttachmentCount: aws.Int64(int64(len(p.roles)))})
+		}
+	}
+	fn(page, true)
+	return nil
+}
+
+func (f *fakeIAM) name(arn *string) string {
+	return aws.StringValue(arn)[len("arn:aws:iam::000000000000:policy/"):]
+}
+
+func (f *fakeIAM) ListEntitiesForPolicyPagesWithContext(_ aws.Context, in *iam.ListEntitiesForPolicyInput, fn func(*iam.ListEntitiesForPolicyOutput, bool) bool, _ ...request.Option) error {
+	page := &iam.ListEntitiesForPolicyOutput{}
+	for _, role := range f.policies[f.name(in.PolicyArn)].roles {
+		page.PolicyRoles = append(page.PolicyRoles, &iam.PolicyRole{RoleName: aws.String(role)})
+	}
+	fn(page, true)
+	return nil
+}
+
+func (f *fakeIAM) DetachRolePolicyWithContext(_ aws.Context, in *iam.DetachRolePolicyInput, _ ...request.Option) (*iam.DetachRolePolicyOutput, error) {
+	f.policies[f.name(in.PolicyArn)].roles = nil
+	f.calls = append(f.calls, "DetachRolePolicy "+aws.StringValue(in.RoleName)+" "+f.name(in.PolicyArn))
+	return &iam.DetachRolePolicyOutput{}, nil
+}
+
+func (f *fakeIAM) ListPolicyVersionsWithContext(_ aws.Context, in *iam.ListPolicyVersionsInput, _ ...request.Option) (*iam.ListPolicyVersionsOutput, error) {
+	out := &iam.ListPolicyVersionsOutput{}
+	for i, id := range f.policies[f.name(in.PolicyArn)].versions {
+		out.Versions = append(out.Versions, &iam.PolicyVersion{VersionId: aws.String(id), IsDefaultVersion: aws.Bool(i == 0)})
+	}
+	return out, nil
+}
+
+func (f *fakeIAM) DeletePolicyVersionWithContext(_ aws.Context, in *iam.DeletePolicyVersionInput, _ ...request.Option) (*iam.DeletePolicyVersionOutput, error) {
+	p := f.policies[f.name(in.PolicyArn)]
+	p.versions = p.versions[:1]
+	f.calls = append(f.calls, "DeletePolicyVersion "+f.name(in.PolicyArn)+" "+aws.StringValue(in.VersionId))
+	return &iam.DeletePolicyVersionOutput{}, nil
+}
+
+func (f *fakeIAM) DeletePolicyWithContext(_ aws.Context, in *iam.DeletePolicyInput, _ ...request.Option) (*iam.DeletePolicyOutput, error) {
+	name := f.name(in.PolicyArn)
+	if p := f.policies[name]; len(p.roles) > 0 || len(p.versions) > 1 {
+		return nil, awserr.New("DeleteConflict", "still attached or versioned", nil)
+	}
+	delete(f.policies, name)
+	f.calls = append(f.calls, "DeletePolicy "+name)
+	return &iam.DeletePolicyOutput{}, nil
+}
+
+type fakeKMS struct {
+	kmsiface.KMSAPI
+	aliases map[string]*kms.KeyMetadata
+	calls   []string
+}
+
+func (f *fakeKMS) ListAliasesPagesWithContext(_ aws.Context, _ *kms.ListAliasesInput, fn func(*kms.ListAliasesOutput, bool) bool, _ ...request.Option) error {
+	page := &kms.ListAliasesOutput{Aliases: []*kms.AliasListEntry{{AliasName: aws.String("alias/test-unused")}}}
+	for _, name := range []string{"alias/aws/s3", "alias/audit-logs-prod", "alias/test-auditledger-kms-abc"} {
+		if key, ok := f.aliases[name]; ok {
+			page.Aliases = append(page.Aliases, &kms.AliasListEntry{AliasName: aws.String(name), TargetKeyId: key.KeyId})
+		}
+	}
+	fn(page, true)
+	return nil
+}
+
+func (f *fakeKMS) DescribeKeyWithContext(_ aws.Context, in *kms.DescribeKeyInput, _ ...request.Option) (*kms.DescribeKeyOutput, error) {
+	for _, key := range f.aliases {
+		if aws.StringValue(key.KeyId) == aws.StringValue(in.KeyId) {
+			return &kms.DescribeKeyOutput{KeyMetadata: key}, nil
+		}
+	}
+	return nil, awserr.New("NotFoundException", "no key", nil)
+}
+
+func (f *fakeKMS) ScheduleKeyDeletionWithContext(_ aws.Context, in *kms.ScheduleKeyDeletionInput, _ ...request.Option) (*kms.ScheduleKeyDeletionOutput, error) {
+	f.calls = append(f.calls, fmt.Sprintf("ScheduleKeyDeletion %s %d", aws.StringValue(in.KeyId), aws.Int64Value(in.PendingWindowInDays)))
+	return &kms.ScheduleKeyDeletionOutput{}, nil
+}
+
+func (f *fakeKMS) DeleteAliasWithContext(_ aws.Context, in *kms.DeleteAliasInput, _ ...request.Option) (*kms.DeleteAliasOutput, error) {
+	delete(f.aliases, aws.StringValue(in.AliasName))
+	f.calls = append(f.calls, "DeleteAlias "+aws.StringValue(in.AliasName))
+	return &kms.DeleteAliasOutput{}, nil
+}
+
+func newAccount() (*fakeS3, *fakeIAM, *fakeKMS) {
+	old := now.Add(-48 * time.Hour)
+	s3API := &fakeS3{buckets: map[string]*bucket{
+		"audit-logs-prod": {created: old, region: "us-east-1", lockMode: "COMPLIANCE", policy: true, versions: []string{"p1"}},
+		"test-local-old":  {created: old, region: "us-east-1", lockMode: "GOVERNANCE", policy: true, versions: []string{"v1", "v2"}, markers: []string{"m1"}},
+		"test-local-new":  {created: now.Add(-10 * time.Minute), region: "us-east-1", lockMode: "GOVERNANCE", policy: true, versions: []string{"v1"}},
+		"smoke-test-abc":  {created: old, region: "us-east-1"},
+		"test-ops-west":   {created: old, region: "us-west-2", lockMode: "GOVERNANCE"},
+	}}
+	iamAPI := &fakeIAM{policies: map[string]*policy{
+		"audit-logs-prod-access-policy":         {created: old, roles: []string{"app"}, versions: []string{"v1"}},
+		"test-local-old-access-policy":          {created: old, roles: []string{"test-role"}, versions: []string{"v2", "v1"}},
+		"test-local-old-manifest-writer-policy": {created: old, versions: []string{"v1"}},
+	}}
+	kmsAPI := &fakeKMS{aliases: map[string]*kms.KeyMetadata{
+		"alias/aws/s3":                   {KeyId: aws.String("aws-key"), KeyManager: aws.String("AWS"), KeyState: aws.String("Enabled"), CreationDate: aws.Time(old)},
+		"alias/audit-logs-prod":          {KeyId: aws.String("prod-key"), KeyManager: aws.String("CUSTOMER"), KeyState: aws.String("Enabled"), CreationDate: aws.Time(old)},
+		"alias/test-auditledger-kms-abc": {KeyId: aws.String("test-key"), KeyManager: aws.String("CUSTOMER"), KeyState: aws.String("Enabled"), CreationDate: aws.Time(old)},
+	}}
+	return s3API, iamAPI, kmsAPI
+}
+
+func newReaper(s3API *fakeS3, iamAPI *fakeIAM, kmsAPI *fakeKMS) *Reaper {
+	return &Reaper{
+		S3: s3API, IAM: iamAPI, KMS: kmsAPI,
+		Region:    "us-east-1",
+		Prefixes:  DefaultPrefixes,
+		OlderThan: time.Hour,
+		Now:       func() time.Time { return now },
+	}
+}
+
+func statuses(r *report.Report) map[string]report.Status {
+	result := map[string]report.Status{}
+	for _, check := range r.Checks {
+		result[check.Name] = check.Status
+	}
+	return result
+}
+
+func TestReap(t *testing.T) {
+	s3API, iamAPI, kmsAPI := newAccount()
+
+	r, err := newReaper(s3API, iamAPI, kmsAPI).Reap(context.Background())
+	require.NoError(t, err)
+	assert.True(t, r.Passed())
+	assert.Equal(t, "auditledger-reaper", r.Tool)
+	assert.Equal(t, map[string]report.Status{
+		"s3_bucket test-local-old":                         report.Pass,
+		"s3_bucket smoke-test-abc":                         report.Pass,
+		"s3_bucket test-ops-west":                          report.Skip,
+		"iam_policy test-local-old-access-policy":          report.Pass,
+		"iam_policy test-local-old-manifest-writer-policy": report.Pass,
+		"kms_key alias/test-auditledger-kms-abc":           report.Pass,
+	}, statuses(r))
+
+	assert.Equal(t, []string{
+		"DeleteBucket smoke-test-abc",
+		"DeleteBucketPolicy test-local-old",
+		"DeleteObjects test-local-old 3 bypass=true",
+		"DeleteBucket test-local-old",
+	}, s3API.calls, "The policy goes first because DenyDeleteObject applies to every principal")
+	assert.Contains(t, s3API.buckets, "audit-logs-prod")
+	assert.Contains(t, s3API.buckets, "test-local-new", "Buckets younger than -older-than may belong to a running test")
+	assert.Contains(t, s3API.buckets, "test-ops-west", "Buckets in other regions are skipped")
+
+	assert.Equal(t, []string{
+		"DetachRolePolicy test-role test-local-old-access-policy",
+		"DeletePolicyVersion test-local-old-access-policy v1",
+		"DeletePolicy test-local-old-access-policy",
+		"DeletePolicy test-local-old-manifest-writer-policy",
+	}, iamAPI.calls)
+	assert.Equal(t, []string{"ScheduleKeyDeletion test-key 7", "DeleteAlias alias/test-auditledger-kms-abc"}, kmsAPI.calls)
+
+	for _, check := range r.Checks {
+		if check.Name == "s3_bucket test-local-old" {
+			assert.Equal(t, "deleted with 2 versions and 1 delete markers, GOVERNANCE", check.Message)
+		}
+		if check.Name == "s3_bucket test-ops-west" {
+			assert.Equal(t, "bucket is in us-west-2; run the reaper with -region us-west-2", check.Message)
+		}
+	}
+}
+
+func TestReapDryRun(t *testing.T) {
+	s3API, iamAPI, kmsAPI := newAccount()
+	reaper := newReaper(s3API, iamAPI, kmsAPI)
+	reaper.DryRun = true
+
+	r, err := reaper.Reap(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, s3API.calls)
+	assert.Empty(t, iamAPI.calls)
+	assert.Empty(t, kmsAPI.calls)
+	assert.Len(t, r.Checks, 6)
+	for _, check := range r.Checks {
+		assert.Equal(t, report.Skip, check.Status, check.Name)
+	}
+}
+
+func TestReapComplianceRetention(t *testing.T) {
+	s3API, iamAPI, kmsAPI := newAccount()
+	b := s3API.buckets["test-local-old"]
+	b.lockMode = "COMPLIANCE"
+	b.compliance = map[string]time.Time{"v1": now.Add(-time.Hour), "v2": now.Add(24 * time.Hour)}
+
+	r, err := newReaper(s3API, iamAPI, kmsAPI).Reap(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, report.Skip, statuses(r)["s3_bucket test-local-old"])
+	assert.True(t, b.policy, "The policy stays while versions cannot be deleted")
+	assert.Equal(t, []string{"v1", "v2"}, b.versions)
+	assert.Equal(t, []string{"DeleteBucket smoke-test-abc"}, s3API.calls)
+	for _, check := range r.Checks {
+		if check.Name == "s3_bucket test-local-old" {
+			assert.Contains(t, check.Message, "1 of 2 versions are under COMPLIANCE retention until 2026-03-02T12:00:00Z")
+		}
+	}
+}
+
+func TestReapExpiredComplianceRetention(t *testing.T) {
+	s3API, iamAPI, kmsAPI := newAccount()
+	b := s3API.buckets["test-local-old"]
+	b.lockMode = "COMPLIANCE"
+	b.compliance = map[string]time.Time{"v1": now.Add(-time.Hour), "v2": now.Add(-time.Minute)}
+
+	r, err := newReaper(s3API, iamAPI, kmsAPI).Reap(context.Background())
+	require.NoError(t, err)
+	assert.True(t, r.Passed())
+	assert.Equal(t, report.Pass, statuses(r)["s3_bucket test-local-old"])
+	assert.NotContains(t, s3API.buckets, "test-local-old")
+}
+
+func TestReapRejectsEmptyPrefix(t *testing.T) {
+	s3API, iamAPI, kmsAPI := newAccount()
+	reaper := newReaper(s3API, iamAPI, kmsAPI)
+	reaper.Prefixes = []string{"test-local-", ""}
+
+	_, err := reaper.Reap(context.Background())
+	assert.ErrorContains(t, err, "empty prefix")
+	assert.Empty(t, s3API.calls)
+}
@@ -0,0 +1,123 @@
+package report
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// WriteText renders a human-readable report
+func (r *Report) WriteText(w io.Writer) error {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "%s: %s %s\n\n", r.Tool, r.Provider, r.Target)
+	for _, check := range r.Checks {
+		fmt.Fprintf(&b, "[%s] %s: %s\n", check.Status, check.Name, check.Message)
+		if check.Status == Fail && (check.Expected != "" || check.Actual != "") {
+			fmt.Fprintf(&b, "       expected: %s\n       actual:   %s\n", check.Expected, check.Actual)
+		}
+	}
+
+	passed, failed, skipped := r.Counts()
+	result := "PASSED"
+	if failed > 0 {
+		result = "FAILED"
+	}
+	fmt.Fprintf(&b, "\n%s: %d passed, %d failed, %d skipped\n", result, passed, failed, skipped)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteJSON renders the report with a summary for machine consumption
+func (r *Report) WriteJSON(w io.Writer) error {
+	passed, failed, skipped := r.Counts()
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(struct {
+		*Report
+		Passed  bool           `json:"passed"`
+		Summary map[string]int `json:"summary"`
+	}{
+		Report: r,
+		Passed: failed == 0,
+		Summary: map[string]int{
+			"passed":  passed,
+			"failed":  failed,
+			"skipped": skipped,
+		},
+	})
+}
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit renders the report as JUnit XML so CI systems can display each check
+func (r *Report) WriteJUnit(w io.Writer) error {
+	passed, failed, skipped := r.Counts()
+	suite := junitTestSuite{
+		Name:      fmt.Sprintf("%s.%s", r.Tool, r.Provider),
+		Tests:     passed + failed + skipped,
+		Failures:  failed,
+		Skipped:   skipped,
+		Timestamp: r.GeneratedAt.Format("2006-01-02T15:04:05"),
+	}
+
+	for _, check := range r.Checks {
+		testCase := junitTestCase{Name: check.Name, ClassName: r.Target}
+		switch check.Status {
+		case Fail:
+			testCase.Failure = &junitFailure{
+				Message: check.Message,
+				Body:    fmt.Sprintf("expected: %s\nactual: %s", check.Expected, check.Actual),
+			}
+		case Skip:
+			testCase.Skipped = &junitSkipped{Message: check.Message}
+		default:
+			testCase.SystemOut = check.Message
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,114 @@
+// Package report collects verification checks and renders them as text, JSON or JUnit XML
+package report
+
+import (
+	"fmt"
+	"io"
+	"time"
+)
+
+// Status is the outcome of a single check
+type Status string
+
+const (
+	Pass Status = "PASS"
+	Fail Status = "FAIL"
+	Skip Status = "SKIP"
+)
+
+// Check is one verified property of a deployed resource
+type Check struct {
+	Name     string `json:"name"`
+	Status   Status `json:"status"`
+	Message  string `json:"message"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// Report is the result of verifying one deployed resource
+type Report struct {
+	Tool        string    `json:"tool"`
+	Provider    string    `json:"provider"`
+	Target      string    `json:"target"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Checks      []Check   `json:"checks"`
+}
+
+// New returns an empty report for the given provider and target
+func New(provider, target string) *Report {
+	return &Report{
+		Tool:        "auditledger-verify",
+		Provider:    provider,
+		Target:      target,
+		GeneratedAt: time.Now().UTC(),
+		Checks:      []Check{},
+	}
+}
+
+// Add appends a check
+func (r *Report) Add(check Check) {
+	r.Checks = append(r.Checks, check)
+}
+
+// Passf records a passing check
+func (r *Report) Passf(name, format string, args ...interface{}) {
+	r.Add(Check{Name: name, Status: Pass, Message: fmt.Sprintf(format, args...)})
+}
+
+// Skipf records a check that does not apply to this deployment
+func (r *Report) Skipf(name, format string, args ...interface{}) {
+	r.Add(Check{Name: name, Status: Skip, Message: fmt.Sprintf(format, args...)})
+}
+
+// Failf records a failing check
+func (r *Report) Failf(name, format string, args ...interface{}) {
+	r.Add(Check{Name: name, Status: Fail, Message: fmt.Sprintf(format, args...)})
+}
+
+// Compare records a check that passes when expected and actual are equal
+func (r *Report) Compare(name, expected, actual string) {
+	check := Check{Name: name, Expected: expected, Actual: actual, Status: Pass, Message: "matches the expected value"}
+	if expected != actual {
+		check.Status = Fail
+		check.Message = "does not match the expected value"
+	}
+	r.Add(check)
+}
+
+// Counts returns the number of passed, failed and skipped checks
+func (r *Report) Counts() (passed, failed, skipped int) {
+	for _, check := range r.Checks {
+		switch check.Status {
+		case Pass:
+			passed++
+		case Fail:
+			failed++
+		case Skip:
+			skipped++
+		}
+	}
+	return passed, failed, skipped
+}
+
+// Passed reports whether no check failed
+func (r *Report) Passed() bool {
+	_, failed, _ := r.Counts()
+	return failed == 0
+}
+
+// Formats lists the supported output formats
+var Formats = []string{"text", "json", "junit"}
+
+// Write renders the report in the given format
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "text":
+		return r.WriteText(w)
+	case "json":
+		return r.WriteJSON(w)
+	case "junit":
+		return r.WriteJUnit(w)
+	default:
+		return fmt.Errorf("unsupported format %q (use text, json or junit)", format)
+	}
+}
@@ -0,0 +1,76 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func sampleReport() *Report {
+	r := New("aws", "s3://audit-logs")
+	r.Passf("versioning", "versioning is enabled")
+	r.Compare("object_lock.mode", "COMPLIANCE", "GOVERNANCE")
+	r.Skipf("replication", "replication is not configured (optional)")
+	return r
+}
+
+func TestCountsAndPassed(t *testing.T) {
+	r := sampleReport()
+
+	passed, failed, skipped := r.Counts()
+	assert.Equal(t, []int{1, 1, 1}, []int{passed, failed, skipped})
+	assert.False(t, r.Passed())
+
+	assert.True(t, New("aws", "s3://empty").Passed(), "A report without checks has no failures")
+}
+
+func TestWriteText(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, sampleReport().Write(&out, "text"))
+
+	assert.Contains(t, out.String(), "[FAIL] object_lock.mode")
+	assert.Contains(t, out.String(), "expected: COMPLIANCE")
+	assert.Contains(t, out.String(), "FAILED: 1 passed, 1 failed, 1 skipped")
+}
+
+func TestWriteJSON(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, sampleReport().Write(&out, "json"))
+
+	var decoded struct {
+		Provider string         `json:"provider"`
+		Passed   bool           `json:"passed"`
+		Summary  map[string]int `json:"summary"`
+		Checks   []Check        `json:"checks"`
+	}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+
+	assert.Equal(t, "aws", decoded.Provider)
+	assert.False(t, decoded.Passed)
+	assert.Equal(t, 1, decoded.Summary["failed"])
+	assert.Len(t, decoded.Checks, 3)
+}
+
+func TestWriteJUnit(t *testing.T) {
+	var out bytes.Buffer
+	require.NoError(t, sampleReport().Write(&out, "junit"))
+
+	var decoded junitTestSuites
+	require.NoError(t, xml.Unmarshal(out.Bytes(), &decoded))
+	require.Len(t, decoded.Suites, 1)
+
+	suite := decoded.Suites[0]
+	assert.Equal(t, 3, suite.Tests)
+	assert.Equal(t, 1, suite.Failures)
+	assert.Equal(t, 1, suite.Skipped)
+	assert.NotNil(t, suite.Cases[1].Failure)
+	assert.NotNil(t, suite.Cases[2].Skipped)
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	assert.Error(t, sampleReport().Write(&bytes.Buffer{}, "yaml"))
+}
@@ -0,0 +1,95 @@
+// Package signing creates and loads the keys that sign evidence bundles and digest manifests, and signs and verifies with them
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+// GenerateKey creates an Ed25519 signing key pair as PKCS#8 and PKIX PEM blocks
+func GenerateKey() (privatePEM, publicPEM []byte, err error) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), nil
+}
+
+// LoadPrivateKey reads an Ed25519 private key from a PKCS#8 PEM file
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	block, err := readPEM(path, "PRIVATE KEY")
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	private, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an Ed25519 private key", path)
+	}
+	return private, nil
+}
+
+// LoadPublicKey reads an Ed25519 public key from a PKIX PEM file
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEM(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	return ParsePublicKey(block.Bytes)
+}
+
+// ParsePublicKey decodes a PKIX DER Ed25519 public key
+func ParsePublicKey(der []byte) (ed25519.PublicKey, error) {
+	key, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return nil, err
+	}
+	public, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("not an Ed25519 public key")
+	}
+	return public, nil
+}
+
+func readPEM(path, blockType string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != blockType {
+		return nil, fmt.Errorf("%s: no %s PEM block", path, blockType)
+	}
+	return block, nil
+}
+
+// Fingerprint identifies a public key by the SHA-256 of its PKIX encoding
+func Fingerprint(public ed25519.PublicKey) string {
+	der, err := x509.MarshalPKIXPublicKey(public)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(der)
+	return "SHA256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,52 @@
+package signing
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyRoundTrip(t *testing.T) {
+	privatePEM, publicPEM, err := GenerateKey()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(dir+"/key.pem", privatePEM, 0o600))
+	require.NoError(t, os.WriteFile(dir+"/key.pub.pem", publicPEM, 0o600))
+
+	private, err := LoadPrivateKey(dir + "/key.pem")
+	require.NoError(t, err)
+	public, err := LoadPublicKey(dir + "/key.pub.pem")
+	require.NoError(t, err)
+	assert.Equal(t, private.Public(), public)
+
+	_, err = LoadPrivateKey(dir + "/key.pub.pem")
+	assert.Error(t, err)
+}
+
+func TestLoadVerifier(t *testing.T) {
+	privatePEM, publicPEM, err := GenerateKey()
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(dir+"/key.pem", privatePEM, 0o600))
+	require.NoError(t, os.WriteFile(dir+"/key.pub.pem", publicPEM, 0o600))
+
+	private, err := LoadPrivateKey(dir + "/key.pem")
+	require.NoError(t, err)
+	verifier, err := LoadVerifier(dir + "/key.pub.pem")
+	require.NoError(t, err)
+
+	signer := Ed25519Signer{Key: private}
+	signature, err := signer.Sign(context.Background(), []byte("payload"))
+	require.NoError(t, err)
+	assert.Equal(t, signer.KeyID(), verifier.KeyID())
+	assert.NoError(t, verifier.Verify(context.Background(), []byte("payload"), signature))
+	assert.ErrorIs(t, verifier.Verify(context.Background(), []byte("forged"), signature), ErrBadSignature)
+
+	_, err = LoadVerifier(dir + "/key.pem")
+	assert.Error(t, err, "A private key is not a verification key")
+}
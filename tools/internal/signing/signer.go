@@ -0,0 +1,77 @@
+package signing
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+)
+
+// Signature algorithms recorded next to each signature
+const (
+	AlgorithmEd25519     = "ed25519"
+	AlgorithmECDSASHA256 = "ecdsa-sha256"
+)
+
+// Signer signs payloads with a key that verifiers can identify by KeyID
+type Signer interface {
+	KeyID() string
+	Algorithm() string
+	Sign(ctx context.Context, payload []byte) ([]byte, error)
+}
+
+// Ed25519Signer signs with a local Ed25519 private key; its key ID is the public key fingerprint
+type Ed25519Signer struct {
+	Key ed25519.PrivateKey
+}
+
+// KeyID implements Signer
+func (s Ed25519Signer) KeyID() string {
+	return Fingerprint(s.Key.Public().(ed25519.PublicKey))
+}
+
+// Algorithm implements Signer
+func (s Ed25519Signer) Algorithm() string {
+	return AlgorithmEd25519
+}
+
+// Sign implements Signer
+func (s Ed25519Signer) Sign(_ context.Context, payload []byte) ([]byte, error) {
+	return ed25519.Sign(s.Key, payload), nil
+}
+
+// KMSSigner signs with an asymmetric AWS KMS key (key spec ECC_NIST_P256, usage SIGN_VERIFY),
+// so the private key never leaves KMS. Signatures are ASN.1 ECDSA over the SHA-256 of the payload
+type KMSSigner struct {
+	API kmsiface.KMSAPI
+	Key string // key ID, ARN or alias ARN
+}
+
+// KeyID implements Signer
+func (s KMSSigner) KeyID() string {
+	return s.Key
+}
+
+// Algorithm implements Signer
+func (s KMSSigner) Algorithm() string {
+	return AlgorithmECDSASHA256
+}
+
+// Sign implements Signer
+func (s KMSSigner) Sign(ctx context.Context, payload []byte) ([]byte, error) {
+	digest := sha256.Sum256(payload)
+	out, err := s.API.SignWithContext(ctx, &kms.SignInput{
+		KeyId:            aws.String(s.Key),
+		Message:          digest[:],
+		MessageType:      aws.String(kms.MessageTypeDigest),
+		SigningAlgorithm: aws.String(kms.SigningAlgorithmSpecEcdsaSha256),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("kms sign with %s: %w", s.Key, err)
+	}
+	return out.Signature, nil
+}
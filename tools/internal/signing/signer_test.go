@@ -0,0 +1,58 @@
+package signing
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeKMS signs digests with an in-memory P-256 key like an ECC_NIST_P256 KMS key
+type fakeKMS struct {
+	kmsiface.KMSAPI
+	key            *ecdsa.PrivateKey
+	publicKeyCalls int
+}
+
+func (f *fakeKMS) SignWithContext(_ aws.Context, input *kms.SignInput, _ ...request.Option) (*kms.SignOutput, error) {
+	if aws.StringValue(input.MessageType) != kms.MessageTypeDigest || aws.StringValue(input.SigningAlgorithm) != kms.SigningAlgorithmSpecEcdsaSha256 {
+		return nil, assert.AnError
+	}
+	signature, err := ecdsa.SignASN1(rand.Reader, f.key, input.Message)
+	return &kms.SignOutput{Signature: signature, KeyId: input.KeyId}, err
+}
+
+func (f *fakeKMS) GetPublicKeyWithContext(_ aws.Context, _ *kms.GetPublicKeyInput, _ ...request.Option) (*kms.GetPublicKeyOutput, error) {
+	f.publicKeyCalls++
+	der, err := x509.MarshalPKIXPublicKey(&f.key.PublicKey)
+	return &kms.GetPublicKeyOutput{PublicKey: der}, err
+}
+
+func TestKMSSignAndVerify(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	api := &fakeKMS{key: key}
+	ctx := context.Background()
+
+	signer := KMSSigner{API: api, Key: "alias/auditledger-manifest"}
+	signature, err := signer.Sign(ctx, []byte("payload"))
+	require.NoError(t, err)
+
+	verifier := &KMSVerifier{API: api, Key: "alias/auditledger-manifest"}
+	assert.NoError(t, verifier.Verify(ctx, []byte("payload"), signature))
+	assert.ErrorIs(t, verifier.Verify(ctx, []byte("forged"), signature), ErrBadSignature)
+	assert.Equal(t, 1, api.publicKeyCalls, "The public key is fetched once")
+
+	offline := ECDSAVerifier{Key: &key.PublicKey}
+	assert.NoError(t, offline.Verify(ctx, []byte("payload"), signature), "KMS signatures verify with the exported public key")
+	assert.Equal(t, AlgorithmECDSASHA256, offline.Algorithm())
+}
@@ -0,0 +1,153 @@
+package signing
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/kms"
+	"github.com/aws/aws-sdk-go/service/kms/kmsiface"
+)
+
+// ErrBadSignature is returned when a signature does not match the payload and key
+var ErrBadSignature = errors.New("signature does not match the payload")
+
+// Verifier checks signatures made by one key. Verification only needs the public key,
+// so auditors can check signatures without access to the signer
+type Verifier interface {
+	KeyID() string
+	Algorithm() string
+	Verify(ctx context.Context, payload, signature []byte) error
+}
+
+// Ed25519Verifier checks signatures made by Ed25519Signer
+type Ed25519Verifier struct {
+	Key ed25519.PublicKey
+}
+
+// KeyID implements Verifier
+func (v Ed25519Verifier) KeyID() string {
+	return Fingerprint(v.Key)
+}
+
+// Algorithm implements Verifier
+func (v Ed25519Verifier) Algorithm() string {
+	return AlgorithmEd25519
+}
+
+// Verify implements Verifier
+func (v Ed25519Verifier) Verify(_ context.Context, payload, signature []byte) error {
+	if !ed25519.Verify(v.Key, payload, signature) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// ECDSAVerifier checks signatures made by KMSSigner with an exported P-256 public key
+type ECDSAVerifier struct {
+	Key *ecdsa.PublicKey
+}
+
+// KeyID implements Verifier; KMS signatures name the KMS key instead, so this is informational
+func (v ECDSAVerifier) KeyID() string {
+	der, err := x509.MarshalPKIXPublicKey(v.Key)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(der)
+	return "SHA256:" + hex.EncodeToString(sum[:])
+}
+
+// Algorithm implements Verifier
+func (v ECDSAVerifier) Algorithm() string {
+	return AlgorithmECDSASHA256
+}
+
+// Verify implements Verifier
+func (v ECDSAVerifier) Verify(_ context.Context, payload, signature []byte) error {
+	digest := sha256.Sum256(payload)
+	if !ecdsa.VerifyASN1(v.Key, digest[:], signature) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// KMSVerifier fetches the public key of an AWS KMS signing key once and verifies locally,
+// so checking a chain needs kms:GetPublicKey but not kms:Verify
+type KMSVerifier struct {
+	API kmsiface.KMSAPI
+	Key string // key ID, ARN or alias ARN
+
+	once     sync.Once
+	verifier ECDSAVerifier
+	err      error
+}
+
+// KeyID implements Verifier
+func (v *KMSVerifier) KeyID() string {
+	return v.Key
+}
+
+// Algorithm implements Verifier
+func (v *KMSVerifier) Algorithm() string {
+	return AlgorithmECDSASHA256
+}
+
+// Verify implements Verifier
+func (v *KMSVerifier) Verify(ctx context.Context, payload, signature []byte) error {
+	v.once.Do(func() {
+		out, err := v.API.GetPublicKeyWithContext(ctx, &kms.GetPublicKeyInput{KeyId: aws.String(v.Key)})
+		if err != nil {
+			v.err = fmt.Errorf("kms get public key %s: %w", v.Key, err)
+			return
+		}
+		key, err := x509.ParsePKIXPublicKey(out.PublicKey)
+		if err != nil {
+			v.err = fmt.Errorf("kms public key %s: %w", v.Key, err)
+			return
+		}
+		public, ok := key.(*ecdsa.PublicKey)
+		if !ok || public.Curve != elliptic.P256() {
+			v.err = fmt.Errorf("kms key %s is not an ECC_NIST_P256 key", v.Key)
+			return
+		}
+		v.verifier = ECDSAVerifier{Key: public}
+	})
+	if v.err != nil {
+		return v.err
+	}
+	return v.verifier.Verify(ctx, payload, signature)
+}
+
+// LoadVerifier reads an Ed25519 or ECDSA P-256 public key from a PKIX PEM file, e.g. the
+// output of 'auditledger-evidence keygen' or 'aws kms get-public-key'
+func LoadVerifier(path string) (Verifier, error) {
+	block, err := readPEM(path, "PUBLIC KEY")
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	switch public := key.(type) {
+	case ed25519.PublicKey:
+		return Ed25519Verifier{Key: public}, nil
+	case *ecdsa.PublicKey:
+		if public.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("%s: only P-256 ECDSA keys are supported", path)
+		}
+		return ECDSAVerifier{Key: public}, nil
+	default:
+		return nil, fmt.Errorf("%s: not an Ed25519 or ECDSA P-256 public key", path)
+	}
+}
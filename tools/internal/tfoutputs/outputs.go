@@ -0,0 +1,118 @@
+// Package tfoutputs reads module outputs from `terraform output -json` or a Terraform state file
+package tfoutputs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Outputs maps output names to their JSON values
+type Outputs map[string]json.RawMessage
+
+type outputValue struct {
+	Value json.RawMessage `json:"value"`
+}
+
+type stateFile struct {
+	Version *int                   `json:"version"`
+	Outputs map[string]outputValue `json:"outputs"`
+}
+
+// Load reads outputs from a file path ("-" for stdin)
+func Load(path string) (Outputs, error) {
+	var reader io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse accepts either a Terraform state file (version 4) or the output of `terraform output -json`
+func Parse(data []byte) (Outputs, error) {
+	var state stateFile
+	if err := json.Unmarshal(data, &state); err == nil && state.Version != nil {
+		return fromValues(state.Outputs), nil
+	}
+
+	var values map[string]outputValue
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("not a Terraform state file or `terraform output -json` document: %w", err)
+	}
+	return fromValues(values), nil
+}
+
+func fromValues(values map[string]outputValue) Outputs {
+	outputs := Outputs{}
+	for name, output := range values {
+		outputs[name] = output.Value
+	}
+	return outputs
+}
+
+// Nested returns the outputs of a module that the root configuration exposes as a
+// single object output, e.g. `output "audit_storage" { value = module.audit_storage }`
+func (o Outputs) Nested(key string) (Outputs, error) {
+	raw, ok := o[key]
+	if !ok {
+		return nil, fmt.Errorf("output %q not found", key)
+	}
+
+	var nested map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &nested); err != nil || nested == nil {
+		return nil, fmt.Errorf("output %q is not an object of module outputs", key)
+	}
+	return Outputs(nested), nil
+}
+
+// Path follows Nested through a dotted key, e.g. audit_storage.aws; an empty key returns o
+func (o Outputs) Path(key string) (Outputs, error) {
+	if key == "" {
+		return o, nil
+	}
+
+	outputs := o
+	for _, name := range strings.Split(key, ".") {
+		var err error
+		if outputs, err = outputs.Nested(name); err != nil {
+			return nil, err
+		}
+	}
+	return outputs, nil
+}
+
+// Has reports whether an output is present and not null
+func (o Outputs) Has(name string) bool {
+	raw, ok := o[name]
+	return ok && string(raw) != "null"
+}
+
+// String returns a string output, or "" if it is missing or null
+func (o Outputs) String(name string) string {
+	var value string
+	_ = o.Decode(name, &value)
+	return value
+}
+
+// Decode unmarshals an output into v; missing or null outputs leave v unchanged
+func (o Outputs) Decode(name string, v interface{}) error {
+	if !o.Has(name) {
+		return nil
+	}
+	if err := json.Unmarshal(o[name], v); err != nil {
+		return fmt.Errorf("output %q: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package tfoutputs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseOutputJSON(t *testing.T) {
+	outputs, err := Parse([]byte(`{
+		"bucket_id": {"sensitive": false, "type": "string", "value": "audit-logs"},
+		"kms_key_id": {"sensitive": false, "type": "string", "value": null}
+	}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, "audit-logs", outputs.String("bucket_id"))
+	assert.False(t, outputs.Has("kms_key_id"), "null outputs are treated as missing")
+	assert.Equal(t, "", outputs.String("missing"))
+}
+
+func TestParseStateFile(t *testing.T) {
+	outputs, err := Parse([]byte(`{
+		"version": 4,
+		"terraform_version": "1.5.0",
+		"outputs": {
+			"object_lock_configuration": {"value": {"mode": "COMPLIANCE", "retention_days": 2555}, "type": ["object", {}]}
+		},
+		"resources": []
+	}`))
+	require.NoError(t, err)
+
+	var lock struct {
+		Mode          string `json:"mode"`
+		RetentionDays int    `json:"retention_days"`
+	}
+	require.NoError(t, outputs.Decode("object_lock_configuration", &lock))
+	assert.Equal(t, "COMPLIANCE", lock.Mode)
+	assert.Equal(t, 2555, lock.RetentionDays)
+}
+
+func TestNested(t *testing.T) {
+	outputs, err := Parse([]byte(`{
+		"aws": {"value": {"bucket_id": "audit-logs"}},
+		"cloud": {"value": "aws"}
+	}`))
+	require.NoError(t, err)
+
+	nested, err := outputs.Nested("aws")
+	require.NoError(t, err)
+	assert.Equal(t, "audit-logs", nested.String("bucket_id"))
+
+	_, err = outputs.Nested("cloud")
+	assert.Error(t, err, "A string output cannot hold module outputs")
+
+	_, err = outputs.Nested("missing")
+	assert.Error(t, err)
+}
+
+func TestParseInvalid(t *testing.T) {
+	_, err := Parse([]byte(`["not", "outputs"]`))
+	assert.Error(t, err)
+}
+
+func TestPath(t *testing.T) {
+	outputs, err := Parse([]byte(`{"audit_storage": {"value": {"azure": {"container_name": "audit-logs"}}}}`))
+	require.NoError(t, err)
+
+	nested, err := outputs.Path("audit_storage.azure")
+	require.NoError(t, err)
+	assert.Equal(t, "audit-logs", nested.String("container_name"))
+
+	same, err := outputs.Path("")
+	require.NoError(t, err)
+	assert.True(t, same.Has("audit_storage"))
+
+	_, err = outputs.Path("audit_storage.aws")
+	assert.EqualError(t, err, `output "aws" not found`)
+}
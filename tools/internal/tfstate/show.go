@@ -0,0 +1,159 @@
+// Package tfstate reads the resources of a `terraform show -json` document (state or plan)
+package tfstate
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Resource is a managed or data resource with its attribute values
+type Resource struct {
+	Address       string                 `json:"address"`
+	Mode          string                 `json:"mode"`
+	Type          string                 `json:"type"`
+	Name          string                 `json:"name"`
+	Index         interface{}            `json:"index,omitempty"`
+	Values        map[string]interface{} `json:"values"`
+	Sensitive     interface{}            `json:"sensitive_values,omitempty"`
+	ModuleAddress string                 `json:"-"`
+}
+
+type module struct {
+	Address      string     `json:"address"`
+	Resources    []Resource `json:"resources"`
+	ChildModules []module   `json:"child_modules"`
+}
+
+type values struct {
+	RootModule module `json:"root_module"`
+}
+
+// Show is a parsed `terraform show -json` document
+type Show struct {
+	FormatVersion    string  `json:"format_version"`
+	TerraformVersion string  `json:"terraform_version"`
+	Values           *values `json:"values"`
+	PlannedValues    *values `json:"planned_values"`
+}
+
+// Load reads a document from a file path ("-" for stdin)
+func Load(path string) (*Show, []byte, error) {
+	var reader io.Reader = os.Stdin
+	if path != "-" {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, nil, err
+		}
+		defer file.Close()
+		reader = file
+	}
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	show, err := Parse(data)
+	return show, data, err
+}
+
+// Parse decodes `terraform show -json` output for a state or a saved plan
+func Parse(data []byte) (*Show, error) {
+	var show Show
+	if err := json.Unmarshal(data, &show); err != nil {
+		return nil, fmt.Errorf("not a `terraform show -json` document: %w", err)
+	}
+	if show.FormatVersion == "" || (show.Values == nil && show.PlannedValues == nil) {
+		return nil, fmt.Errorf("not a `terraform show -json` document: no values or planned_values (raw terraform.tfstate files are not supported)")
+	}
+	return &show, nil
+}
+
+// Resources returns every managed resource in the state (or the planned values of a plan), sorted by address
+func (s *Show) Resources() []Resource {
+	root := s.Values
+	if root == nil {
+		root = s.PlannedValues
+	}
+
+	var resources []Resource
+	var walk func(m module)
+	walk = func(m module) {
+		for _, resource := range m.Resources {
+			if resource.Mode != "managed" {
+				continue
+			}
+			resource.ModuleAddress = m.Address
+			resources = append(resources, resource)
+		}
+		for _, child := range m.ChildModules {
+			walk(child)
+		}
+	}
+	walk(root.RootModule)
+
+	sort.Slice(resources, func(i, j int) bool { return resources[i].Address < resources[j].Address })
+	return resources
+}
+
+// ByModule groups the managed resources by the address of their module ("" for the root module)
+func (s *Show) ByModule() map[string][]Resource {
+	byModule := map[string][]Resource{}
+	for _, resource := range s.Resources() {
+		byModule[resource.ModuleAddress] = append(byModule[resource.ModuleAddress], resource)
+	}
+	return byModule
+}
+
+// Find returns the resources of a type and name (any count/for_each index); an empty name
+// matches every resource of the type
+func Find(resources []Resource, resourceType, name string) []Resource {
+	var found []Resource
+	for _, resource := range resources {
+		if resource.Type == resourceType && (name == "" || resource.Name == name) {
+			found = append(found, resource)
+		}
+	}
+	return found
+}
+
+// LocalAddress is the address relative to the resource's module, e.g. aws_s3_bucket.audit_logs
+func (r Resource) LocalAddress() string {
+	return strings.TrimPrefix(strings.TrimPrefix(r.Address, r.ModuleAddress), ".")
+}
+
+// String returns a string attribute, or "" if it is missing or not a string
+func (r Resource) String(name string) string {
+	value, _ := r.Values[name].(string)
+	return value
+}
+
+// Lookup walks nested values; blocks are lists of objects, so a path alternates names and indexes
+func Lookup(value interface{}, path ...interface{}) interface{} {
+	for _, step := range path {
+		switch key := step.(type) {
+		case string:
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return nil
+			}
+			value = object[key]
+		case int:
+			list, ok := value.([]interface{})
+			if !ok || key >= len(list) {
+				return nil
+			}
+			value = list[key]
+		}
+	}
+	return value
+}
+
+// Number converts a JSON number to an int; unknown and null values are not numbers
+func Number(value interface{}) (int, bool) {
+	f, ok := value.(float64)
+	return int(f), ok
+}
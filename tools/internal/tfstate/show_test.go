@@ -0,0 +1,68 @@
+package tfstate
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourcesWalksChildModules(t *testing.T) {
+	show, err := Parse([]byte(`{
+		"format_version": "1.0",
+		"terraform_version": "1.5.7",
+		"values": {"root_module": {
+			"resources": [{"address": "aws_kms_key.audit", "mode": "managed", "type": "aws_kms_key", "name": "audit", "values": {}}],
+			"child_modules": [{
+				"address": "module.audit_storage",
+				"resources": [
+					{"address": "module.audit_storage.aws_s3_bucket.audit_logs", "mode": "managed", "type": "aws_s3_bucket", "name": "audit_logs", "values": {"bucket": "audit-logs"}},
+					{"address": "module.audit_storage.data.aws_caller_identity.current", "mode": "data", "type": "aws_caller_identity", "name": "current", "values": {}}
+				]
+			}]
+		}}
+	}`))
+	require.NoError(t, err)
+
+	resources := show.Resources()
+	require.Len(t, resources, 2, "data sources are skipped")
+
+	bucket := resources[1]
+	assert.Equal(t, "module.audit_storage", bucket.ModuleAddress)
+	assert.Equal(t, "aws_s3_bucket.audit_logs", bucket.LocalAddress())
+	assert.Equal(t, "audit-logs", bucket.String("bucket"))
+	assert.Equal(t, "aws_kms_key.audit", resources[0].LocalAddress())
+
+	byModule := show.ByModule()
+	assert.Len(t, byModule[""], 1)
+	assert.Equal(t, []Resource{bucket}, Find(byModule["module.audit_storage"], "aws_s3_bucket", "audit_logs"))
+	assert.Len(t, Find(resources, "aws_s3_bucket", ""), 1, "an empty name matches any resource of the type")
+	assert.Empty(t, Find(resources, "aws_s3_bucket", "other"))
+}
+
+func TestLookup(t *testing.T) {
+	values := map[string]interface{}{
+		"rule": []interface{}{map[string]interface{}{"default_retention": []interface{}{map[string]interface{}{"days": float64(365), "mode": "COMPLIANCE"}}}},
+	}
+
+	days, ok := Number(Lookup(values, "rule", 0, "default_retention", 0, "days"))
+	assert.True(t, ok)
+	assert.Equal(t, 365, days)
+	assert.Equal(t, "COMPLIANCE", Lookup(values, "rule", 0, "default_retention", 0, "mode"))
+	assert.Nil(t, Lookup(values, "rule", 1, "default_retention"), "indexes past the end are nil")
+	assert.Nil(t, Lookup(values, "rule", "default_retention"), "names do not index lists")
+
+	_, ok = Number(Lookup(values, "rule", 0, "default_retention", 0, "years"))
+	assert.False(t, ok, "null is not a number")
+}
+
+func TestParsePlan(t *testing.T) {
+	show, err := Parse([]byte(`{"format_version": "1.2", "planned_values": {"root_module": {"resources": [{"address": "aws_s3_bucket.a", "mode": "managed", "type": "aws_s3_bucket", "name": "a"}]}}}`))
+	require.NoError(t, err)
+	assert.Len(t, show.Resources(), 1)
+}
+
+func TestParseRejectsRawState(t *testing.T) {
+	_, err := Parse([]byte(`{"version": 4, "terraform_version": "1.5.7", "resources": []}`))
+	assert.ErrorContains(t, err, "terraform.tfstate")
+}
@@ -0,0 +1,426 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/arm"
+	"github.com/auditledger/auditledger-terraform/tools/internal/report"
+	"github.com/auditledger/auditledger-terraform/tools/internal/tfoutputs"
+)
+
+// API versions of the Resource Manager endpoints the Azure verifier reads
+const (
+	storageAPIVersion            = "2023-01-01"
+	threatProtectionAPIVersion   = "2019-01-01"
+	diagnosticSettingsAPIVersion = "2021-05-01-preview"
+)
+
+// Diagnostic settings created by modules/auditledger-azure-blob
+const (
+	AccountDiagnosticSettingName = "auditledger-diagnostics"
+	BlobDiagnosticSettingName    = "auditledger-blob-diagnostics"
+)
+
+// BlobLogCategories are the blob service log categories the module enables
+var BlobLogCategories = []string{"StorageRead", "StorageWrite", "StorageDelete"}
+
+// AzureExpectations is what the auditledger-azure-blob module declared for a storage account
+type AzureExpectations struct {
+	StorageAccountID string
+	ContainerName    string
+
+	HTTPSOnly            bool
+	MinTLSVersion        string
+	SharedKeyEnabled     bool
+	NetworkDefaultAction string
+	ThreatProtection     bool
+
+	ChangeFeedRetentionDays int
+	SoftDeleteDays          int
+
+	ImmutabilityDays         int
+	ImmutabilityLocked       bool
+	ProtectedAppendWrites    bool
+	VersionLevelImmutability string
+	LegalHoldTags            []string
+
+	RequireDiagnostics          bool
+	LogAnalyticsWorkspaceID     string
+	EventHubAuthorizationRuleID string
+	EventHubName                string
+	DiagnosticStorageAccountID  string
+}
+
+// AzureExpectationsFromOutputs reads expectations from the auditledger-azure-blob module outputs.
+// Settings without an output (older module versions) default to the module's hard-coded values
+func AzureExpectationsFromOutputs(outputs tfoutputs.Outputs) (AzureExpectations, error) {
+	expected := AzureExpectations{
+		StorageAccountID: outputs.String("storage_account_id"),
+		ContainerName:    outputs.String("container_name"),
+	}
+
+	security := struct {
+		HTTPSOnly               bool   `json:"https_traffic_only_enabled"`
+		MinTLSVersion           string `json:"min_tls_version"`
+		SharedKeyEnabled        bool   `json:"shared_access_key_enabled"`
+		NetworkDefaultAction    string `json:"network_default_action"`
+		ThreatProtection        bool   `json:"threat_protection_enabled"`
+		ChangeFeedRetentionDays int    `json:"change_feed_retention_days"`
+	}{HTTPSOnly: true, MinTLSVersion: "TLS1_2", NetworkDefaultAction: "Deny", ThreatProtection: true}
+
+	var immutability struct {
+		RetentionDays            int      `json:"retention_days"`
+		SoftDeleteDays           int      `json:"soft_delete_days"`
+		ImmutabilityDays         int      `json:"immutability_period_days"`
+		Locked                   bool     `json:"immutability_policy_locked"`
+		ProtectedAppendWrites    bool     `json:"protected_append_writes_enabled"`
+		VersionLevelImmutability string   `json:"version_level_immutability"`
+		LegalHoldTags            []string `json:"legal_hold_tags"`
+	}
+	var monitoring struct {
+		Enabled                     bool   `json:"diagnostics_enabled"`
+		LogAnalyticsWorkspaceID     string `json:"log_analytics_workspace_id"`
+		EventHubAuthorizationRuleID string `json:"event_hub_authorization_rule_id"`
+		EventHubName                string `json:"event_hub_name"`
+		StorageAccountID            string `json:"storage_account_id"`
+	}
+	var profile struct {
+		RequireAccessLogging bool `json:"require_access_logging"`
+	}
+
+	for name, target := range map[string]interface{}{
+		"security_configuration":     &security,
+		"immutability_configuration": &immutability,
+		"monitoring_configuration":   &monitoring,
+		"compliance_profile":         &profile,
+	} {
+		if err := outputs.Decode(name, target); err != nil {
+			return expected, err
+		}
+	}
+
+	expected.HTTPSOnly = security.HTTPSOnly
+	expected.MinTLSVersion = security.MinTLSVersion
+	expected.SharedKeyEnabled = security.SharedKeyEnabled
+	expected.NetworkDefaultAction = security.NetworkDefaultAction
+	expected.ThreatProtection = security.ThreatProtection
+	expected.ChangeFeedRetentionDays = security.ChangeFeedRetentionDays
+	if expected.ChangeFeedRetentionDays == 0 {
+		expected.ChangeFeedRetentionDays = immutability.RetentionDays
+	}
+
+	expected.SoftDeleteDays = immutability.SoftDeleteDays
+	expected.ImmutabilityDays = immutability.ImmutabilityDays
+	expected.ImmutabilityLocked = immutability.Locked
+	expected.ProtectedAppendWrites = immutability.ProtectedAppendWrites
+	expected.VersionLevelImmutability = immutability.VersionLevelImmutability
+	expected.LegalHoldTags = immutability.LegalHoldTags
+
+	expected.RequireDiagnostics = monitoring.Enabled || profile.RequireAccessLogging
+	expected.LogAnalyticsWorkspaceID = monitoring.LogAnalyticsWorkspaceID
+	expected.EventHubAuthorizationRuleID = monitoring.EventHubAuthorizationRuleID
+	expected.EventHubName = monitoring.EventHubName
+	expected.DiagnosticStorageAccountID = monitoring.StorageAccountID
+
+	if expected.StorageAccountID == "" {
+		return expected, fmt.Errorf("output storage_account_id not found - pass the auditledger-azure-blob module outputs or set -storage-account-id")
+	}
+	if expected.ContainerName == "" {
+		return expected, fmt.Errorf("output container_name not found - pass the auditledger-azure-blob module outputs or set -container")
+	}
+	return expected, nil
+}
+
+// Resource Manager response bodies, limited to the properties the verifier reads
+
+type storageAccountResource struct {
+	Name       string `json:"name"`
+	Properties struct {
+		SupportsHTTPSTrafficOnly bool   `json:"supportsHttpsTrafficOnly"`
+		MinimumTLSVersion        string `json:"minimumTlsVersion"`
+		AllowSharedKeyAccess     *bool  `json:"allowSharedKeyAccess"`
+		NetworkACLs              struct {
+			DefaultAction string `json:"defaultAction"`
+		} `json:"networkAcls"`
+		ImmutableStorageWithVersioning struct {
+			Enabled bool `json:"enabled"`
+		} `json:"immutableStorageWithVersioning"`
+	} `json:"properties"`
+}
+
+type retentionPolicy struct {
+	Enabled bool `json:"enabled"`
+	Days    int  `json:"days"`
+}
+
+type blobServiceResource struct {
+	Properties struct {
+		IsVersioningEnabled bool `json:"isVersioningEnabled"`
+		ChangeFeed          struct {
+			Enabled         bool `json:"enabled"`
+			RetentionInDays int  `json:"retentionInDays"`
+		} `json:"changeFeed"`
+		DeleteRetentionPolicy          retentionPolicy `json:"deleteRetentionPolicy"`
+		ContainerDeleteRetentionPolicy retentionPolicy `json:"containerDeleteRetentionPolicy"`
+	} `json:"properties"`
+}
+
+type containerResource struct {
+	Properties struct {
+		HasImmutabilityPolicy bool `json:"hasImmutabilityPolicy"`
+		LegalHold             struct {
+			Tags []struct {
+				Tag string `json:"tag"`
+			} `json:"tags"`
+		} `json:"legalHold"`
+		ImmutableStorageWithVersioning struct {
+			Enabled bool `json:"enabled"`
+		} `json:"immutableStorageWithVersioning"`
+	} `json:"properties"`
+}
+
+type immutabilityPolicyResource struct {
+	Properties struct {
+		PeriodDays            int    `json:"immutabilityPeriodSinceCreationInDays"`
+		State                 string `json:"state"`
+		AllowProtectedAppends bool   `json:"allowProtectedAppendWrites"`
+	} `json:"properties"`
+}
+
+type threatProtectionResource struct {
+	Properties struct {
+		IsEnabled bool `json:"isEnabled"`
+	} `json:"properties"`
+}
+
+type diagnosticSetting struct {
+	Name       string `json:"name"`
+	Properties struct {
+		WorkspaceID                 string `json:"workspaceId"`
+		EventHubAuthorizationRuleID string `json:"eventHubAuthorizationRuleId"`
+		EventHubName                string `json:"eventHubName"`
+		StorageAccountID            string `json:"storageAccountId"`
+		Logs                        []struct {
+			Category string `json:"category"`
+			Enabled  bool   `json:"enabled"`
+		} `json:"logs"`
+		Metrics []struct {
+			Category string `json:"category"`
+			Enabled  bool   `json:"enabled"`
+		} `json:"metrics"`
+	} `json:"properties"`
+}
+
+// AzureVerifier queries a live storage account through Resource Manager
+type AzureVerifier struct {
+	ARM arm.Getter
+}
+
+// Verify checks the storage account and container against the expectations and returns the report
+func (v *AzureVerifier) Verify(ctx context.Context, expected AzureExpectations) *report.Report {
+	accountID := strings.TrimSuffix(expected.StorageAccountID, "/")
+	blobServiceID := accountID + "/blobServices/default"
+	containerID := blobServiceID + "/containers/" + expected.ContainerName
+
+	r := report.New("azure", accountName(accountID)+"/"+expected.ContainerName)
+
+	var account storageAccountResource
+	if err := v.ARM.Get(ctx, accountID, storageAPIVersion, &account); err != nil {
+		r.Failf("storage_account", "reading storage account: %v", err)
+	} else {
+		v.checkAccount(r, account, expected)
+	}
+
+	var blobService blobServiceResource
+	if err := v.ARM.Get(ctx, blobServiceID, storageAPIVersion, &blobService); err != nil {
+		r.Failf("blob_service", "reading blob service properties: %v", err)
+	} else {
+		v.checkBlobService(r, blobService, expected)
+	}
+
+	v.checkContainer(ctx, r, containerID, account, expected)
+	v.checkThreatProtection(ctx, r, accountID, expected)
+	v.checkDiagnostics(ctx, r, "diagnostics.account", accountID, AccountDiagnosticSettingName, expected)
+	v.checkDiagnostics(ctx, r, "diagnostics.blob", blobServiceID, BlobDiagnosticSettingName, expected)
+
+	return r
+}
+
+func (v *AzureVerifier) checkAccount(r *report.Report, account storageAccountResource, expected AzureExpectations) {
+	properties := account.Properties
+
+	r.Compare("account.https_only", strconv.FormatBool(expected.HTTPSOnly), strconv.FormatBool(properties.SupportsHTTPSTrafficOnly))
+	r.Compare("account.min_tls_version", expected.MinTLSVersion, properties.MinimumTLSVersion)
+
+	// Azure omits allowSharedKeyAccess until it is set, and the default is to allow it
+	sharedKey := properties.AllowSharedKeyAccess == nil || *properties.AllowSharedKeyAccess
+	r.Compare("account.shared_key_access", strconv.FormatBool(expected.SharedKeyEnabled), strconv.FormatBool(sharedKey))
+
+	r.Compare("network.default_action", expected.NetworkDefaultAction, properties.NetworkACLs.DefaultAction)
+}
+
+func (v *AzureVerifier) checkBlobService(r *report.Report, service blobServiceResource, expected AzureExpectations) {
+	properties := service.Properties
+
+	r.Compare("blob.versioning", "true", strconv.FormatBool(properties.IsVersioningEnabled))
+
+	if !properties.ChangeFeed.Enabled {
+		r.Failf("blob.change_feed", "change feed is not enabled")
+	} else {
+		r.Compare("blob.change_feed", retentionString(true, expected.ChangeFeedRetentionDays), retentionString(true, properties.ChangeFeed.RetentionInDays))
+	}
+
+	r.Compare("blob.soft_delete",
+		retentionString(true, expected.SoftDeleteDays),
+		retentionString(properties.DeleteRetentionPolicy.Enabled, properties.DeleteRetentionPolicy.Days))
+	r.Compare("blob.container_soft_delete",
+		retentionString(true, expected.SoftDeleteDays),
+		retentionString(properties.ContainerDeleteRetentionPolicy.Enabled, properties.ContainerDeleteRetentionPolicy.Days))
+}
+
+// retentionString renders a retention setting; 0 days means "enabled, unlimited" for the change feed
+func retentionString(enabled bool, days int) string {
+	switch {
+	case !enabled:
+		return "disabled"
+	case days == 0:
+		return "enabled"
+	default:
+		return fmt.Sprintf("%d days", days)
+	}
+}
+
+func (v *AzureVerifier) checkContainer(ctx context.Context, r *report.Report, containerID string, account storageAccountResource, expected AzureExpectations) {
+	var container containerResource
+	if err := v.ARM.Get(ctx, containerID, storageAPIVersion, &container); err != nil {
+		r.Failf("container", "reading container: %v", err)
+		return
+	}
+
+	var policy immutabilityPolicyResource
+	err := v.ARM.Get(ctx, containerID+"/immutabilityPolicies/default", storageAPIVersion, &policy)
+	switch {
+	case arm.IsNotFound(err) || (err == nil && !container.Properties.HasImmutabilityPolicy):
+		r.Failf("immutability_policy", "the container has no time-based retention policy")
+	case err != nil:
+		r.Failf("immutability_policy", "reading immutability policy: %v", err)
+	default:
+		properties := policy.Properties
+		r.Compare("immutability_policy.period_days", strconv.Itoa(expected.ImmutabilityDays), strconv.Itoa(properties.PeriodDays))
+		r.Compare("immutability_policy.locked", strconv.FormatBool(expected.ImmutabilityLocked), strconv.FormatBool(strings.EqualFold(properties.State, "Locked")))
+		r.Compare("immutability_policy.protected_append_writes", strconv.FormatBool(expected.ProtectedAppendWrites), strconv.FormatBool(properties.AllowProtectedAppends))
+	}
+
+	switch expected.VersionLevelImmutability {
+	case "account":
+		r.Compare("immutability.version_level", "true", strconv.FormatBool(account.Properties.ImmutableStorageWithVersioning.Enabled))
+	case "container":
+		r.Compare("immutability.version_level", "true", strconv.FormatBool(container.Properties.ImmutableStorageWithVersioning.Enabled))
+	default:
+		r.Skipf("immutability.version_level", "version-level immutability is not configured (optional)")
+	}
+
+	if len(expected.LegalHoldTags) == 0 {
+		r.Skipf("legal_hold", "no legal hold tags expected")
+		return
+	}
+
+	var actual []string
+	for _, tag := range container.Properties.LegalHold.Tags {
+		actual = append(actual, strings.ToLower(tag.Tag))
+	}
+	var missing []string
+	for _, tag := range expected.LegalHoldTags {
+		if !contains(actual, strings.ToLower(tag)) {
+			missing = append(missing, tag)
+		}
+	}
+	if len(missing) > 0 {
+		r.Add(report.Check{Name: "legal_hold", Status: report.Fail, Message: "legal hold tags are missing: " + strings.Join(missing, ", "), Expected: strings.Join(sortedCopy(expected.LegalHoldTags), ", "), Actual: strings.Join(sortedCopy(actual), ", ")})
+		return
+	}
+	r.Passf("legal_hold", "legal hold tags are set: %s", strings.Join(sortedCopy(expected.LegalHoldTags), ", "))
+}
+
+func (v *AzureVerifier) checkThreatProtection(ctx context.Context, r *report.Report, accountID string, expected AzureExpectations) {
+	if !expected.ThreatProtection {
+		r.Skipf("threat_protection", "threat protection is not enabled by the module (optional)")
+		return
+	}
+
+	var setting threatProtectionResource
+	if err := v.ARM.Get(ctx, accountID+"/providers/Microsoft.Security/advancedThreatProtectionSettings/current", threatProtectionAPIVersion, &setting); err != nil {
+		r.Failf("threat_protection", "reading threat protection: %v", err)
+		return
+	}
+	if !setting.Properties.IsEnabled {
+		r.Failf("threat_protection", "Advanced Threat Protection is not enabled")
+		return
+	}
+	r.Passf("threat_protection", "Advanced Threat Protection is enabled")
+}
+
+func (v *AzureVerifier) checkDiagnostics(ctx context.Context, r *report.Report, name, resourceID, settingName string, expected AzureExpectations) {
+	if !expected.RequireDiagnostics {
+		r.Skipf(name, "no diagnostic sink is configured (optional)")
+		return
+	}
+
+	var settings struct {
+		Value []diagnosticSetting `json:"value"`
+	}
+	if err := v.ARM.Get(ctx, resourceID+"/providers/Microsoft.Insights/diagnosticSettings", diagnosticSettingsAPIVersion, &settings); err != nil {
+		r.Failf(name, "reading diagnostic settings: %v", err)
+		return
+	}
+
+	var setting *diagnosticSetting
+	for i := range settings.Value {
+		if settings.Value[i].Name == settingName {
+			setting = &settings.Value[i]
+		}
+	}
+	if setting == nil {
+		r.Failf(name, "diagnostic setting %s is missing", settingName)
+		return
+	}
+
+	var problems []string
+	for _, sink := range []struct{ name, expected, actual string }{
+		{"Log Analytics workspace", expected.LogAnalyticsWorkspaceID, setting.Properties.WorkspaceID},
+		{"Event Hub authorization rule", expected.EventHubAuthorizationRuleID, setting.Properties.EventHubAuthorizationRuleID},
+		{"Event Hub name", expected.EventHubName, setting.Properties.EventHubName},
+		{"storage account", expected.DiagnosticStorageAccountID, setting.Properties.StorageAccountID},
+	} {
+		// Resource Manager does not preserve the casing of resource IDs
+		if !strings.EqualFold(strings.TrimSuffix(sink.expected, "/"), strings.TrimSuffix(sink.actual, "/")) {
+			problems = append(problems, fmt.Sprintf("%s is %q, expected %q", sink.name, sink.actual, sink.expected))
+		}
+	}
+
+	if settingName == BlobDiagnosticSettingName {
+		enabled := map[string]bool{}
+		for _, log := range setting.Properties.Logs {
+			enabled[log.Category] = log.Enabled
+		}
+		for _, category := range BlobLogCategories {
+			if !enabled[category] {
+				problems = append(problems, category+" logs are not enabled")
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		r.Failf(name, "%s: %s", settingName, strings.Join(problems, "; "))
+		return
+	}
+	r.Passf(name, "%s sends to the expected sinks", settingName)
+}
+
+// accountName is the last segment of a storage account resource ID
+func accountName(id string) string {
+	return id[strings.LastIndex(id, "/")+1:]
+}
@@ -0,0 +1,215 @@
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/arm"
+	"github.com/auditledger/auditledger-terraform/tools/internal/report"
+	"github.com/auditledger/auditledger-terraform/tools/internal/tfoutputs"
+)
+
+const (
+	testAccountID   = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/audit/providers/Microsoft.Storage/storageAccounts/auditlogs"
+	testWorkspaceID = "/subscriptions/00000000-0000-0000-0000-000000000000/resourceGroups/monitoring/providers/Microsoft.OperationalInsights/workspaces/audit"
+)
+
+// armResponses are Resource Manager bodies for an account configured the way
+// modules/auditledger-azure-blob configures it, keyed by resource path
+func armResponses() map[string]map[string]interface{} {
+	blobService := testAccountID + "/blobServices/default"
+	container := blobService + "/containers/audit-logs"
+	diagnostics := func(name string, logs []interface{}) map[string]interface{} {
+		return map[string]interface{}{"value": []interface{}{map[string]interface{}{
+			"name": name,
+			"properties": map[string]interface{}{
+				"workspaceId": testWorkspaceID,
+				"logs":        logs,
+				"metrics":     []interface{}{map[string]interface{}{"category": "Transaction", "enabled": true}},
+			},
+		}}}
+	}
+
+	return map[string]map[string]interface{}{
+		testAccountID: {"name": "auditlogs", "properties": map[string]interface{}{
+			"supportsHttpsTrafficOnly": true,
+			"minimumTlsVersion":        "TLS1_2",
+			"allowSharedKeyAccess":     false,
+			"networkAcls":              map[string]interface{}{"defaultAction": "Deny"},
+		}},
+		blobService: {"properties": map[string]interface{}{
+			"isVersioningEnabled":            true,
+			"changeFeed":                     map[string]interface{}{"enabled": true, "retentionInDays": 2555},
+			"deleteRetentionPolicy":          map[string]interface{}{"enabled": true, "days": 365},
+			"containerDeleteRetentionPolicy": map[string]interface{}{"enabled": true, "days": 365},
+		}},
+		container: {"properties": map[string]interface{}{
+			"hasImmutabilityPolicy": true,
+			"legalHold":             map[string]interface{}{"tags": []interface{}{map[string]interface{}{"tag": "litigation2026"}}},
+		}},
+		container + "/immutabilityPolicies/default": {"properties": map[string]interface{}{
+			"immutabilityPeriodSinceCreationInDays": 2555,
+			"state":                                 "Locked",
+			"allowProtectedAppendWrites":            true,
+		}},
+		testAccountID + "/providers/Microsoft.Security/advancedThreatProtectionSettings/current": {
+			"properties": map[string]interface{}{"isEnabled": true},
+		},
+		testAccountID + "/providers/Microsoft.Insights/diagnosticSettings": diagnostics(AccountDiagnosticSettingName, nil),
+		blobService + "/providers/Microsoft.Insights/diagnosticSettings": diagnostics(BlobDiagnosticSettingName, []interface{}{
+			map[string]interface{}{"category": "StorageRead", "enabled": true},
+			map[string]interface{}{"category": "StorageWrite", "enabled": true},
+			map[string]interface{}{"category": "StorageDelete", "enabled": true},
+		}),
+	}
+}
+
+// fakeARM serves the responses over HTTP so the real arm.Client is exercised
+func fakeARM(t *testing.T, responses map[string]map[string]interface{}) *arm.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, ok := responses[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(body)
+	}))
+	t.Cleanup(server.Close)
+
+	return &arm.Client{Endpoint: server.URL, Token: arm.StaticToken("test")}
+}
+
+func azureExpectations() AzureExpectations {
+	return AzureExpectations{
+		StorageAccountID:        testAccountID,
+		ContainerName:           "audit-logs",
+		HTTPSOnly:               true,
+		MinTLSVersion:           "TLS1_2",
+		NetworkDefaultAction:    "Deny",
+		ThreatProtection:        true,
+		ChangeFeedRetentionDays: 2555,
+		SoftDeleteDays:          365,
+		ImmutabilityDays:        2555,
+		ImmutabilityLocked:      true,
+		ProtectedAppendWrites:   true,
+		LegalHoldTags:           []string{"litigation2026"},
+		RequireDiagnostics:      true,
+		LogAnalyticsWorkspaceID: testWorkspaceID,
+	}
+}
+
+func TestAzureVerifierPassesModuleConfiguration(t *testing.T) {
+	verifier := &AzureVerifier{ARM: fakeARM(t, armResponses())}
+
+	r := verifier.Verify(context.Background(), azureExpectations())
+
+	assert.True(t, r.Passed(), "%+v", r.Checks)
+	assert.Equal(t, "auditlogs/audit-logs", r.Target)
+	assert.Equal(t, report.Pass, statusOf(t, r, "diagnostics.blob"))
+	assert.Equal(t, report.Skip, statusOf(t, r, "immutability.version_level"))
+}
+
+func TestAzureVerifierDetectsDrift(t *testing.T) {
+	blobService := testAccountID + "/blobServices/default"
+	container := blobService + "/containers/audit-logs"
+	properties := func(responses map[string]map[string]interface{}, path string) map[string]interface{} {
+		return responses[path]["properties"].(map[string]interface{})
+	}
+
+	testCases := []struct {
+		name   string
+		mutate func(map[string]map[string]interface{}, *AzureExpectations)
+		check  string
+	}{
+		{"http allowed", func(r map[string]map[string]interface{}, _ *AzureExpectations) {
+			properties(r, testAccountID)["supportsHttpsTrafficOnly"] = false
+		}, "account.https_only"},
+		{"old TLS", func(r map[string]map[string]interface{}, _ *AzureExpectations) {
+			properties(r, testAccountID)["minimumTlsVersion"] = "TLS1_0"
+		}, "account.min_tls_version"},
+		{"shared key unset defaults to enabled", func(r map[string]map[string]interface{}, _ *AzureExpectations) {
+			delete(properties(r, testAccountID), "allowSharedKeyAccess")
+		}, "account.shared_key_access"},
+		{"network open", func(r map[string]map[string]interface{}, _ *AzureExpectations) {
+			properties(r, testAccountID)["networkAcls"] = map[string]interface{}{"defaultAction": "Allow"}
+		}, "network.default_action"},
+		{"versioning off", func(r map[string]map[string]interface{}, _ *AzureExpectations) {
+			properties(r, blobService)["isVersioningEnabled"] = false
+		}, "blob.versioning"},
+		{"change feed off", func(r map[string]map[string]interface{}, _ *AzureExpectations) {
+			properties(r, blobService)["changeFeed"] = map[string]interface{}{"enabled": false}
+		}, "blob.change_feed"},
+		{"soft delete shortened", func(r map[string]map[string]interface{}, _ *AzureExpectations) {
+			properties(r, blobService)["deleteRetentionPolicy"] = map[string]interface{}{"enabled": true, "days": 7}
+		}, "blob.soft_delete"},
+		{"policy unlocked", func(r map[string]map[string]interface{}, _ *AzureExpectations) {
+			properties(r, container+"/immutabilityPolicies/default")["state"] = "Unlocked"
+		}, "immutability_policy.locked"},
+		{"policy removed", func(r map[string]map[string]interface{}, _ *AzureExpectations) {
+			delete(r, container+"/immutabilityPolicies/default")
+		}, "immutability_policy"},
+		{"legal hold cleared", func(r map[string]map[string]interface{}, _ *AzureExpectations) {
+			properties(r, container)["legalHold"] = map[string]interface{}{"tags": []interface{}{}}
+		}, "legal_hold"},
+		{"threat protection off", func(r map[string]map[string]interface{}, _ *AzureExpectations) {
+			properties(r, testAccountID+"/providers/Microsoft.Security/advancedThreatProtectionSettings/current")["isEnabled"] = false
+		}, "threat_protection"},
+		{"blob diagnostics removed", func(r map[string]map[string]interface{}, _ *AzureExpectations) {
+			r[blobService+"/providers/Microsoft.Insights/diagnosticSettings"] = map[string]interface{}{"value": []interface{}{}}
+		}, "diagnostics.blob"},
+		{"diagnostics sent elsewhere", func(_ map[string]map[string]interface{}, e *AzureExpectations) {
+			e.LogAnalyticsWorkspaceID = testWorkspaceID + "-other"
+		}, "diagnostics.account"},
+		{"container-level WORM missing", func(_ map[string]map[string]interface{}, e *AzureExpectations) {
+			e.VersionLevelImmutability = "container"
+		}, "immutability.version_level"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			responses, expected := armResponses(), azureExpectations()
+			tc.mutate(responses, &expected)
+
+			r := (&AzureVerifier{ARM: fakeARM(t, responses)}).Verify(context.Background(), expected)
+
+			assert.False(t, r.Passed())
+			assert.Equal(t, report.Fail, statusOf(t, r, tc.check))
+		})
+	}
+}
+
+func TestAzureExpectationsFromOutputs(t *testing.T) {
+	outputs, err := tfoutputs.Parse([]byte(`{
+		"storage_account_id": {"value": "` + testAccountID + `"},
+		"container_name": {"value": "audit-logs"},
+		"immutability_configuration": {"value": {"retention_days": 2555, "soft_delete_days": 365, "immutability_period_days": 2555, "immutability_policy_locked": true, "version_level_immutability": "disabled", "legal_hold_tags": []}},
+		"monitoring_configuration": {"value": {"diagnostics_enabled": false, "log_analytics_workspace_id": null}},
+		"compliance_profile": {"value": {"require_access_logging": true}}
+	}`))
+	require.NoError(t, err)
+
+	expected, err := AzureExpectationsFromOutputs(outputs)
+	require.NoError(t, err)
+
+	assert.Equal(t, "audit-logs", expected.ContainerName)
+	assert.Equal(t, 2555, expected.ChangeFeedRetentionDays, "falls back to retention_days without security_configuration")
+	assert.Equal(t, "TLS1_2", expected.MinTLSVersion)
+	assert.Equal(t, "Deny", expected.NetworkDefaultAction)
+	assert.False(t, expected.SharedKeyEnabled)
+	assert.True(t, expected.ImmutabilityLocked)
+	assert.True(t, expected.RequireDiagnostics, "compliance profile requires diagnostics")
+}
+
+func TestAzureExpectationsRequireAccountAndContainer(t *testing.T) {
+	_, err := AzureExpectationsFromOutputs(tfoutputs.Outputs{})
+	assert.ErrorContains(t, err, "storage_account_id")
+
+	_, err = AzureExpectationsFromOutputs(tfoutputs.Outputs{"storage_account_id": []byte(`"` + testAccountID + `"`)})
+	assert.ErrorContains(t, err, "container_name")
+}
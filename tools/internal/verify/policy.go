@@ -0,0 +1,137 @@
+package verify
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+)
+
+// StringList accepts IAM's "one string or a list of strings" JSON fields
+type StringList []string
+
+// UnmarshalJSON implements json.Unmarshaler
+func (s *StringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*s = StringList{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*s = list
+	return nil
+}
+
+// PolicyStatement is the subset of an IAM or bucket policy statement the verifier inspects
+type PolicyStatement struct {
+	Sid       string          `json:"Sid"`
+	Effect    string          `json:"Effect"`
+	Action    StringList      `json:"Action"`
+	NotAction StringList      `json:"NotAction"`
+	Principal json.RawMessage `json:"Principal"`
+	Resource  StringList      `json:"Resource"`
+	Condition json.RawMessage `json:"Condition"`
+}
+
+// PolicyDocument is an IAM or bucket policy
+type PolicyDocument struct {
+	Version   string            `json:"Version"`
+	Statement []PolicyStatement `json:"Statement"`
+}
+
+// ParsePolicy parses a policy document; IAM returns URL-encoded documents, which are decoded first
+func ParsePolicy(document string) (*PolicyDocument, error) {
+	if !strings.HasPrefix(strings.TrimSpace(document), "{") {
+		decoded, err := url.QueryUnescape(document)
+		if err != nil {
+			return nil, fmt.Errorf("decoding policy document: %w", err)
+		}
+		document = decoded
+	}
+
+	var raw struct {
+		Version   string          `json:"Version"`
+		Statement json.RawMessage `json:"Statement"`
+	}
+	if err := json.Unmarshal([]byte(document), &raw); err != nil {
+		return nil, fmt.Errorf("parsing policy document: %w", err)
+	}
+
+	policy := &PolicyDocument{Version: raw.Version}
+	var single PolicyStatement
+	if err := json.Unmarshal(raw.Statement, &policy.Statement); err != nil {
+		if err := json.Unmarshal(raw.Statement, &single); err != nil {
+			return nil, fmt.Errorf("parsing policy statements: %w", err)
+		}
+		policy.Statement = []PolicyStatement{single}
+	}
+	return policy, nil
+}
+
+// StatementBySid returns the statement with the given Sid, or nil
+func (p *PolicyDocument) StatementBySid(sid string) *PolicyStatement {
+	for i := range p.Statement {
+		if p.Statement[i].Sid == sid {
+			return &p.Statement[i]
+		}
+	}
+	return nil
+}
+
+// Grants reports whether the statement's actions cover the given action, honoring wildcards
+func (s *PolicyStatement) Grants(action string) bool {
+	for _, pattern := range s.Action {
+		if ActionMatches(pattern, action) {
+			return true
+		}
+	}
+
+	// NotAction grants everything it does not list
+	if len(s.NotAction) > 0 {
+		for _, pattern := range s.NotAction {
+			if ActionMatches(pattern, action) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// ActionMatches compares an IAM action pattern such as "s3:Delete*" with an action, case-insensitively
+func ActionMatches(pattern, action string) bool {
+	matched, err := path.Match(strings.ToLower(pattern), strings.ToLower(action))
+	return err == nil && matched
+}
+
+// DeleteActions are the S3 actions that remove audit log objects or their versions
+var DeleteActions = []string{"s3:DeleteObject", "s3:DeleteObjectVersion"}
+
+// AllowedDeletes returns the delete actions that Allow statements grant, sorted
+func (p *PolicyDocument) AllowedDeletes() []string {
+	granted := map[string]bool{}
+	for i := range p.Statement {
+		statement := &p.Statement[i]
+		if !strings.EqualFold(statement.Effect, "Allow") {
+			continue
+		}
+		for _, action := range DeleteActions {
+			if statement.Grants(action) {
+				granted[action] = true
+			}
+		}
+	}
+
+	actions := make([]string, 0, len(granted))
+	for action := range granted {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
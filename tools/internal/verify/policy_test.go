@@ -0,0 +1,58 @@
+package verify
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicySingleStatementAndStrings(t *testing.T) {
+	policy, err := ParsePolicy(`{"Version":"2012-10-17","Statement":{"Sid":"Write","Effect":"Allow","Action":"s3:PutObject","Resource":"*"}}`)
+	require.NoError(t, err)
+
+	require.Len(t, policy.Statement, 1)
+	assert.Equal(t, StringList{"s3:PutObject"}, policy.Statement[0].Action)
+	assert.NotNil(t, policy.StatementBySid("Write"))
+	assert.Nil(t, policy.StatementBySid("Missing"))
+}
+
+func TestParsePolicyURLEncoded(t *testing.T) {
+	policy, err := ParsePolicy(url.QueryEscape(`{"Statement":[{"Effect":"Allow","Action":["s3:GetObject"]}]}`))
+	require.NoError(t, err)
+	assert.Len(t, policy.Statement, 1)
+}
+
+func TestAllowedDeletes(t *testing.T) {
+	testCases := []struct {
+		name     string
+		document string
+		expected []string
+	}{
+		{"put only", `{"Statement":[{"Effect":"Allow","Action":"s3:PutObject"}]}`, []string{}},
+		{"wildcard", `{"Statement":[{"Effect":"Allow","Action":"s3:*"}]}`, DeleteActions},
+		{"prefix wildcard", `{"Statement":[{"Effect":"Allow","Action":"S3:Delete*"}]}`, DeleteActions},
+		{"version only", `{"Statement":[{"Effect":"Allow","Action":"s3:DeleteObjectVersion"}]}`, []string{"s3:DeleteObjectVersion"}},
+		{"deny is ignored", `{"Statement":[{"Effect":"Deny","Action":"s3:*"}]}`, []string{}},
+		{"not action", `{"Statement":[{"Effect":"Allow","NotAction":"s3:GetObject"}]}`, DeleteActions},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policy, err := ParsePolicy(tc.document)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, policy.AllowedDeletes())
+		})
+	}
+}
+
+func TestKMSKeyMatches(t *testing.T) {
+	arn := "arn:aws:kms:us-east-1:123456789012:key/1234abcd"
+
+	assert.True(t, KMSKeyMatches(arn, arn))
+	assert.True(t, KMSKeyMatches("1234abcd", arn))
+	assert.True(t, KMSKeyMatches(arn, "1234abcd"))
+	assert.False(t, KMSKeyMatches("5678efgh", arn))
+	assert.False(t, KMSKeyMatches("1234abcd", ""))
+}
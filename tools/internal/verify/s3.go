@@ -0,0 +1,433 @@
+package verify
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/report"
+	"github.com/auditledger/auditledger-terraform/tools/internal/tfoutputs"
+)
+
+// S3Expectations is what the auditledger-s3 module declared for a bucket
+type S3Expectations struct {
+	Bucket               string
+	LockMode             string
+	RetentionDays        int
+	SSEAlgorithm         string
+	KMSKeyID             string
+	RequireLogging       bool
+	LogBucket            string
+	RequireReplication   bool
+	ReplicationBucketARN string
+	IAMPolicyARN         string
+	ManifestEnabled      bool
+}
+
+// S3ExpectationsFromOutputs reads expectations from the auditledger-s3 module outputs
+func S3ExpectationsFromOutputs(outputs tfoutputs.Outputs) (S3Expectations, error) {
+	expected := S3Expectations{
+		Bucket:       outputs.String("bucket_id"),
+		IAMPolicyARN: outputs.String("iam_policy_arn"),
+	}
+
+	var lock struct {
+		Mode          string `json:"mode"`
+		RetentionDays int    `json:"retention_days"`
+	}
+	var encryption struct {
+		SSEAlgorithm string `json:"sse_algorithm"`
+		KMSKeyID     string `json:"kms_key_id"`
+	}
+	var logging struct {
+		Enabled      bool   `json:"enabled"`
+		TargetBucket string `json:"target_bucket"`
+	}
+	var replication struct {
+		Enabled              bool   `json:"enabled"`
+		DestinationBucketARN string `json:"destination_bucket_arn"`
+	}
+	var profile struct {
+		RequireAccessLogging bool `json:"require_access_logging"`
+	}
+	var manifest struct {
+		WriterRoleARNs []string `json:"writer_role_arns"`
+	}
+
+	for name, target := range map[string]interface{}{
+		"object_lock_configuration":    &lock,
+		"encryption_configuration":     &encryption,
+		"access_logging_configuration": &logging,
+		"replication_configuration":    &replication,
+		"compliance_profile":           &profile,
+		"manifest_configuration":       &manifest,
+	} {
+		if err := outputs.Decode(name, target); err != nil {
+			return expected, err
+		}
+	}
+
+	expected.LockMode = lock.Mode
+	expected.RetentionDays = lock.RetentionDays
+	expected.SSEAlgorithm = encryption.SSEAlgorithm
+	expected.KMSKeyID = encryption.KMSKeyID
+	expected.RequireLogging = logging.Enabled || profile.RequireAccessLogging
+	expected.LogBucket = logging.TargetBucket
+	expected.RequireReplication = replication.Enabled
+	expected.ReplicationBucketARN = replication.DestinationBucketARN
+	expected.ManifestEnabled = len(manifest.WriterRoleARNs) > 0
+
+	if expected.Bucket == "" {
+		return expected, fmt.Errorf("output bucket_id not found - pass the auditledger-s3 module outputs or set -bucket")
+	}
+	return expected, nil
+}
+
+// BucketStatement is a bucket policy statement created by aws_s3_bucket_policy.audit_logs in
+// modules/auditledger-s3. Actions lists every action the module grants or denies
+type BucketStatement struct {
+	Sid     string
+	Effect  string
+	Actions []string
+}
+
+// ExpectedBucketStatements are the statements the module always creates
+var ExpectedBucketStatements = []BucketStatement{
+	{"DenyDeleteObject", "Deny", []string{"s3:DeleteObject", "s3:DeleteObjectVersion"}},
+	{"DenyBypassGovernanceRetention", "Deny", []string{"s3:BypassGovernanceRetention"}},
+	{"DenyDisableObjectLock", "Deny", []string{"s3:PutBucketObjectLockConfiguration", "s3:PutObjectLegalHold", "s3:PutObjectRetention"}},
+	{"AllowAuditLedgerWrite", "Allow", []string{"s3:PutObject", "s3:PutObjectLegalHold", "s3:PutObjectRetention"}},
+	{"AllowAuditLedgerRead", "Allow", []string{"s3:GetObject", "s3:GetObjectVersion", "s3:ListBucket", "s3:ListBucketVersions"}},
+	{"DenyUnencryptedObjectUploads", "Deny", []string{"s3:PutObject"}},
+	{"EnforceTLSRequestsOnly", "Deny", []string{"s3:*"}},
+}
+
+// ManifestBucketStatements are added to the bucket policy when manifest_writer_role_arns is set
+var ManifestBucketStatements = []BucketStatement{
+	{"AllowManifestWrite", "Allow", []string{"s3:PutObject", "s3:PutObjectRetention"}},
+	{"AllowManifestRead", "Allow", []string{"s3:GetObject", "s3:GetObjectVersion", "s3:ListBucket", "s3:ListBucketVersions"}},
+	{"DenyManifestWriteByOthers", "Deny", []string{"s3:PutObject"}},
+	{"DenyManifestWriterOutsidePrefix", "Deny", []string{"s3:PutObject"}},
+}
+
+// S3Verifier queries a live bucket through the S3 and IAM APIs
+type S3Verifier struct {
+	S3  s3iface.S3API
+	IAM iamiface.IAMAPI
+}
+
+// Verify checks the bucket against the expectations and returns the report
+func (v *S3Verifier) Verify(ctx context.Context, expected S3Expectations) *report.Report {
+	r := report.New("aws", "s3://"+expected.Bucket)
+	bucket := aws.String(expected.Bucket)
+
+	v.checkObjectLock(ctx, r, bucket, expected)
+	v.checkVersioning(ctx, r, bucket)
+	v.checkPublicAccessBlock(ctx, r, bucket)
+	v.checkEncryption(ctx, r, bucket, expected)
+	v.checkBucketPolicy(ctx, r, bucket, expected)
+	v.checkLogging(ctx, r, bucket, expected)
+	v.checkReplication(ctx, r, bucket, expected)
+	v.checkIAMPolicy(ctx, r, expected)
+
+	return r
+}
+
+func (v *S3Verifier) checkObjectLock(ctx context.Context, r *report.Report, bucket *string, expected S3Expectations) {
+	out, err := v.S3.GetObjectLockConfigurationWithContext(ctx, &s3.GetObjectLockConfigurationInput{Bucket: bucket})
+	if err != nil {
+		r.Failf("object_lock.enabled", "reading Object Lock configuration: %v", err)
+		return
+	}
+
+	config := out.ObjectLockConfiguration
+	if config == nil || aws.StringValue(config.ObjectLockEnabled) != s3.ObjectLockEnabledEnabled {
+		r.Failf("object_lock.enabled", "Object Lock is not enabled on the bucket")
+		return
+	}
+	r.Passf("object_lock.enabled", "Object Lock is enabled")
+
+	if config.Rule == nil || config.Rule.DefaultRetention == nil {
+		r.Failf("object_lock.default_retention", "no default retention rule - objects are only protected if writers set retention")
+		return
+	}
+
+	retention := config.Rule.DefaultRetention
+	days := int(aws.Int64Value(retention.Days))
+	if retention.Years != nil {
+		days = int(aws.Int64Value(retention.Years)) * 365
+	}
+
+	if expected.LockMode != "" {
+		r.Compare("object_lock.mode", expected.LockMode, aws.StringValue(retention.Mode))
+	} else {
+		r.Passf("object_lock.mode", "default retention mode is %s", aws.StringValue(retention.Mode))
+	}
+
+	if expected.RetentionDays > 0 {
+		r.Compare("object_lock.retention_days", strconv.Itoa(expected.RetentionDays), strconv.Itoa(days))
+	} else {
+		r.Passf("object_lock.retention_days", "default retention is %d days", days)
+	}
+}
+
+func (v *S3Verifier) checkVersioning(ctx context.Context, r *report.Report, bucket *string) {
+	out, err := v.S3.GetBucketVersioningWithContext(ctx, &s3.GetBucketVersioningInput{Bucket: bucket})
+	if err != nil {
+		r.Failf("versioning", "reading versioning: %v", err)
+		return
+	}
+	r.Compare("versioning", s3.BucketVersioningStatusEnabled, aws.StringValue(out.Status))
+}
+
+func (v *S3Verifier) checkPublicAccessBlock(ctx context.Context, r *report.Report, bucket *string) {
+	out, err := v.S3.GetPublicAccessBlockWithContext(ctx, &s3.GetPublicAccessBlockInput{Bucket: bucket})
+	if err != nil {
+		r.Failf("public_access_block", "reading public access block: %v", err)
+		return
+	}
+
+	config := out.PublicAccessBlockConfiguration
+	if config == nil {
+		r.Failf("public_access_block", "no public access block configuration")
+		return
+	}
+
+	var disabled []string
+	for name, value := range map[string]*bool{
+		"BlockPublicAcls":       config.BlockPublicAcls,
+		"BlockPublicPolicy":     config.BlockPublicPolicy,
+		"IgnorePublicAcls":      config.IgnorePublicAcls,
+		"RestrictPublicBuckets": config.RestrictPublicBuckets,
+	} {
+		if !aws.BoolValue(value) {
+			disabled = append(disabled, name)
+		}
+	}
+
+	if len(disabled) > 0 {
+		r.Add(report.Check{
+			Name:     "public_access_block",
+			Status:   report.Fail,
+			Message:  "public access is not fully blocked",
+			Expected: "all four settings true",
+			Actual:   "disabled: " + strings.Join(sortedCopy(disabled), ", "),
+		})
+		return
+	}
+	r.Passf("public_access_block", "all public access is blocked")
+}
+
+func (v *S3Verifier) checkEncryption(ctx context.Context, r *report.Report, bucket *string, expected S3Expectations) {
+	out, err := v.S3.GetBucketEncryptionWithContext(ctx, &s3.GetBucketEncryptionInput{Bucket: bucket})
+	if err != nil {
+		r.Failf("encryption.algorithm", "reading default encryption: %v", err)
+		return
+	}
+
+	var rule *s3.ServerSideEncryptionByDefault
+	if config := out.ServerSideEncryptionConfiguration; config != nil && len(config.Rules) > 0 {
+		rule = config.Rules[0].ApplyServerSideEncryptionByDefault
+	}
+	if rule == nil {
+		r.Failf("encryption.algorithm", "no default encryption rule")
+		return
+	}
+
+	algorithm := aws.StringValue(rule.SSEAlgorithm)
+	if expected.SSEAlgorithm != "" {
+		r.Compare("encryption.algorithm", expected.SSEAlgorithm, algorithm)
+	} else {
+		r.Passf("encryption.algorithm", "default encryption is %s", algorithm)
+	}
+
+	if expected.KMSKeyID == "" {
+		r.Skipf("encryption.kms_key", "no customer-managed key expected")
+		return
+	}
+
+	actual := aws.StringValue(rule.KMSMasterKeyID)
+	if KMSKeyMatches(expected.KMSKeyID, actual) {
+		r.Passf("encryption.kms_key", "bucket uses the expected KMS key")
+		return
+	}
+	r.Add(report.Check{Name: "encryption.kms_key", Status: report.Fail, Message: "bucket uses a different KMS key", Expected: expected.KMSKeyID, Actual: actual})
+}
+
+// KMSKeyMatches compares key IDs, aliases and ARNs that may be given in different forms
+func KMSKeyMatches(expected, actual string) bool {
+	if expected == "" || actual == "" {
+		return expected == actual
+	}
+	return expected == actual || strings.HasSuffix(actual, "/"+expected) || strings.HasSuffix(expected, "/"+actual)
+}
+
+func (v *S3Verifier) checkBucketPolicy(ctx context.Context, r *report.Report, bucket *string, expected S3Expectations) {
+	out, err := v.S3.GetBucketPolicyWithContext(ctx, &s3.GetBucketPolicyInput{Bucket: bucket})
+	if err != nil {
+		r.Failf("bucket_policy", "reading bucket policy: %v", err)
+		return
+	}
+
+	policy, err := ParsePolicy(aws.StringValue(out.Policy))
+	if err != nil {
+		r.Failf("bucket_policy", "%v", err)
+		return
+	}
+
+	statements := ExpectedBucketStatements
+	if expected.ManifestEnabled {
+		statements = append(append([]BucketStatement(nil), statements...), ManifestBucketStatements...)
+	}
+
+	known := map[string]bool{}
+	for _, statement := range statements {
+		known[statement.Sid] = true
+		name := "bucket_policy." + statement.Sid
+
+		actual := policy.StatementBySid(statement.Sid)
+		if actual == nil {
+			r.Failf(name, "statement is missing")
+			continue
+		}
+		if !strings.EqualFold(actual.Effect, statement.Effect) {
+			r.Add(report.Check{Name: name, Status: report.Fail, Message: "statement has the wrong effect", Expected: statement.Effect, Actual: actual.Effect})
+			continue
+		}
+
+		var missing []string
+		for _, action := range statement.Actions {
+			if !actual.Grants(action) {
+				missing = append(missing, action)
+			}
+		}
+		if len(missing) > 0 {
+			r.Add(report.Check{Name: name, Status: report.Fail, Message: "statement does not cover every expected action", Expected: strings.Join(statement.Actions, ", "), Actual: strings.Join(actual.Action, ", ")})
+			continue
+		}
+		r.Passf(name, "%s %s", statement.Effect, strings.Join(statement.Actions, ", "))
+	}
+
+	var unexpected []string
+	for _, statement := range policy.Statement {
+		if !known[statement.Sid] {
+			unexpected = append(unexpected, fmt.Sprintf("%s (%s)", statement.Sid, statement.Effect))
+		}
+	}
+	if len(unexpected) > 0 {
+		r.Add(report.Check{Name: "bucket_policy.unexpected_statements", Status: report.Fail, Message: "policy has statements the module does not create", Expected: "none", Actual: strings.Join(unexpected, ", ")})
+	} else {
+		r.Passf("bucket_policy.unexpected_statements", "policy only contains the module's statements")
+	}
+
+	if deletes := policy.AllowedDeletes(); len(deletes) > 0 {
+		r.Failf("bucket_policy.no_delete_grants", "an Allow statement grants %s", strings.Join(deletes, ", "))
+	} else {
+		r.Passf("bucket_policy.no_delete_grants", "no statement allows object deletion")
+	}
+}
+
+func (v *S3Verifier) checkLogging(ctx context.Context, r *report.Report, bucket *string, expected S3Expectations) {
+	out, err := v.S3.GetBucketLoggingWithContext(ctx, &s3.GetBucketLoggingInput{Bucket: bucket})
+	if err != nil {
+		r.Failf("access_logging", "reading access logging: %v", err)
+		return
+	}
+
+	if out.LoggingEnabled == nil {
+		if expected.RequireLogging {
+			r.Failf("access_logging", "access logging is required but not enabled")
+		} else {
+			r.Skipf("access_logging", "access logging is not enabled (optional)")
+		}
+		return
+	}
+
+	target := aws.StringValue(out.LoggingEnabled.TargetBucket)
+	if expected.LogBucket != "" {
+		r.Compare("access_logging", expected.LogBucket, target)
+		return
+	}
+	r.Passf("access_logging", "access logs are delivered to %s", target)
+}
+
+func (v *S3Verifier) checkReplication(ctx context.Context, r *report.Report, bucket *string, expected S3Expectations) {
+	out, err := v.S3.GetBucketReplicationWithContext(ctx, &s3.GetBucketReplicationInput{Bucket: bucket})
+	if err != nil {
+		if isErrorCode(err, "ReplicationConfigurationNotFoundError") {
+			if expected.RequireReplication {
+				r.Failf("replication", "replication is expected but not configured")
+			} else {
+				r.Skipf("replication", "replication is not configured (optional)")
+			}
+			return
+		}
+		r.Failf("replication", "reading replication: %v", err)
+		return
+	}
+
+	var destinations []string
+	for _, rule := range out.ReplicationConfiguration.Rules {
+		if aws.StringValue(rule.Status) == s3.ReplicationRuleStatusEnabled && rule.Destination != nil {
+			destinations = append(destinations, aws.StringValue(rule.Destination.Bucket))
+		}
+	}
+
+	switch {
+	case len(destinations) == 0:
+		r.Failf("replication", "replication is configured but no rule is enabled")
+	case expected.ReplicationBucketARN != "" && !contains(destinations, expected.ReplicationBucketARN):
+		r.Add(report.Check{Name: "replication", Status: report.Fail, Message: "replication targets a different bucket", Expected: expected.ReplicationBucketARN, Actual: strings.Join(destinations, ", ")})
+	default:
+		r.Passf("replication", "replicating to %s", strings.Join(destinations, ", "))
+	}
+}
+
+func (v *S3Verifier) checkIAMPolicy(ctx context.Context, r *report.Report, expected S3Expectations) {
+	if expected.IAMPolicyARN == "" || v.IAM == nil {
+		r.Skipf("iam_policy.no_delete_grants", "no IAM policy ARN given")
+		return
+	}
+
+	policy, err := v.IAM.GetPolicyWithContext(ctx, &iam.GetPolicyInput{PolicyArn: aws.String(expected.IAMPolicyARN)})
+	if err != nil {
+		r.Failf("iam_policy.no_delete_grants", "reading IAM policy: %v", err)
+		return
+	}
+
+	version, err := v.IAM.GetPolicyVersionWithContext(ctx, &iam.GetPolicyVersionInput{
+		PolicyArn: aws.String(expected.IAMPolicyARN),
+		VersionId: policy.Policy.DefaultVersionId,
+	})
+	if err != nil {
+		r.Failf("iam_policy.no_delete_grants", "reading IAM policy version: %v", err)
+		return
+	}
+
+	document, err := ParsePolicy(aws.StringValue(version.PolicyVersion.Document))
+	if err != nil {
+		r.Failf("iam_policy.no_delete_grants", "%v", err)
+		return
+	}
+
+	if deletes := document.AllowedDeletes(); len(deletes) > 0 {
+		r.Failf("iam_policy.no_delete_grants", "writer IAM policy grants %s", strings.Join(deletes, ", "))
+		return
+	}
+	r.Passf("iam_policy.no_delete_grants", "writer IAM policy does not allow object deletion")
+}
+
+func isErrorCode(err error, code string) bool {
+	if aerr, ok := err.(awserr.Error); ok {
+		return aerr.Code() == code
+	}
+	return false
+}
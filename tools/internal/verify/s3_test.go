@@ -0,0 +1,270 @@
+package verify
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/iam"
+	"github.com/aws/aws-sdk-go/service/iam/iamiface"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/auditledger/auditledger-terraform/tools/internal/report"
+	"github.com/auditledger/auditledger-terraform/tools/internal/tfoutputs"
+)
+
+// fakeS3 returns a bucket configured the way modules/auditledger-s3 configures it
+type fakeS3 struct {
+	s3iface.S3API
+	lockMode       string
+	retentionDays  int64
+	versioning     string
+	blockPublic    bool
+	sseAlgorithm   string
+	kmsKeyID       string
+	policy         map[string]interface{}
+	logBucket      string
+	replicationARN string
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{
+		lockMode:      "COMPLIANCE",
+		retentionDays: 2555,
+		versioning:    "Enabled",
+		blockPublic:   true,
+		sseAlgorithm:  "aws:kms",
+		kmsKeyID:      "arn:aws:kms:us-east-1:123456789012:key/1234abcd",
+		policy:        modulePolicy(),
+	}
+}
+
+func modulePolicy(extra ...BucketStatement) map[string]interface{} {
+	statements := []interface{}{}
+	for _, expected := range append(append([]BucketStatement(nil), ExpectedBucketStatements...), extra...) {
+		statements = append(statements, map[string]interface{}{
+			"Sid":       expected.Sid,
+			"Effect":    expected.Effect,
+			"Principal": "*",
+			"Action":    expected.Actions,
+			"Resource":  "arn:aws:s3:::audit-logs/*",
+		})
+	}
+	return map[string]interface{}{"Version": "2012-10-17", "Statement": statements}
+}
+
+func (f *fakeS3) GetObjectLockConfigurationWithContext(aws.Context, *s3.GetObjectLockConfigurationInput, ...request.Option) (*s3.GetObjectLockConfigurationOutput, error) {
+	return &s3.GetObjectLockConfigurationOutput{ObjectLockConfiguration: &s3.ObjectLockConfiguration{
+		ObjectLockEnabled: aws.String("Enabled"),
+		Rule: &s3.ObjectLockRule{DefaultRetention: &s3.DefaultRetention{
+			Mode: aws.String(f.lockMode),
+			Days: aws.Int64(f.retentionDays),
+		}},
+	}}, nil
+}
+
+func (f *fakeS3) GetBucketVersioningWithContext(aws.Context, *s3.GetBucketVersioningInput, ...request.Option) (*s3.GetBucketVersioningOutput, error) {
+	return &s3.GetBucketVersioningOutput{Status: aws.String(f.versioning)}, nil
+}
+
+func (f *fakeS3) GetPublicAccessBlockWithContext(aws.Context, *s3.GetPublicAccessBlockInput, ...request.Option) (*s3.GetPublicAccessBlockOutput, error) {
+	return &s3.GetPublicAccessBlockOutput{PublicAccessBlockConfiguration: &s3.PublicAccessBlockConfiguration{
+		BlockPublicAcls:       aws.Bool(f.blockPublic),
+		BlockPublicPolicy:     aws.Bool(true),
+		IgnorePublicAcls:      aws.Bool(true),
+		RestrictPublicBuckets: aws.Bool(true),
+	}}, nil
+}
+
+func (f *fakeS3) GetBucketEncryptionWithContext(aws.Context, *s3.GetBucketEncryptionInput, ...request.Option) (*s3.GetBucketEncryptionOutput, error) {
+	return &s3.GetBucketEncryptionOutput{ServerSideEncryptionConfiguration: &s3.ServerSideEncryptionConfiguration{
+		Rules: []*s3.ServerSideEncryptionRule{{ApplyServerSideEncryptionByDefault: &s3.ServerSideEncryptionByDefault{
+			SSEAlgorithm:   aws.String(f.sseAlgorithm),
+			KMSMasterKeyID: aws.String(f.kmsKeyID),
+		}}},
+	}}, nil
+}
+
+func (f *fakeS3) GetBucketPolicyWithContext(aws.Context, *s3.GetBucketPolicyInput, ...request.Option) (*s3.GetBucketPolicyOutput, error) {
+	policy, _ := json.Marshal(f.policy)
+	return &s3.GetBucketPolicyOutput{Policy: aws.String(string(policy))}, nil
+}
+
+func (f *fakeS3) GetBucketLoggingWithContext(aws.Context, *s3.GetBucketLoggingInput, ...request.Option) (*s3.GetBucketLoggingOutput, error) {
+	if f.logBucket == "" {
+		return &s3.GetBucketLoggingOutput{}, nil
+	}
+	return &s3.GetBucketLoggingOutput{LoggingEnabled: &s3.LoggingEnabled{TargetBucket: aws.String(f.logBucket)}}, nil
+}
+
+func (f *fakeS3) GetBucketReplicationWithContext(aws.Context, *s3.GetBucketReplicationInput, ...request.Option) (*s3.GetBucketReplicationOutput, error) {
+	if f.replicationARN == "" {
+		return nil, awserr.New("ReplicationConfigurationNotFoundError", "The replication configuration was not found", nil)
+	}
+	return &s3.GetBucketReplicationOutput{ReplicationConfiguration: &s3.ReplicationConfiguration{
+		Rules: []*s3.ReplicationRule{{Status: aws.String("Enabled"), Destination: &s3.Destination{Bucket: aws.String(f.replicationARN)}}},
+	}}, nil
+}
+
+type fakeIAM struct {
+	iamiface.IAMAPI
+	document string
+}
+
+func (f *fakeIAM) GetPolicyWithContext(aws.Context, *iam.GetPolicyInput, ...request.Option) (*iam.GetPolicyOutput, error) {
+	return &iam.GetPolicyOutput{Policy: &iam.Policy{DefaultVersionId: aws.String("v1")}}, nil
+}
+
+func (f *fakeIAM) GetPolicyVersionWithContext(aws.Context, *iam.GetPolicyVersionInput, ...request.Option) (*iam.GetPolicyVersionOutput, error) {
+	return &iam.GetPolicyVersionOutput{PolicyVersion: &iam.PolicyVersion{Document: aws.String(url.QueryEscape(f.document))}}, nil
+}
+
+func expectations() S3Expectations {
+	return S3Expectations{
+		Bucket:        "audit-logs",
+		LockMode:      "COMPLIANCE",
+		RetentionDays: 2555,
+		SSEAlgorithm:  "aws:kms",
+		KMSKeyID:      "1234abcd",
+		IAMPolicyARN:  "arn:aws:iam::123456789012:policy/audit-logs-access",
+	}
+}
+
+func statusOf(t *testing.T, r *report.Report, name string) report.Status {
+	t.Helper()
+	for _, check := range r.Checks {
+		if check.Name == name {
+			return check.Status
+		}
+	}
+	t.Fatalf("check %s not found", name)
+	return ""
+}
+
+const writerPolicy = `{"Version":"2012-10-17","Statement":[{"Effect":"Allow","Action":["s3:PutObject","s3:GetObject"],"Resource":"*"}]}`
+
+func TestS3VerifierPassesModuleConfiguration(t *testing.T) {
+	verifier := &S3Verifier{S3: newFakeS3(), IAM: &fakeIAM{document: writerPolicy}}
+
+	r := verifier.Verify(context.Background(), expectations())
+
+	assert.True(t, r.Passed(), "%+v", r.Checks)
+	assert.Equal(t, report.Skip, statusOf(t, r, "access_logging"))
+	assert.Equal(t, report.Skip, statusOf(t, r, "replication"))
+	assert.Equal(t, report.Pass, statusOf(t, r, "encryption.kms_key"))
+}
+
+func TestS3VerifierDetectsDrift(t *testing.T) {
+	testCases := []struct {
+		name   string
+		mutate func(*fakeS3, *fakeIAM, *S3Expectations)
+		check  string
+	}{
+		{"governance mode", func(f *fakeS3, _ *fakeIAM, _ *S3Expectations) { f.lockMode = "GOVERNANCE" }, "object_lock.mode"},
+		{"shorter retention", func(f *fakeS3, _ *fakeIAM, _ *S3Expectations) { f.retentionDays = 30 }, "object_lock.retention_days"},
+		{"versioning suspended", func(f *fakeS3, _ *fakeIAM, _ *S3Expectations) { f.versioning = "Suspended" }, "versioning"},
+		{"public ACLs allowed", func(f *fakeS3, _ *fakeIAM, _ *S3Expectations) { f.blockPublic = false }, "public_access_block"},
+		{"different key", func(f *fakeS3, _ *fakeIAM, _ *S3Expectations) {
+			f.kmsKeyID = "arn:aws:kms:us-east-1:123456789012:key/other"
+		}, "encryption.kms_key"},
+		{"SSE-S3 instead of KMS", func(f *fakeS3, _ *fakeIAM, _ *S3Expectations) { f.sseAlgorithm = "AES256" }, "encryption.algorithm"},
+		{"logging required", func(_ *fakeS3, _ *fakeIAM, e *S3Expectations) { e.RequireLogging = true }, "access_logging"},
+		{"replication required", func(_ *fakeS3, _ *fakeIAM, e *S3Expectations) { e.RequireReplication = true }, "replication"},
+		{"writer can delete", func(_ *fakeS3, i *fakeIAM, _ *S3Expectations) {
+			i.document = `{"Statement":{"Effect":"Allow","Action":"s3:*","Resource":"*"}}`
+		}, "iam_policy.no_delete_grants"},
+		{"statement removed", func(f *fakeS3, _ *fakeIAM, _ *S3Expectations) {
+			statements := f.policy["Statement"].([]interface{})
+			f.policy["Statement"] = statements[1:]
+		}, "bucket_policy.DenyDeleteObject"},
+		{"extra allow", func(f *fakeS3, _ *fakeIAM, _ *S3Expectations) {
+			f.policy["Statement"] = append(f.policy["Statement"].([]interface{}), map[string]interface{}{
+				"Sid": "Cleanup", "Effect": "Allow", "Principal": "*", "Action": "s3:Delete*", "Resource": "*",
+			})
+		}, "bucket_policy.no_delete_grants"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s3Client, iamClient, expected := newFakeS3(), &fakeIAM{document: writerPolicy}, expectations()
+			tc.mutate(s3Client, iamClient, &expected)
+
+			r := (&S3Verifier{S3: s3Client, IAM: iamClient}).Verify(context.Background(), expected)
+
+			assert.False(t, r.Passed())
+			assert.Equal(t, report.Fail, statusOf(t, r, tc.check))
+		})
+	}
+}
+
+func TestS3VerifierReplicationDestination(t *testing.T) {
+	s3Client := newFakeS3()
+	s3Client.replicationARN = "arn:aws:s3:::audit-logs-dr"
+	expected := expectations()
+	expected.RequireReplication = true
+	expected.ReplicationBucketARN = "arn:aws:s3:::audit-logs-dr"
+
+	r := (&S3Verifier{S3: s3Client, IAM: &fakeIAM{document: writerPolicy}}).Verify(context.Background(), expected)
+
+	assert.Equal(t, report.Pass, statusOf(t, r, "replication"))
+}
+
+func TestS3VerifierManifestStatements(t *testing.T) {
+	s3Client := newFakeS3()
+	s3Client.policy = modulePolicy(ManifestBucketStatements...)
+	expected := expectations()
+	expected.ManifestEnabled = true
+
+	r := (&S3Verifier{S3: s3Client, IAM: &fakeIAM{document: writerPolicy}}).Verify(context.Background(), expected)
+
+	assert.True(t, r.Passed(), "%+v", r.Checks)
+	assert.Equal(t, report.Pass, statusOf(t, r, "bucket_policy.AllowManifestWrite"))
+	assert.Equal(t, report.Pass, statusOf(t, r, "bucket_policy.unexpected_statements"))
+
+	// Manifest statements on a bucket whose module has no manifest writers are not the module's
+	expected.ManifestEnabled = false
+	r = (&S3Verifier{S3: s3Client, IAM: &fakeIAM{document: writerPolicy}}).Verify(context.Background(), expected)
+	assert.Equal(t, report.Fail, statusOf(t, r, "bucket_policy.unexpected_statements"))
+
+	// Manifests enabled but the statements missing from the live policy
+	expected.ManifestEnabled = true
+	s3Client.policy = modulePolicy()
+	r = (&S3Verifier{S3: s3Client, IAM: &fakeIAM{document: writerPolicy}}).Verify(context.Background(), expected)
+	assert.Equal(t, report.Fail, statusOf(t, r, "bucket_policy.DenyManifestWriterOutsidePrefix"))
+}
+
+func TestS3ExpectationsFromOutputs(t *testing.T) {
+	outputs, err := tfoutputs.Parse([]byte(`{
+		"bucket_id": {"value": "audit-logs"},
+		"iam_policy_arn": {"value": "arn:aws:iam::123456789012:policy/audit-logs-access"},
+		"object_lock_configuration": {"value": {"enabled": true, "mode": "GOVERNANCE", "retention_days": 365}},
+		"encryption_configuration": {"value": {"sse_algorithm": "aws:kms", "kms_key_id": "1234abcd"}},
+		"access_logging_configuration": {"value": {"enabled": false, "target_bucket": null}},
+		"compliance_profile": {"value": {"require_access_logging": true}},
+		"manifest_configuration": {"value": {"prefix": "_manifests/", "writer_role_arns": ["arn:aws:iam::123456789012:role/manifest"]}}
+	}`))
+	require.NoError(t, err)
+
+	expected, err := S3ExpectationsFromOutputs(outputs)
+	require.NoError(t, err)
+
+	assert.Equal(t, "audit-logs", expected.Bucket)
+	assert.Equal(t, "GOVERNANCE", expected.LockMode)
+	assert.Equal(t, 365, expected.RetentionDays)
+	assert.Equal(t, "1234abcd", expected.KMSKeyID)
+	assert.True(t, expected.RequireLogging, "compliance profile requires access logging")
+	assert.False(t, expected.RequireReplication)
+	assert.True(t, expected.ManifestEnabled)
+}
+
+func TestS3ExpectationsRequireBucket(t *testing.T) {
+	_, err := S3ExpectationsFromOutputs(tfoutputs.Outputs{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,19 @@
+// Package verify compares deployed AuditLedger storage against the configuration its module declared
+package verify
+
+import "sort"
+
+func sortedCopy(values []string) []string {
+	sorted := append([]string(nil), values...)
+	sort.Strings(sorted)
+	return sorted
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}